@@ -0,0 +1,198 @@
+package v2
+
+// login godoc
+// @Summary Login
+// @Description Authenticate with email & password. When 2FA is enabled data.mfa_required is true and data.mfa_token must be sent to /v2/auth/mfa/verify.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.AuthRequest true "Login Request"
+// @Success 200 {object} models.Envelope{data=models.LoginData}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Failure 403 {object} models.Envelope "Email not verified"
+// @Router /v2/auth/login [post]
+func login() {}
+
+// logout godoc
+// @Summary Logout
+// @Description Invalidate the current JWT
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/auth/logout [post]
+func logout() {}
+
+// register godoc
+// @Summary Register
+// @Description Create an account and send a verification email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.AuthRequest true "Register Request"
+// @Success 201 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "Email already registered"
+// @Router /v2/auth/register [post]
+func register() {}
+
+// requestVerification godoc
+// @Summary Resend verification email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailRequest true "Email Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Router /v2/auth/verify/request [post]
+func requestVerification() {}
+
+// verifyEmail godoc
+// @Summary Verify email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.TokenRequest true "Token Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope "Token invalid or expired"
+// @Router /v2/auth/verify [post]
+func verifyEmail() {}
+
+// forgotPassword godoc
+// @Summary Forgot password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailRequest true "Email Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Router /v2/auth/password/forgot [post]
+func forgotPassword() {}
+
+// resetPassword godoc
+// @Summary Reset password
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Router /v2/auth/password/reset [post]
+func resetPassword() {}
+
+// changePassword godoc
+// @Summary Change password
+// @Description Other sessions are revoked, data.token is a fresh JWT for this device
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Change Password Request"
+// @Success 200 {object} models.Envelope{data=models.LoginData}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/auth/password [patch]
+func changePassword() {}
+
+// changeEmail godoc
+// @Summary Change email
+// @Description Send a confirmation link to the new address
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeEmailRequest true "Change Email Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "Email already registered"
+// @Router /v2/auth/email [patch]
+func changeEmail() {}
+
+// confirmEmailChange godoc
+// @Summary Confirm email change
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.TokenRequest true "Token Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "Email already registered"
+// @Router /v2/auth/email/confirm [post]
+func confirmEmailChange() {}
+
+// verifyMFA godoc
+// @Summary Complete login with a TOTP or recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFAVerifyRequest true "MFA Verify Request"
+// @Success 200 {object} models.Envelope{data=models.LoginData}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Failure 429 {object} models.Envelope "Too many failed attempts"
+// @Router /v2/auth/mfa/verify [post]
+func verifyMFA() {}
+
+// enrollMFA godoc
+// @Summary Start 2FA enrollment
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Envelope{data=models.MFAEnrollment}
+// @Failure 401 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "2FA already enabled"
+// @Failure 429 {object} models.Envelope
+// @Router /v2/auth/mfa/enroll [post]
+func enrollMFA() {}
+
+// enableMFA godoc
+// @Summary Enable 2FA
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP Code"
+// @Success 200 {object} models.Envelope{data=models.RecoveryCodes}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/auth/mfa/enable [post]
+func enableMFA() {}
+
+// disableMFA godoc
+// @Summary Disable 2FA
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFADisableRequest true "MFA Disable Request"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/auth/mfa [delete]
+func disableMFA() {}
+
+// oidcLogin godoc
+// @Summary Start social login
+// @Tags Auth
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 302 "Redirect to provider"
+// @Failure 404 {object} models.Envelope "Unknown provider"
+// @Router /v2/auth/oidc/{provider}/login [get]
+func oidcLogin() {}
+
+// oidcCallback godoc
+// @Summary Social login callback
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} models.Envelope{data=models.LoginData}
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Unknown provider"
+// @Failure 502 {object} models.Envelope "Provider error"
+// @Router /v2/auth/oidc/{provider}/callback [get]
+func oidcCallback() {}
@@ -0,0 +1,22 @@
+// Package v2 berisi deklarasi swagger untuk route /v2. Handler aslinya ada di
+// internals/handlers dan dipasang oleh routers.InitV2, fungsi di sini hanya
+// membawa anotasi karena path dan bentuk response /v2 berbeda dari /v1.
+//
+// Generate dengan:
+//
+//	swag init -g doc.go -d api/v2,internals/models --instanceName v2 -o docs/v2
+//
+// @title Social Media API
+// @version 2.0
+// @description Version 2 of the social media API. Every response uses the envelope {data, error, meta}; lists carry meta.pagination.
+// @termsOfService http://swagger.io/terms/
+
+// @host localhost:8080
+// @schemes http
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+package v2
+
+// tipe di anotasi (models.Envelope dan lainnya) di-resolve lewat import ini
+import _ "github.com/ntisrangga142/chat/internals/models"
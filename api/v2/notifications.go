@@ -0,0 +1,15 @@
+package v2
+
+// listNotifications godoc
+// @Summary Unread notifications
+// @Description Unread likes, comments, follows and mentions, newest first
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Notification}
+// @Failure 400 {object} models.Envelope "Invalid cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/notifications [get]
+func listNotifications() {}
@@ -0,0 +1,147 @@
+package v2
+
+// listFeed godoc
+// @Summary Following feed
+// @Description Posts from accounts I follow. Default is newest first; mode=ranked orders by recency, my interactions with the author and engagement.
+// @Description In ranked mode the order is refreshed every 2 minutes; a cursor whose post left the feed returns 400 and the feed is reloaded from the first page.
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param mode query string false "latest (default) or ranked" Enums(latest, ranked)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.PostFeed}
+// @Failure 400 {object} models.Envelope "Invalid mode, invalid or expired cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/posts [get]
+func listFeed() {}
+
+// createPost godoc
+// @Summary Create a post
+// @Description Caption is at most 2200 characters and required when no image is uploaded; up to 10 jpg/png images of at most 1MB each.
+// @Tags Posts
+// @Security BearerAuth
+// @Accept mpfd
+// @Produce json
+// @Param caption formData string false "Post Caption"
+// @Param images formData []file false "Post Images"
+// @Param Idempotency-Key header string false "Client generated key, retries with the same key replay the first response"
+// @Success 201 {object} models.Envelope{data=models.Post}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "Request with the same Idempotency-Key in progress"
+// @Failure 422 {object} models.Envelope "Idempotency-Key reused with a different body"
+// @Router /v2/posts [post]
+func createPost() {}
+
+// listExplore godoc
+// @Summary Explore
+// @Description Trending posts from accounts I don't follow, with at most 2 posts per author.
+// @Description The ranking is snapshotted on the first page, so later pages never repeat or skip posts. Cursors expire after 30 minutes.
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.PostFeed}
+// @Failure 400 {object} models.Envelope "Invalid or expired cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/posts/explore [get]
+func listExplore() {}
+
+// listSaved godoc
+// @Summary My saved posts
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.SavedPost}
+// @Failure 400 {object} models.Envelope "Invalid cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/posts/saved [get]
+func listSaved() {}
+
+// getPost godoc
+// @Summary Get a post
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} models.Envelope{data=models.PostDetail}
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Post not found"
+// @Router /v2/posts/{id} [get]
+func getPost() {}
+
+// likePost godoc
+// @Summary Like a post
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Post not found"
+// @Router /v2/posts/{id}/like [post]
+func likePost() {}
+
+// unlikePost godoc
+// @Summary Unlike a post
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Router /v2/posts/{id}/like [delete]
+func unlikePost() {}
+
+// savePost godoc
+// @Summary Save a post
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Post not found"
+// @Router /v2/posts/{id}/save [post]
+func savePost() {}
+
+// unsavePost godoc
+// @Summary Unsave a post
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Router /v2/posts/{id}/save [delete]
+func unsavePost() {}
+
+// listComments godoc
+// @Summary Comments of a post
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Post ID"
+// @Success 200 {object} models.Envelope{data=[]models.Comment}
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Post not found"
+// @Router /v2/posts/{id}/comments [get]
+func listComments() {}
+
+// createComment godoc
+// @Summary Comment on a post
+// @Description post_id in the body is optional and ignored, the post comes from the path
+// @Tags Posts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param request body models.CreateCommentRequest true "Comment"
+// @Param Idempotency-Key header string false "Client generated key, retries with the same key replay the first response"
+// @Success 201 {object} models.Envelope "Created, data is null"
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "Post not found"
+// @Failure 409 {object} models.Envelope "Request with the same Idempotency-Key in progress"
+// @Failure 422 {object} models.Envelope "Idempotency-Key reused with a different body"
+// @Router /v2/posts/{id}/comments [post]
+func createComment() {}
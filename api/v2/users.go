@@ -0,0 +1,242 @@
+package v2
+
+// getMe godoc
+// @Summary Get my profile
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Envelope{data=models.Profile}
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me [get]
+func getMe() {}
+
+// updateMe godoc
+// @Summary Update my profile
+// @Description JSON merge-patch or multipart form-data (for img and cover)
+// @Tags Users
+// @Security BearerAuth
+// @Accept json,mpfd
+// @Produce json
+// @Param request body models.UpdateProfileRequest false "JSON merge-patch"
+// @Param img formData file false "Profile Image"
+// @Param cover formData file false "Cover Image"
+// @Success 200 {object} models.Envelope{data=models.Profile}
+// @Failure 400 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me [patch]
+func updateMe() {}
+
+// deleteMe godoc
+// @Summary Delete my account
+// @Description Schedule the account for deletion after the grace period
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.DeleteAccountRequest false "Password confirmation"
+// @Success 200 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me [delete]
+func deleteMe() {}
+
+// changeUsername godoc
+// @Summary Change my username
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeUsernameRequest true "New username"
+// @Success 200 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 409 {object} models.Envelope "Username taken"
+// @Router /v2/users/me/username [patch]
+func changeUsername() {}
+
+// deactivateMe godoc
+// @Summary Deactivate my account
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.DeleteAccountRequest false "Password confirmation"
+// @Success 200 {object} models.Envelope
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me/deactivate [post]
+func deactivateMe() {}
+
+// exportMe godoc
+// @Summary Export my data
+// @Description Build a zip of my data and return a signed download link
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} models.Envelope{data=models.ExportLink}
+// @Failure 401 {object} models.Envelope
+// @Failure 429 {object} models.Envelope
+// @Router /v2/users/me/export [post]
+func exportMe() {}
+
+// downloadExport godoc
+// @Summary Download data export
+// @Description Download the export ZIP through the signed, expiring link from exportMe. No login needed.
+// @Tags Users
+// @Produce application/zip
+// @Param file query string true "File name"
+// @Param expires query int true "Expiry (unix)"
+// @Param sig query string true "Signature"
+// @Success 200 {file} file
+// @Failure 403 {object} models.Envelope "Invalid or expired link"
+// @Failure 404 {object} models.Envelope "Export not found"
+// @Router /v2/users/me/export/download [get]
+func downloadExport() {}
+
+// getMyFollowers godoc
+// @Summary My followers
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Follow}
+// @Failure 400 {object} models.Envelope "Invalid cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me/followers [get]
+func getMyFollowers() {}
+
+// getMyFollowing godoc
+// @Summary Who I follow
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Follow}
+// @Failure 400 {object} models.Envelope "Invalid cursor"
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me/following [get]
+func getMyFollowing() {}
+
+// getSuggestions godoc
+// @Summary Who to follow
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of suggestions (default 20, max 50)"
+// @Success 200 {object} models.Envelope{data=[]models.Suggestion}
+// @Failure 401 {object} models.Envelope
+// @Router /v2/users/me/suggestions [get]
+func getSuggestions() {}
+
+// dismissSuggestion godoc
+// @Summary Dismiss a suggestion
+// @Tags Users
+// @Security BearerAuth
+// @Param id path int true "Suggested User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/me/suggestions/{id} [delete]
+func dismissSuggestion() {}
+
+// getByHandle godoc
+// @Summary Get a profile by username
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param handle path string true "Username, with or without @"
+// @Success 200 {object} models.Envelope{data=models.PublicProfile}
+// @Success 307 "Redirect to the current handle"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/by-handle/{handle} [get]
+func getByHandle() {}
+
+// follow godoc
+// @Summary Follow a user
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Target User ID or username"
+// @Param Idempotency-Key header string false "Client generated key, retries with the same key replay the first response"
+// @Success 201 {object} models.Envelope
+// @Failure 400 {object} models.Envelope
+// @Failure 403 {object} models.Envelope "Blocked"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/follow [post]
+func follow() {}
+
+// unfollow godoc
+// @Summary Unfollow a user
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "Target User ID or username"
+// @Success 204 "No Content"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/follow [delete]
+func unfollow() {}
+
+// block godoc
+// @Summary Block a user
+// @Description Follows in both directions are removed
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "Target User ID or username"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.Envelope
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/block [post]
+func block() {}
+
+// unblock godoc
+// @Summary Unblock a user
+// @Tags Users
+// @Security BearerAuth
+// @Param id path string true "Target User ID or username"
+// @Success 204 "No Content"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/block [delete]
+func unblock() {}
+
+// getUserFollowers godoc
+// @Summary A user's followers
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Follow}
+// @Failure 403 {object} models.Envelope "List is private"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/followers [get]
+func getUserFollowers() {}
+
+// getUserFollowing godoc
+// @Summary Who a user follows
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Follow}
+// @Failure 403 {object} models.Envelope "List is private"
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/following [get]
+func getUserFollowing() {}
+
+// getMutualFollowers godoc
+// @Summary Followed by people you know
+// @Tags Users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "meta.pagination.next_cursor from the previous page"
+// @Success 200 {object} models.Envelope{data=[]models.Follow}
+// @Failure 404 {object} models.Envelope "User not found"
+// @Router /v2/users/{id}/mutual [get]
+func getMutualFollowers() {}
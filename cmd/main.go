@@ -2,16 +2,34 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
 	"github.com/ntisrangga142/chat/internals/configs"
+	"github.com/ntisrangga142/chat/internals/handlers"
+	"github.com/ntisrangga142/chat/internals/jobs"
+	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/ntisrangga142/chat/internals/routers"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Dokumentasi /v1, generate dengan:
+//
+//	swag init -g cmd/main.go --exclude api --instanceName v1 -o docs/v1
+//
+// Dokumentasi /v2 ada di package api/v2.
+//
 // @title Social Media API
 // @version 1.0
-// @description API documentation for social media app
+// @description API documentation for social media app. Version 1 is deprecated, new clients should use /v2. The same routes are also served without the /v1 prefix.
 // @termsOfService http://swagger.io/terms/
 
 // @host localhost:8080
@@ -20,33 +38,116 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	// Load ENV
-	err := godotenv.Load()
+	// Load config dari .env, file config, env dan flag
+	cfg, err := configs.Load(os.Args[1:])
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal("Config error", err)
 	}
 
+	// Logger terstruktur, dipakai juga oleh middleware dan job
+	configs.InitLogger(cfg.Log, os.Stdout)
+	slog.Info("Config loaded", "config", cfg.String())
+
+	// Tracing OTLP, dipasang sebelum DB & Redis supaya instrumentasinya aktif
+	shutdownTracing, err := configs.InitTracing(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Tracing error", err)
+	}
+
+	// Load JWT keys
+	keySet, err := pkg.LoadKeySet(cfg.JWT)
+	if err != nil {
+		fatal("JWT key error", err)
+	}
+	pkg.InitKeySet(keySet)
+
 	// Init Database
-	db, err := configs.InitDB()
+	db, err := configs.InitDB(cfg.DB)
 	if err != nil {
-		log.Printf("DB ERROR: %s", err.Error())
+		fatal("Database error", err)
 	}
+	defer db.Close()
 
 	// Ping Database
 	if err := configs.PingDB(db); err != nil {
-		log.Printf("DB ERROR: %s", err.Error())
+		fatal("Database error", err)
 	}
-	log.Println("Database connected")
+	slog.Info("Database connected")
+	prometheus.MustRegister(utils.NewPoolCollector(db))
 
 	//Init Redis
-	rdb := configs.InitRedis()
+	rdb, err := configs.InitRedis(cfg.Redis)
+	if err != nil {
+		fatal("Redis error", err)
+	}
 	if cmd := rdb.Ping(context.Background()); cmd.Err() != nil {
-		log.Println("Ping to Redis failed\nCause: ", cmd.Err().Error())
-		return
+		fatal("Ping to Redis failed", cmd.Err())
 	}
-	log.Println("Redis Connected")
+	slog.Info("Redis connected")
 	defer rdb.Close()
 
-	router := routers.InitRouter(db, rdb)
-	router.Run(":8080")
+	// ctx dibatalkan saat SIGINT / SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Background jobs
+	var jobsDone sync.WaitGroup
+	userRepo := repositories.NewUserRepository(db)
+	jobsDone.Go(func() { jobs.NewPurgeJob(userRepo, rdb, time.Hour, utils.ExportLinkTTL).Start(ctx) })
+	jobsDone.Go(func() { jobs.NewSuggestionJob(userRepo, rdb, time.Hour).Start(ctx) })
+	jobsDone.Go(func() { jobs.NewExploreJob(repositories.NewPostRepository(db), rdb, time.Hour).Start(ctx) })
+
+	// Init Mailer
+	mailer := configs.InitMailer(cfg.Mail)
+
+	// Init OIDC providers
+	providers := configs.InitOIDC(cfg.OIDC)
+
+	router := routers.InitRouter(cfg, db, rdb, mailer, providers)
+	srv := &http.Server{
+		Addr:              cfg.App.Addr,
+		Handler:           router,
+		ReadTimeout:       cfg.App.ReadTimeout,
+		ReadHeaderTimeout: cfg.App.ReadHeaderTimeout,
+		WriteTimeout:      cfg.App.WriteTimeout,
+		IdleTimeout:       cfg.App.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "addr", cfg.App.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("Server error", "error", err)
+		stop()
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received, draining requests")
+		// /readyz menjawab 503 selama jeda ini, load balancer sempat melepas
+		// instance sebelum listener ditutup dan koneksi baru ditolak
+		handlers.SetDraining(true)
+		time.Sleep(cfg.App.ShutdownDrainDelay)
+	}
+
+	// tunggu request yang sedang berjalan, lalu pool & redis ditutup oleh defer
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown error", "error", err)
+	}
+	jobsDone.Wait()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal mencatat error lalu keluar, pengganti log.Fatalf
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,35 @@
+// Package db menyimpan file migration & seed. File migration ikut di-embed ke
+// binary supaya versi schema yang diharapkan bisa dicek tanpa folder db/.
+package db
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// LatestMigrationVersion mengembalikan nomor migration terbaru, contoh 22 untuk
+// 000022_create_saves_table.up.sql. Angka ini yang dicatat golang-migrate di
+// tabel schema_migrations setelah migrate up.
+func LatestMigrationVersion() int64 {
+	entries, err := fs.ReadDir(Migrations, "migrations")
+	if err != nil {
+		return 0
+	}
+
+	var latest int64
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		if v, err := strconv.ParseInt(prefix, 10, 64); err == nil && v > latest {
+			latest = v
+		}
+	}
+	return latest
+}
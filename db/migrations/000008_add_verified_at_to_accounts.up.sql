@@ -0,0 +1,4 @@
+ALTER TABLE public.accounts ADD COLUMN verified_at TIMESTAMP NULL;
+
+-- akun lama dianggap sudah terverifikasi
+UPDATE public.accounts SET verified_at = created_at;
\ No newline at end of file
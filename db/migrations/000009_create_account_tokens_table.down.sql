@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.account_tokens;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE public.account_tokens (
+    id          INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id  INT          NOT NULL REFERENCES public.accounts(id),
+    purpose     VARCHAR(32)  NOT NULL,
+    token_hash  VARCHAR(255) NOT NULL UNIQUE,
+    expires_at  TIMESTAMP    NOT NULL,
+    used_at     TIMESTAMP    NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE public.account_tokens DROP COLUMN IF EXISTS payload;
\ No newline at end of file
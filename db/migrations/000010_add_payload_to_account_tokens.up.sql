@@ -0,0 +1 @@
+ALTER TABLE public.account_tokens ADD COLUMN payload VARCHAR(255) NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.account_mfa;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE public.account_mfa (
+    account_id      INT PRIMARY KEY REFERENCES public.accounts(id),
+    secret          VARCHAR(64) NOT NULL,
+    last_used_step  BIGINT      NOT NULL DEFAULT 0,
+    enabled_at      TIMESTAMP   NULL,
+    created_at      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP   NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.recovery_codes;
\ No newline at end of file
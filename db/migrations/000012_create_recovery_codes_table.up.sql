@@ -0,0 +1,7 @@
+CREATE TABLE public.recovery_codes (
+    id          INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id  INT          NOT NULL REFERENCES public.accounts(id),
+    code_hash   VARCHAR(255) NOT NULL UNIQUE,
+    used_at     TIMESTAMP    NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
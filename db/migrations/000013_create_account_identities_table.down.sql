@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.account_identities;
\ No newline at end of file
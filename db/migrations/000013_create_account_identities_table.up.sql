@@ -0,0 +1,8 @@
+CREATE TABLE public.account_identities (
+    provider    VARCHAR(64)  NOT NULL,
+    subject     VARCHAR(255) NOT NULL,
+    account_id  INT          NOT NULL REFERENCES public.accounts(id),
+    email       VARCHAR(255) NULL,
+    created_at  TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT account_identities_pk PRIMARY KEY (provider, subject)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE public.accounts
+    DROP COLUMN IF EXISTS delete_after,
+    DROP COLUMN IF EXISTS purged_at;
\ No newline at end of file
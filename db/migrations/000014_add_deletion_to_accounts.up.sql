@@ -0,0 +1,3 @@
+ALTER TABLE public.accounts
+    ADD COLUMN delete_after TIMESTAMP NULL,
+    ADD COLUMN purged_at    TIMESTAMP NULL;
\ No newline at end of file
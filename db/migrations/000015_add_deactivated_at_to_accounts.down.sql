@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS public.active_accounts;
+
+ALTER TABLE public.accounts DROP COLUMN IF EXISTS deactivated_at;
\ No newline at end of file
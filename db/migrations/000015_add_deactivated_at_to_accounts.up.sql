@@ -0,0 +1,7 @@
+ALTER TABLE public.accounts ADD COLUMN deactivated_at TIMESTAMP NULL;
+
+-- akun yang tampil di feed, profile, follower, dan notifikasi
+CREATE VIEW public.active_accounts AS
+SELECT id
+FROM public.accounts
+WHERE deactivated_at IS NULL AND delete_after IS NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.username_history;
+
+DROP INDEX IF EXISTS public.profiles_username_lower_idx;
+
+ALTER TABLE public.profiles
+    DROP COLUMN IF EXISTS username,
+    DROP COLUMN IF EXISTS username_changed_at;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE public.profiles
+    ADD COLUMN username            VARCHAR(30) NULL,
+    ADD COLUMN username_changed_at TIMESTAMP   NULL;
+
+CREATE UNIQUE INDEX profiles_username_lower_idx ON public.profiles (LOWER(username));
+
+-- handle lama, dipakai untuk redirect ke handle baru
+CREATE TABLE public.username_history (
+    username    VARCHAR(30) NOT NULL PRIMARY KEY,
+    account_id  INT         NOT NULL REFERENCES public.accounts(id),
+    changed_at  TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE public.mentions (
+    id          INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id  INT       NOT NULL REFERENCES public.accounts(id),
+    from_id     INT       NOT NULL REFERENCES public.accounts(id),
+    post_id     INT       NOT NULL REFERENCES public.posts(id),
+    comment_id  INT       NULL REFERENCES public.comments(id),
+    read        BOOLEAN,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
\ No newline at end of file
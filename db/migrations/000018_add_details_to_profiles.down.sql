@@ -0,0 +1,8 @@
+ALTER TABLE public.profiles
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS website,
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS pronouns,
+    DROP COLUMN IF EXISTS birthdate,
+    DROP COLUMN IF EXISTS birthdate_visibility,
+    DROP COLUMN IF EXISTS cover_img;
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE public.profiles
+    ADD COLUMN bio                  VARCHAR(160) NULL,
+    ADD COLUMN website              VARCHAR(255) NULL,
+    ADD COLUMN location             VARCHAR(100) NULL,
+    ADD COLUMN pronouns             VARCHAR(40)  NULL,
+    ADD COLUMN birthdate            DATE         NULL,
+    ADD COLUMN birthdate_visibility VARCHAR(10)  NOT NULL DEFAULT 'private'
+        CHECK (birthdate_visibility IN ('public', 'followers', 'private')),
+    ADD COLUMN cover_img            VARCHAR(255) NULL;
\ No newline at end of file
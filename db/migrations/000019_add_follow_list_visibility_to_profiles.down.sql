@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.followers_follower_created_idx;
+DROP INDEX IF EXISTS public.followers_account_created_idx;
+
+ALTER TABLE public.profiles
+    DROP COLUMN IF EXISTS follow_list_visibility;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE public.profiles
+    ADD COLUMN follow_list_visibility VARCHAR(10) NOT NULL DEFAULT 'public'
+        CHECK (follow_list_visibility IN ('public', 'followers', 'private'));
+
+CREATE INDEX followers_account_created_idx ON public.followers (account_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX followers_follower_created_idx ON public.followers (follower_id, created_at DESC) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.blocks;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE public.blocks (
+    blocker_id  INT       NOT NULL REFERENCES public.accounts(id),
+    blocked_id  INT       NOT NULL REFERENCES public.accounts(id),
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT blocks_pk PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE INDEX blocks_blocked_idx ON public.blocks (blocked_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.suggestion_dismissals;
\ No newline at end of file
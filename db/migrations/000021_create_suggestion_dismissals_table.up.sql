@@ -0,0 +1,7 @@
+-- saran who-to-follow yang ditutup user, tidak muncul lagi
+CREATE TABLE public.suggestion_dismissals (
+    account_id    INT       NOT NULL REFERENCES public.accounts(id),
+    dismissed_id  INT       NOT NULL REFERENCES public.accounts(id),
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT suggestion_dismissals_pk PRIMARY KEY (account_id, dismissed_id)
+);
\ No newline at end of file
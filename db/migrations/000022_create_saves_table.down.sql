@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.saves;
\ No newline at end of file
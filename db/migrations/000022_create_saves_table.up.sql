@@ -0,0 +1,8 @@
+CREATE TABLE public.saves (
+    account_id  INT       NOT NULL REFERENCES public.accounts(id),
+    post_id     INT       NOT NULL REFERENCES public.posts(id),
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT saves_pk PRIMARY KEY (account_id, post_id)
+);
+
+CREATE INDEX saves_post_idx ON public.saves (post_id);
\ No newline at end of file
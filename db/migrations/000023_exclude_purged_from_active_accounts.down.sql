@@ -0,0 +1,4 @@
+CREATE OR REPLACE VIEW public.active_accounts AS
+SELECT id
+FROM public.accounts
+WHERE deactivated_at IS NULL AND delete_after IS NULL;
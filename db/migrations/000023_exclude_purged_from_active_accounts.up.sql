@@ -0,0 +1,5 @@
+-- akun yang sudah di-purge ("Deleted User") tidak lagi dianggap aktif
+CREATE OR REPLACE VIEW public.active_accounts AS
+SELECT id
+FROM public.accounts
+WHERE deactivated_at IS NULL AND delete_after IS NULL AND purged_at IS NULL;
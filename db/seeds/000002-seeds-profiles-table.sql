@@ -1,8 +1,7 @@
-INSERT INTO public.profiles (id, fullname, phone, img, created_at)
+INSERT INTO public.profiles (id, fullname, username, phone, img, created_at)
 VALUES
-(1, 'Alice Johnson', '081234567890', 'alice.jpg', NOW()),
-(2, 'Bob Smith', '081298765432', 'bob.png', NOW()),
-(3, 'Charlie Brown', '082134567891', 'charlie.png', NOW()),
-(4, 'Diana Prince', '083145678912', 'diana.jpg', NOW()),
-(5, 'Eric Cartman', '084156789123', 'eric.jpg', NOW());
-
+(1, 'Alice Johnson', 'alice', '081234567890', 'alice.jpg', NOW()),
+(2, 'Bob Smith', 'bob.smith', '081298765432', 'bob.png', NOW()),
+(3, 'Charlie Brown', 'charlie', '082134567891', 'charlie.png', NOW()),
+(4, 'Diana Prince', 'diana', '083145678912', 'diana.jpg', NOW()),
+(5, 'Eric Cartman', 'eric_c', '084156789123', 'eric.jpg', NOW());
\ No newline at end of file
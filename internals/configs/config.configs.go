@@ -0,0 +1,454 @@
+package configs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// Config berisi semua konfigurasi aplikasi. Urutan sumber (yang belakang menang):
+// default, file .env, file dari -config / CONFIG_FILE, environment, lalu flag.
+type Config struct {
+	App        AppConfig
+	Log        LogConfig
+	Tracing    TracingConfig
+	DB         DBConfig
+	Redis      RedisConfig
+	JWT        pkg.KeyConfig
+	Mail       MailConfig
+	OIDC       []OIDCConfig
+	RateLimits map[string]RateLimit
+}
+
+type AppConfig struct {
+	Addr        string // APP_ADDR, alamat listen server
+	URL         string // APP_URL, base url frontend untuk link di email
+	SwaggerHost string // SWAGGER_HOST, host yang ditampilkan di swagger
+	MFAIssuer   string // MFA_ISSUER, nama yang muncul di aplikasi authenticator
+
+	// SERVER_*_TIMEOUT, format time.Duration (contoh 15s)
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// SHUTDOWN_TIMEOUT, batas menunggu request yang sedang berjalan saat SIGTERM
+	ShutdownTimeout time.Duration
+	// SHUTDOWN_DRAIN_DELAY, jeda setelah /readyz menjawab 503 sebelum listener
+	// ditutup, supaya load balancer sempat berhenti mengirim request. 0 = tanpa jeda.
+	ShutdownDrainDelay time.Duration
+	// IDEMPOTENCY_TTL, lama response disimpan untuk header Idempotency-Key
+	IdempotencyTTL time.Duration
+	// TRUSTED_PROXIES, IP atau CIDR load balancer yang boleh mengirim
+	// X-Forwarded-For. Kosong berarti header itu diabaikan dan ClientIP
+	// adalah alamat koneksi.
+	TrustedProxies []string
+}
+
+type LogConfig struct {
+	Level  slog.Level // LOG_LEVEL: debug | info | warn | error
+	Format string     // LOG_FORMAT: json | text
+}
+
+// OTEL_* mengikuti nama env standar OpenTelemetry
+type TracingConfig struct {
+	ServiceName string  // OTEL_SERVICE_NAME
+	Endpoint    string  // OTEL_EXPORTER_OTLP_ENDPOINT, kosong = span tidak diekspor
+	SampleRatio float64 // OTEL_TRACES_SAMPLE_RATIO, 0 sampai 1
+}
+
+type DBConfig struct {
+	User string
+	Pass string
+	Host string
+	Port string
+	Name string
+}
+
+type RedisConfig struct {
+	Host string
+	Port string
+	User string
+	Pass string
+}
+
+type MailConfig struct {
+	Driver   string // smtp | log
+	LogFile  string
+	From     string
+	SMTPHost string
+	SMTPPort string
+	SMTPUser string
+	SMTPPass string
+}
+
+type OIDCConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// RateLimit override dari env RATELIMIT_<NAME>, format "<limit>/<window>"
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+const rateLimitPrefix = "RATELIMIT_"
+
+// Load membaca konfigurasi dari semua sumber lalu memvalidasinya.
+// args biasanya os.Args[1:].
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("chat", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to an optional KEY=VALUE config file")
+	addr := fs.String("addr", "", "listen address, overrides APP_ADDR")
+	swaggerHost := fs.String("swagger-host", "", "host shown in swagger, overrides SWAGGER_HOST")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	if err := mergeFile(values, ".env", true); err != nil {
+		return nil, err
+	}
+	if *configFile != "" {
+		if err := mergeFile(values, *configFile, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			values[k] = v
+		}
+	}
+
+	cfg, err := fromValues(values)
+	if err != nil {
+		return nil, err
+	}
+	if *addr != "" {
+		cfg.App.Addr = *addr
+	}
+	if *swaggerHost != "" {
+		cfg.App.SwaggerHost = *swaggerHost
+	}
+	if cfg.App.SwaggerHost == "" {
+		cfg.App.SwaggerHost = defaultSwaggerHost(cfg.App.Addr)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// file .env boleh tidak ada, file dari -config wajib ada
+func mergeFile(values map[string]string, path string, optional bool) error {
+	fileValues, err := godotenv.Read(path)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	for k, v := range fileValues {
+		values[k] = v
+	}
+	return nil
+}
+
+func fromValues(values map[string]string) (*Config, error) {
+	get := func(key, def string) string {
+		if v := strings.TrimSpace(values[key]); v != "" {
+			return v
+		}
+		return def
+	}
+
+	cfg := &Config{
+		App: AppConfig{
+			Addr:        get("APP_ADDR", ":8080"),
+			URL:         get("APP_URL", ""),
+			SwaggerHost: get("SWAGGER_HOST", ""),
+			MFAIssuer:   get("MFA_ISSUER", "SocialMedia"),
+		},
+		DB: DBConfig{
+			User: get("DB_USER", ""),
+			Pass: get("DB_PASS", ""),
+			Host: get("DB_HOST", ""),
+			Port: get("DB_PORT", "5432"),
+			Name: get("DB_NAME", ""),
+		},
+		Redis: RedisConfig{
+			Host: get("RDBHOST", ""),
+			Port: get("RDBPORT", "6379"),
+			User: get("RDBUSER", ""),
+			Pass: get("RDBPASS", ""),
+		},
+		JWT: pkg.KeyConfig{
+			Secret:     get("JWT_SECRET", ""),
+			Issuer:     get("JWT_ISSUER", ""),
+			Audience:   get("JWT_AUDIENCE", ""),
+			SigningKey: get("JWT_SIGNING_KEY", ""),
+			SigningKID: get("JWT_SIGNING_KID", ""),
+			VerifyKeys: splitList(get("JWT_VERIFY_KEYS", ""), ","),
+		},
+		Mail: MailConfig{
+			Driver:   get("MAIL_DRIVER", ""),
+			LogFile:  get("MAIL_LOG_FILE", ""),
+			From:     get("MAIL_FROM", ""),
+			SMTPHost: get("SMTP_HOST", ""),
+			SMTPPort: get("SMTP_PORT", ""),
+			SMTPUser: get("SMTP_USER", ""),
+			SMTPPass: get("SMTP_PASS", ""),
+		},
+		RateLimits: map[string]RateLimit{},
+	}
+
+	var errs []error
+	duration := func(key string, def time.Duration) time.Duration {
+		raw := get(key, "")
+		if raw == "" {
+			return def
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration, got %q", key, raw))
+			return def
+		}
+		return d
+	}
+	cfg.App.ReadTimeout = duration("SERVER_READ_TIMEOUT", 15*time.Second)
+	cfg.App.ReadHeaderTimeout = duration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second)
+	cfg.App.WriteTimeout = duration("SERVER_WRITE_TIMEOUT", 30*time.Second)
+	cfg.App.IdleTimeout = duration("SERVER_IDLE_TIMEOUT", 60*time.Second)
+	cfg.App.ShutdownTimeout = duration("SHUTDOWN_TIMEOUT", 20*time.Second)
+	cfg.App.IdempotencyTTL = duration("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	// jeda drain boleh 0, jadi tidak memakai duration
+	cfg.App.ShutdownDrainDelay = 5 * time.Second
+	if raw := get("SHUTDOWN_DRAIN_DELAY", ""); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("SHUTDOWN_DRAIN_DELAY must be a duration of 0 or more, got %q", raw))
+		} else {
+			cfg.App.ShutdownDrainDelay = d
+		}
+	}
+
+	for _, proxy := range splitList(get("TRUSTED_PROXIES", ""), ",") {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must be a list of IPs or CIDRs, got %q", proxy))
+			continue
+		}
+		cfg.App.TrustedProxies = append(cfg.App.TrustedProxies, proxy)
+	}
+
+	boolean := func(key string, def bool) bool {
+		raw := get(key, "")
+		if raw == "" {
+			return def
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be true or false, got %q", key, raw))
+			return def
+		}
+		return b
+	}
+	cfg.JWT.AcceptLegacy = boolean("JWT_ACCEPT_LEGACY", false)
+
+	cfg.Log.Format = get("LOG_FORMAT", "json")
+	if err := cfg.Log.Level.UnmarshalText([]byte(get("LOG_LEVEL", "info"))); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", get("LOG_LEVEL", "")))
+	}
+
+	cfg.Tracing.ServiceName = get("OTEL_SERVICE_NAME", "chat-api")
+	cfg.Tracing.Endpoint = get("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	cfg.Tracing.SampleRatio = 1
+	if raw := get("OTEL_TRACES_SAMPLE_RATIO", ""); raw != "" {
+		ratio, err := strconv.ParseFloat(raw, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLE_RATIO must be a number between 0 and 1, got %q", raw))
+		} else {
+			cfg.Tracing.SampleRatio = ratio
+		}
+	}
+
+	for _, name := range splitList(get("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg.OIDC = append(cfg.OIDC, OIDCConfig{
+			Name:         name,
+			Issuer:       get(prefix+"ISSUER", ""),
+			ClientID:     get(prefix+"CLIENT_ID", ""),
+			ClientSecret: get(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  get(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(get(prefix+"SCOPES", "")),
+		})
+	}
+
+	for key, raw := range values {
+		if !strings.HasPrefix(key, rateLimitPrefix) || strings.TrimSpace(raw) == "" {
+			continue
+		}
+		rl, err := parseRateLimit(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		cfg.RateLimits[strings.TrimPrefix(key, rateLimitPrefix)] = rl
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func parseRateLimit(raw string) (RateLimit, error) {
+	limit, window, ok := strings.Cut(strings.TrimSpace(raw), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid value %q, expected <limit>/<window>", raw)
+	}
+	l, err := strconv.Atoi(limit)
+	if err != nil || l <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid limit in %q", raw)
+	}
+	w, err := time.ParseDuration(window)
+	if err != nil || w <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid window in %q", raw)
+	}
+	return RateLimit{Limit: l, Window: w}, nil
+}
+
+func splitList(raw, sep string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// ":8080" -> "localhost:8080"
+func defaultSwaggerHost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// Validate mengumpulkan semua kesalahan konfigurasi supaya bisa diperbaiki sekaligus
+func (c *Config) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+	port := func(key, value string) {
+		if n, err := strconv.Atoi(value); value != "" && (err != nil || n <= 0 || n > 65535) {
+			errs = append(errs, fmt.Errorf("%s must be a port number, got %q", key, value))
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(c.App.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("APP_ADDR %q is not a valid listen address", c.App.Addr))
+	}
+
+	required("DB_USER", c.DB.User)
+	required("DB_HOST", c.DB.Host)
+	required("DB_NAME", c.DB.Name)
+	port("DB_PORT", c.DB.Port)
+
+	required("RDBHOST", c.Redis.Host)
+	port("RDBPORT", c.Redis.Port)
+
+	// tetap wajib walau memakai JWT_SIGNING_KEY, secret dipakai untuk token
+	// sekali pakai (verifikasi email, reset password) dan signed url
+	required("JWT_SECRET", c.JWT.Secret)
+
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", c.Log.Format))
+	}
+
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) url, got %q", c.Tracing.Endpoint))
+		}
+	}
+
+	// tidak ada default: driver log menulis link berisi token aktif ke
+	// stdout, jadi harus dipilih secara eksplisit
+	switch c.Mail.Driver {
+	case "":
+		required("MAIL_DRIVER", c.Mail.Driver)
+	case "log":
+	case "smtp":
+		required("SMTP_HOST", c.Mail.SMTPHost)
+		required("SMTP_PORT", c.Mail.SMTPPort)
+		required("MAIL_FROM", c.Mail.From)
+		port("SMTP_PORT", c.Mail.SMTPPort)
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER must be smtp or log, got %q", c.Mail.Driver))
+	}
+
+	for _, p := range c.OIDC {
+		prefix := "OIDC_" + strings.ToUpper(p.Name) + "_"
+		required(prefix+"ISSUER", p.Issuer)
+		required(prefix+"CLIENT_ID", p.ClientID)
+		required(prefix+"REDIRECT_URL", p.RedirectURL)
+	}
+
+	return errors.Join(errs...)
+}
+
+const redacted = "******"
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Redacted mengembalikan salinan config dengan semua secret disamarkan, aman untuk di-log
+func (c Config) Redacted() Config {
+	c.DB.Pass = redact(c.DB.Pass)
+	c.Redis.Pass = redact(c.Redis.Pass)
+	c.JWT.Secret = redact(c.JWT.Secret)
+	c.Mail.SMTPPass = redact(c.Mail.SMTPPass)
+
+	oidc := make([]OIDCConfig, len(c.OIDC))
+	for i, p := range c.OIDC {
+		p.ClientSecret = redact(p.ClientSecret)
+		oidc[i] = p
+	}
+	c.OIDC = oidc
+	return c
+}
+
+// tipe tanpa method String supaya %+v tidak memanggil String lagi
+type configFields Config
+
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", configFields(c.Redacted()))
+}
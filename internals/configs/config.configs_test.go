@@ -0,0 +1,131 @@
+package configs_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/configs"
+)
+
+// env minimal yang lolos validasi
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_USER", "chat")
+	t.Setenv("DB_PASS", "db-password")
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_NAME", "chat")
+	t.Setenv("RDBHOST", "localhost")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("MAIL_DRIVER", "log")
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "chat.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setRequiredEnv(t)
+	file := writeConfigFile(t, "APP_ADDR=:7000\nAPP_URL=https://from-file.example\nMFA_ISSUER=FromFile\nRATELIMIT_POST_CREATE=5/30s\nSHUTDOWN_DRAIN_DELAY=0s\n")
+	t.Setenv("APP_URL", "https://from-env.example")
+
+	cfg, err := configs.Load([]string{"-config", file, "-swagger-host", "api.example:443"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "file over default", got: cfg.App.Addr, want: ":7000"},
+		{name: "env over file", got: cfg.App.URL, want: "https://from-env.example"},
+		{name: "flag over everything", got: cfg.App.SwaggerHost, want: "api.example:443"},
+		{name: "default", got: cfg.Log.Format, want: "json"},
+		{name: "rate limit override", got: cfg.RateLimits["POST_CREATE"], want: configs.RateLimit{Limit: 5, Window: 30 * time.Second}},
+		{name: "drain delay disabled", got: cfg.App.ShutdownDrainDelay, want: time.Duration(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing db host", env: map[string]string{"DB_HOST": ""}, wantErr: "DB_HOST is required"},
+		{name: "invalid port", env: map[string]string{"DB_PORT": "postgres"}, wantErr: "DB_PORT must be a port number"},
+		{name: "missing mail driver", env: map[string]string{"MAIL_DRIVER": ""}, wantErr: "MAIL_DRIVER is required"},
+		{name: "smtp without host", env: map[string]string{"MAIL_DRIVER": "smtp"}, wantErr: "SMTP_HOST is required"},
+		{name: "unknown mail driver", env: map[string]string{"MAIL_DRIVER": "pigeon"}, wantErr: "MAIL_DRIVER must be smtp or log"},
+		{name: "oidc provider incomplete", env: map[string]string{"OIDC_PROVIDERS": "google"}, wantErr: "OIDC_GOOGLE_ISSUER is required"},
+		{name: "invalid rate limit", env: map[string]string{"RATELIMIT_AUTH": "ten/1m"}, wantErr: "RATELIMIT_AUTH"},
+		{name: "unknown log level", env: map[string]string{"LOG_LEVEL": "verbose"}, wantErr: "LOG_LEVEL must be debug, info, warn or error"},
+		{name: "unknown log format", env: map[string]string{"LOG_FORMAT": "xml"}, wantErr: "LOG_FORMAT must be json or text"},
+		{name: "invalid sample ratio", env: map[string]string{"OTEL_TRACES_SAMPLE_RATIO": "1.5"}, wantErr: "OTEL_TRACES_SAMPLE_RATIO must be a number between 0 and 1"},
+		{name: "invalid otlp endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "localhost:4318"}, wantErr: "OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) url"},
+		{name: "invalid accept legacy", env: map[string]string{"JWT_ACCEPT_LEGACY": "maybe"}, wantErr: "JWT_ACCEPT_LEGACY must be true or false"},
+		{name: "invalid idempotency ttl", env: map[string]string{"IDEMPOTENCY_TTL": "1 day"}, wantErr: "IDEMPOTENCY_TTL must be a positive duration"},
+		{name: "negative drain delay", env: map[string]string{"SHUTDOWN_DRAIN_DELAY": "-5s"}, wantErr: "SHUTDOWN_DRAIN_DELAY must be a duration of 0 or more"},
+		{name: "invalid trusted proxy", env: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,lb.internal"}, wantErr: "TRUSTED_PROXIES must be a list of IPs or CIDRs"},
+		{name: "invalid addr flag", args: []string{"-addr", "8080"}, wantErr: "APP_ADDR"},
+		{name: "missing config file", args: []string{"-config", "does-not-exist.env"}, wantErr: "failed to read config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := configs.Load(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigStringRedactsSecrets(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("SMTP_PASS", "smtp-password")
+	t.Setenv("OIDC_PROVIDERS", "google")
+	t.Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "client-id")
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "oidc-secret")
+	t.Setenv("OIDC_GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/oidc/google/callback")
+
+	cfg, err := configs.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := cfg.String()
+	for _, secret := range []string{"db-password", "jwt-secret", "smtp-password", "oidc-secret"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("config string leaks %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "client-id") {
+		t.Fatalf("config string should keep non-secret values: %s", out)
+	}
+	// redaksi tidak mengubah config asli
+	if cfg.OIDC[0].ClientSecret != "oidc-secret" || cfg.JWT.Secret != "jwt-secret" {
+		t.Fatal("Redacted modified the original config")
+	}
+}
@@ -3,23 +3,30 @@ package configs
 import (
 	"context"
 	"fmt"
-	"os"
+	"net"
+	"net/url"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func InitDB() (*pgxpool.Pool, error) {
-	connstring := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASS"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
-
-	return pgxpool.New(context.Background(), connstring)
+func InitDB(cfg DBConfig) (*pgxpool.Pool, error) {
+	connstring := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Pass),
+		Host:   net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:   "/" + cfg.Name,
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(connstring.String())
+	if err != nil {
+		return nil, err
+	}
+	// setiap query menjadi span di bawah span request
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
+
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
 }
 
 func PingDB(pool *pgxpool.Pool) error {
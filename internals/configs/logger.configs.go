@@ -0,0 +1,21 @@
+package configs
+
+import (
+	"io"
+	"log/slog"
+)
+
+// InitLogger memasang slog sebagai logger default. Pemanggilan package log
+// lama juga ikut diteruskan ke handler ini.
+func InitLogger(cfg LogConfig, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+
+	var handler slog.Handler = slog.NewJSONHandler(w, opts)
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger
+}
@@ -0,0 +1,13 @@
+package configs
+
+import (
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// InitMailer memilih implementasi mailer dari MAIL_DRIVER (smtp | log)
+func InitMailer(cfg MailConfig) pkg.Mailer {
+	if cfg.Driver == "smtp" {
+		return pkg.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.From)
+	}
+	return pkg.NewLogMailer(cfg.LogFile)
+}
@@ -0,0 +1,16 @@
+package configs
+
+import (
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// InitOIDC membuat provider dari OIDC_PROVIDERS (contoh "google,github").
+// Setiap provider dikonfigurasi lewat OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL dan _SCOPES (opsional, dipisah spasi).
+func InitOIDC(cfgs []OIDCConfig) map[string]*pkg.OIDCProvider {
+	providers := make(map[string]*pkg.OIDCProvider, len(cfgs))
+	for _, cfg := range cfgs {
+		providers[cfg.Name] = pkg.NewOIDCProvider(cfg.Name, cfg.Issuer, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, cfg.Scopes)
+	}
+	return providers
+}
@@ -2,19 +2,22 @@ package configs
 
 import (
 	"fmt"
-	"os"
+	"net"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitRedis() *redis.Client {
-	rdbHost := os.Getenv("RDBHOST")
-	rdbPort := os.Getenv("RDBPORT")
-	rdbUser := os.Getenv("RDBUSER")
-	rdbPass := os.Getenv("RDBPASS")
-	return redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", rdbHost, rdbPort),
-		Username: rdbUser,
-		Password: rdbPass,
+func InitRedis(cfg RedisConfig) (*redis.Client, error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Username: cfg.User,
+		Password: cfg.Pass,
 	})
+
+	// setiap command redis menjadi span di bawah span request
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		return nil, fmt.Errorf("failed to instrument redis: %w", err)
+	}
+	return rdb, nil
 }
@@ -1,13 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/configs"
 	"github.com/ntisrangga142/chat/internals/models"
 	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/ntisrangga142/chat/internals/utils"
@@ -16,14 +17,24 @@ import (
 )
 
 type AuthHandler struct {
-	repo *repositories.Auth
-	rdb  *redis.Client
+	repo      repositories.AuthStore
+	rdb       *redis.Client
+	mailer    pkg.Mailer
+	totp      *pkg.TOTP
+	providers map[string]*pkg.OIDCProvider
+	app       configs.AppConfig
 }
 
-func NewAuthHandler(repo *repositories.Auth, rdb *redis.Client) *AuthHandler {
-	return &AuthHandler{repo: repo, rdb: rdb}
+func NewAuthHandler(repo repositories.AuthStore, rdb *redis.Client, mailer pkg.Mailer, providers map[string]*pkg.OIDCProvider, app configs.AppConfig) *AuthHandler {
+	return &AuthHandler{repo: repo, rdb: rdb, mailer: mailer, totp: pkg.NewTOTP(), providers: providers, app: app}
 }
 
+// masa berlaku token sekali pakai
+const (
+	verifyEmailTTL   = 24 * time.Hour
+	resetPasswordTTL = 30 * time.Minute
+)
+
 // Register godoc
 // @Summary Register a new account
 // @Description Create new account with email & password
@@ -31,27 +42,28 @@ func NewAuthHandler(repo *repositories.Auth, rdb *redis.Client) *AuthHandler {
 // @Accept json
 // @Produce json
 // @Param request body models.AuthRequest true "Register Request"
-// @Success 201 {object} models.ErrorResponse "Register successful"
+// @Success 201 {object} models.ResponseAny "Register successful"
 // @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 409 {object} models.ErrorResponse "Email already registered"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /auth/register [post]
+// @Router /v1/auth/register [post]
 func (h *AuthHandler) Register(ctx *gin.Context) {
 	var req models.AuthRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "failed binding data", err)
+		utils.HandleBindError(ctx, err)
 		return
 	}
 
 	// Validasi email
 	if valid := utils.ValidateEmail(req.Email); !valid {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "invalid email", fmt.Errorf("invalid email"))
+		utils.HandleFieldError(ctx, "email", "invalid_format", "email must be a valid email address")
 		return
 	}
 
 	// Validasi password
 	if valid := utils.ValidatePassword(req.Password); !valid {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "invalid password", fmt.Errorf("invalid password"))
+		utils.HandleFieldError(ctx, "password", "weak_password", utils.PasswordRequirement)
 		return
 	}
 
@@ -60,25 +72,32 @@ func (h *AuthHandler) Register(ctx *gin.Context) {
 	hashConfig.UseRecommended()
 	hashedPassword, err := hashConfig.GenHash(req.Password)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed hashed password", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed hashed password", err)
 		return
 	}
 
 	// Repository Register
-	if err := h.repo.Register(ctx, req.Email, hashedPassword); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "Email is already registered", err)
+	userID, err := h.repo.Register(ctx, req.Email, hashedPassword)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to register account")
 		return
 	}
+	utils.SignupsTotal.WithLabelValues("password").Inc()
+
+	// Kirim email verifikasi
+	if err := h.sendVerificationMail(ctx, userID, req.Email); err != nil {
+		utils.Logger(ctx).Warn("Failed to send verification email", "error", err)
+	}
 
 	ctx.JSON(http.StatusCreated, models.Response[any]{
 		Success: true,
-		Message: "Register account successful",
+		Message: "Register account successful, please check your email to verify your account",
 	})
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user with email & password, return JWT token
+// @Description Authenticate user with email & password, return JWT token. If 2FA is enabled a short-lived mfa_token is returned instead.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -87,51 +106,58 @@ func (h *AuthHandler) Register(ctx *gin.Context) {
 // @Failure 400 {object} models.ErrorResponse "Invalid request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /auth/login [post]
+// @Router /v1/auth [post]
 func (h *AuthHandler) Login(ctx *gin.Context) {
 	var req models.AuthRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "failed binding data", err)
+		utils.HandleBindError(ctx, err)
 		return
 	}
 
 	// Cari akun
-	userID, hashedPassword, err := h.repo.Login(ctx.Request.Context(), req.Email)
+	account, err := h.repo.Login(ctx.Request.Context(), req.Email)
+	if errors.Is(err, repositories.ErrAccountNotFound) {
+		// pesan sama dengan password salah supaya email terdaftar tidak bocor
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid username or password", err)
+		return
+	}
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "user not found", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get account", err)
 		return
 	}
-	if userID == 0 {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "user not found", err)
+	userID := account.ID
+
+	// Akun dari social login belum punya password
+	if account.Password == "" {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid username or password", errors.New("account has no password"))
 		return
 	}
 
 	// Verifikasi password
 	hashConfig := pkg.NewHashConfig()
-	match, err := hashConfig.ComparePasswordAndHash(req.Password, hashedPassword)
+	match, err := hashConfig.ComparePasswordAndHash(req.Password, account.Password)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed compare password", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed compare password", err)
 		return
 	}
 	if !match {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid username or password", errors.New("invalid password"))
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid username or password", errors.New("invalid password"))
 		return
 	}
 
-	// Generate JWT
-	claims := pkg.NewJWTClaims(userID)
-	token, err := claims.GenToken()
-	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed generate token", err)
+	// Rehash jika parameter argon2 yang tersimpan lebih lemah dari rekomendasi
+	if hashConfig.NeedsRehash() {
+		h.rehashPassword(ctx, userID, req.Password)
+	}
+
+	// Email harus sudah diverifikasi
+	if account.VerifiedAt == nil {
+		utils.HandleError(ctx, http.StatusForbidden, "email is not verified, please check your inbox", errors.New("email not verified"))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models.ResponseLogin{
-		Success: true,
-		Message: "Login successful",
-		Token:   token,
-	})
+	h.completeLogin(ctx, userID)
 }
 
 // Logout godoc
@@ -140,31 +166,31 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 // @Tags Auth
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {object} models.ErrorResponse "Successfully logged out"
+// @Success 200 {object} models.ResponseAny "Successfully logged out"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
-// @Router /auth/logout [post]
+// @Router /v1/auth/ [delete]
 func (h *AuthHandler) Logout(ctx *gin.Context) {
 	token, err := utils.GetToken(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "failed get token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "failed get token", err)
 		return
 	}
 
 	expiresAt, err := utils.GetExpiredFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "failed get expired time token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "failed get expired time token", err)
 		return
 	}
 
 	expiresIn := time.Until(expiresAt)
 	if expiresIn <= 0 {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "token already expired", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "token already expired", err)
 		return
 	}
 
 	redisKey := fmt.Sprintf("Blacklist:%s", token)
 	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, token, expiresIn); err != nil {
-		log.Println("Failed to set redis cache:", err)
+		utils.Logger(ctx).Warn("Failed to set redis cache", "error", err)
 	}
 
 	ctx.JSON(http.StatusOK, models.Response[any]{
@@ -172,3 +198,435 @@ func (h *AuthHandler) Logout(ctx *gin.Context) {
 		Message: "Successfully logged out",
 	})
 }
+
+// RequestVerification godoc
+// @Summary Resend verification email
+// @Description Send a new email verification link if the account is not verified yet
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailRequest true "Email Request"
+// @Success 200 {object} models.ResponseAny "Verification email sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /v1/auth/verify/request [post]
+func (h *AuthHandler) RequestVerification(ctx *gin.Context) {
+	var req models.EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	// Jangan bocorkan apakah email terdaftar atau tidak
+	account, err := h.repo.Login(ctx.Request.Context(), req.Email)
+	if err == nil && account.VerifiedAt == nil {
+		if err := h.sendVerificationMail(ctx, account.ID, account.Email); err != nil {
+			utils.Logger(ctx).Warn("Failed to send verification email", "error", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "If the account exists and is not verified, a verification email has been sent",
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Confirm email address using the token from the verification email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.TokenRequest true "Token Request"
+// @Success 200 {object} models.ResponseAny "Email verified"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/verify [post]
+func (h *AuthHandler) VerifyEmail(ctx *gin.Context) {
+	var req models.TokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	token, err := h.consumeToken(ctx, models.TokenPurposeVerifyEmail, req.Token)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to verify token")
+		return
+	}
+
+	if err := h.repo.VerifyEmail(ctx.Request.Context(), token.AccountID); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to verify email", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Email verified successfully",
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Send a password reset link to the account email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailRequest true "Email Request"
+// @Success 200 {object} models.ResponseAny "Reset email sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Router /v1/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(ctx *gin.Context) {
+	var req models.EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	// Jangan bocorkan apakah email terdaftar atau tidak
+	if account, err := h.repo.Login(ctx.Request.Context(), req.Email); err == nil {
+		token, err := h.issueToken(ctx, account.ID, models.TokenPurposeResetPassword, nil, resetPasswordTTL)
+		if err != nil {
+			utils.Logger(ctx).Warn("Failed to create reset password token", "error", err)
+		} else {
+			h.sendMail(ctx, pkg.Mail{
+				To:      account.Email,
+				Subject: "Reset your password",
+				Body:    fmt.Sprintf("Use the link below to reset your password. The link expires in %s.\n\n%s/reset-password?token=%s", resetPasswordTTL, h.app.URL, token),
+			})
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "If the account exists, a password reset email has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using the token from the reset email. All sessions are revoked.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {object} models.ResponseAny "Password reset"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if valid := utils.ValidatePassword(req.Password); !valid {
+		utils.HandleFieldError(ctx, "password", "weak_password", utils.PasswordRequirement)
+		return
+	}
+
+	token, err := h.consumeToken(ctx, models.TokenPurposeResetPassword, req.Token)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to verify token")
+		return
+	}
+	accountID := token.AccountID
+
+	hashConfig := pkg.NewHashConfig()
+	hashConfig.UseRecommended()
+	hashedPassword, err := hashConfig.GenHash(req.Password)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed hashed password", err)
+		return
+	}
+
+	if err := h.repo.ResetPassword(ctx.Request.Context(), accountID, hashedPassword); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to reset password", err)
+		return
+	}
+
+	// Cabut semua access token yang masih aktif
+	if err := utils.RevokeSessions(ctx.Request.Context(), h.rdb, accountID); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to revoke sessions", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Password has been reset, please login again",
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change password with the current password. Other sessions are revoked and a new token is returned.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Change Password Request"
+// @Success 200 {object} models.ResponseLogin "Password changed"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Wrong current password"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/password [patch]
+func (h *AuthHandler) ChangePassword(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if valid := utils.ValidatePassword(req.NewPassword); !valid {
+		utils.HandleFieldError(ctx, "new_password", "weak_password", utils.PasswordRequirement)
+		return
+	}
+
+	if ok := h.checkPassword(ctx, uid, req.CurrentPassword); !ok {
+		return
+	}
+
+	hashConfig := pkg.NewHashConfig()
+	hashConfig.UseRecommended()
+	hashedPassword, err := hashConfig.GenHash(req.NewPassword)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed hashed password", err)
+		return
+	}
+
+	if err := h.repo.ChangePassword(ctx.Request.Context(), uid, hashedPassword); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to change password", err)
+		return
+	}
+
+	// Cabut semua sesi lalu terbitkan token baru untuk sesi ini
+	if err := utils.RevokeSessions(ctx.Request.Context(), h.rdb, uid); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to revoke sessions", err)
+		return
+	}
+
+	token, err := h.newAccessToken(ctx, uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed generate token", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseLogin{
+		Success: true,
+		Message: "Password changed successfully, other sessions have been logged out",
+		Token:   token,
+	})
+}
+
+// ChangeEmail godoc
+// @Summary Change email
+// @Description Request an email change. A confirmation link is sent to the new address.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeEmailRequest true "Change Email Request"
+// @Success 200 {object} models.ResponseAny "Confirmation email sent"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Wrong password"
+// @Failure 409 {object} models.ErrorResponse "Email already registered"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/email [patch]
+func (h *AuthHandler) ChangeEmail(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if valid := utils.ValidateEmail(req.Email); !valid {
+		utils.HandleFieldError(ctx, "email", "invalid_format", "email must be a valid email address")
+		return
+	}
+
+	if ok := h.checkPassword(ctx, uid, req.Password); !ok {
+		return
+	}
+
+	taken, err := h.repo.IsEmailTaken(ctx.Request.Context(), req.Email)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to check email", err)
+		return
+	}
+	if taken {
+		utils.HandleDomainError(ctx, repositories.ErrEmailTaken, "")
+		return
+	}
+
+	token, err := h.issueToken(ctx, uid, models.TokenPurposeChangeEmail, &req.Email, verifyEmailTTL)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to create token", err)
+		return
+	}
+	h.sendMail(ctx, pkg.Mail{
+		To:      req.Email,
+		Subject: "Confirm your new email",
+		Body:    fmt.Sprintf("Please confirm your new email address. The link expires in %s.\n\n%s/confirm-email?token=%s", verifyEmailTTL, h.app.URL, token),
+	})
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Confirmation email has been sent to the new address",
+	})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Confirm the new email address using the token from the confirmation email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.TokenRequest true "Token Request"
+// @Success 200 {object} models.ResponseAny "Email changed"
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired token"
+// @Failure 409 {object} models.ErrorResponse "Email already registered"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/email/confirm [post]
+func (h *AuthHandler) ConfirmEmailChange(ctx *gin.Context) {
+	var req models.TokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	token, err := h.consumeToken(ctx, models.TokenPurposeChangeEmail, req.Token)
+	if err == nil && token.Payload == nil {
+		err = repositories.ErrTokenInvalid.WithCause(errors.New("token has no email"))
+	}
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to verify token")
+		return
+	}
+
+	account, err := h.repo.GetAccountByID(ctx.Request.Context(), token.AccountID)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to get account")
+		return
+	}
+
+	// Email bisa saja sudah diambil akun lain selama menunggu konfirmasi
+	taken, err := h.repo.IsEmailTaken(ctx.Request.Context(), *token.Payload)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to check email", err)
+		return
+	}
+	if taken {
+		utils.HandleDomainError(ctx, repositories.ErrEmailTaken, "")
+		return
+	}
+
+	if err := h.repo.UpdateEmail(ctx.Request.Context(), token.AccountID, *token.Payload); err != nil {
+		utils.HandleDomainError(ctx, err, "failed to change email")
+		return
+	}
+
+	// Beri tahu alamat lama
+	h.sendMail(ctx, pkg.Mail{
+		To:      account.Email,
+		Subject: "Your email has been changed",
+		Body:    fmt.Sprintf("The email address of your account has been changed to %s. If this was not you, please contact support.", *token.Payload),
+	})
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Email changed successfully",
+	})
+}
+
+// checkPassword memverifikasi password akun, menulis response error jika gagal
+func (h *AuthHandler) checkPassword(ctx *gin.Context, uid int, password string) bool {
+	account, err := h.repo.GetAccountByID(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to get account")
+		return false
+	}
+
+	hashConfig := pkg.NewHashConfig()
+	match, err := hashConfig.ComparePasswordAndHash(password, account.Password)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed compare password", err)
+		return false
+	}
+	if !match {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid password", errors.New("invalid password"))
+		return false
+	}
+	return true
+}
+
+// rehashPassword menyimpan ulang password dengan parameter UseRecommended
+func (h *AuthHandler) rehashPassword(ctx *gin.Context, uid int, password string) {
+	hashConfig := pkg.NewHashConfig()
+	hashConfig.UseRecommended()
+	hashedPassword, err := hashConfig.GenHash(password)
+	if err != nil {
+		utils.Logger(ctx).Warn("Failed to rehash password", "error", err)
+		return
+	}
+	if err := h.repo.UpdatePassword(ctx.Request.Context(), uid, hashedPassword); err != nil {
+		utils.Logger(ctx).Warn("Failed to rehash password", "error", err)
+	}
+}
+
+// issueToken membuat token sekali pakai dan menyimpan signature-nya
+func (h *AuthHandler) issueToken(ctx *gin.Context, accountID int, purpose string, payload *string, ttl time.Duration) (string, error) {
+	token, signature, err := pkg.GenOneTimeToken()
+	if err != nil {
+		return "", err
+	}
+	if err := h.repo.CreateAccountToken(ctx.Request.Context(), accountID, purpose, signature, payload, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (h *AuthHandler) consumeToken(ctx *gin.Context, purpose, token string) (*models.AccountToken, error) {
+	signature, err := pkg.SignOneTimeToken(token)
+	if err != nil {
+		return nil, repositories.ErrTokenInvalid.WithCause(err)
+	}
+	return h.repo.ConsumeAccountToken(ctx.Request.Context(), purpose, signature)
+}
+
+func (h *AuthHandler) sendVerificationMail(ctx *gin.Context, accountID int, email string) error {
+	token, err := h.issueToken(ctx, accountID, models.TokenPurposeVerifyEmail, nil, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+	h.sendMail(ctx, pkg.Mail{
+		To:      email,
+		Subject: "Verify your email",
+		Body:    fmt.Sprintf("Welcome! Please verify your email address. The link expires in %s.\n\n%s/verify-email?token=%s", verifyEmailTTL, h.app.URL, token),
+	})
+	return nil
+}
+
+// sendMail mengirim email di background supaya response tidak menunggu SMTP.
+// Logger request diambil dulu supaya error tetap tercatat dengan request_id.
+func (h *AuthHandler) sendMail(rctx context.Context, mail pkg.Mail) {
+	logger := utils.Logger(rctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.mailer.Send(ctx, mail); err != nil {
+			logger.Warn("Failed to send email", "subject", mail.Subject, "error", err)
+		}
+	}()
+}
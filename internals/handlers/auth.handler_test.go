@@ -0,0 +1,282 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/routers"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     any
+		existing string
+		want     int
+		wantCode string
+		// field pertama di details, kosong jika tidak ada details
+		wantField string
+	}{
+		{name: "valid", body: models.AuthRequest{Email: "new@example.com", Password: "Password123!"}, want: http.StatusCreated},
+		{name: "missing password", body: map[string]string{"email": "new@example.com"}, want: http.StatusBadRequest, wantCode: "validation_failed", wantField: "password"},
+		{name: "invalid email", body: map[string]string{"email": "not-an-email", "password": "Password123!"}, want: http.StatusBadRequest, wantCode: "validation_failed", wantField: "email"},
+		{name: "malformed body", body: "not an object", want: http.StatusBadRequest, wantCode: "invalid_body"},
+		{name: "weak password", body: models.AuthRequest{Email: "new@example.com", Password: "short"}, want: http.StatusBadRequest, wantCode: "validation_failed", wantField: "password"},
+		{name: "duplicate email", body: models.AuthRequest{Email: "taken@example.com", Password: "Password123!"}, existing: "taken@example.com", want: http.StatusConflict, wantCode: "email_taken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			if tt.existing != "" {
+				env.createUser(t, tt.existing, "existing", "Existing User")
+			}
+
+			rec := env.DoJSON(http.MethodPost, "/auth/register", "", tt.body)
+			testutils.ExpectStatus(t, rec, tt.want)
+			if tt.wantCode == "" {
+				return
+			}
+
+			res := testutils.Decode[models.ErrorResponse](t, rec)
+			if res.ErrorCode != tt.wantCode {
+				t.Fatalf("error_code = %q, want %q", res.ErrorCode, tt.wantCode)
+			}
+			if tt.wantField != "" && (len(res.Details) == 0 || res.Details[0].Field != tt.wantField) {
+				t.Fatalf("details = %+v, want field %q", res.Details, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		want      int
+		wantToken bool
+	}{
+		{name: "valid credentials", email: "alice@example.com", password: "Password123!", want: http.StatusOK, wantToken: true},
+		{name: "wrong password", email: "alice@example.com", password: "Wrong123!", want: http.StatusUnauthorized},
+		{name: "unknown email", email: "nobody@example.com", password: "Password123!", want: http.StatusUnauthorized},
+		{name: "unverified email", email: "pending@example.com", password: "Password123!", want: http.StatusForbidden},
+	}
+
+	env := newTestEnv(t)
+	env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/register", "", models.AuthRequest{Email: "pending@example.com", Password: "Password123!"}), http.StatusCreated)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoJSON(http.MethodPost, "/auth", "", models.AuthRequest{Email: tt.email, Password: tt.password})
+			testutils.ExpectStatus(t, rec, tt.want)
+
+			if res := testutils.Decode[models.ResponseLogin](t, rec); (res.Token != "") != tt.wantToken {
+				t.Fatalf("token present = %v, want %v", res.Token != "", tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestAuthRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		// status request ke-11, masing-masing dengan X-Forwarded-For berbeda
+		want int
+	}{
+		{name: "no trusted proxy", want: http.StatusTooManyRequests},
+		// httptest memakai RemoteAddr 192.0.2.1
+		{name: "trusted proxy", proxies: []string{"192.0.2.0/24"}, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			cfg := *testConfig
+			cfg.App.TrustedProxies = tt.proxies
+			router := routers.NewRouter(&cfg, env.stores, env.rdb, env.mailer, nil)
+
+			body, _ := json.Marshal(models.AuthRequest{Email: "nobody@example.com", Password: "Password123!"})
+			var rec *httptest.ResponseRecorder
+			for i := range 11 {
+				req := httptest.NewRequest(http.MethodPost, "/auth", bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+			}
+			testutils.ExpectStatus(t, rec, tt.want)
+		})
+	}
+}
+
+func TestVerifyEmailThenLogin(t *testing.T) {
+	env := newTestEnv(t)
+	creds := models.AuthRequest{Email: "bob@example.com", Password: "Password123!"}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/register", "", creds), http.StatusCreated)
+	token := env.mailer.NextToken(t)
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth", "", creds), http.StatusForbidden)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/verify", "", models.TokenRequest{Token: token}), http.StatusOK)
+	// token sekali pakai
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/verify", "", models.TokenRequest{Token: token}), http.StatusBadRequest)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth", "", creds), http.StatusOK)
+}
+
+func TestResetPassword(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/password/forgot", "", models.EmailRequest{Email: "alice@example.com"}), http.StatusOK)
+	token := env.mailer.NextToken(t)
+
+	tests := []struct {
+		name string
+		body models.ResetPasswordRequest
+		want int
+	}{
+		{name: "invalid token", body: models.ResetPasswordRequest{Token: "bogus", Password: "NewPassword123!"}, want: http.StatusBadRequest},
+		{name: "weak password", body: models.ResetPasswordRequest{Token: token, Password: "short"}, want: http.StatusBadRequest},
+		{name: "valid", body: models.ResetPasswordRequest{Token: token, Password: "NewPassword123!"}, want: http.StatusOK},
+		{name: "token reused", body: models.ResetPasswordRequest{Token: token, Password: "Another123!"}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/password/reset", "", tt.body), tt.want)
+		})
+	}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth", "", models.AuthRequest{Email: "alice@example.com", Password: "Password123!"}), http.StatusUnauthorized)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth", "", models.AuthRequest{Email: "alice@example.com", Password: "NewPassword123!"}), http.StatusOK)
+}
+
+func TestLogoutBlacklistsToken(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/user", want: http.StatusUnauthorized},
+		{name: "token accepted", method: http.MethodGet, path: "/user", token: alice.Token, want: http.StatusOK},
+		{name: "logout", method: http.MethodDelete, path: "/auth/", token: alice.Token, want: http.StatusOK},
+		{name: "token revoked", method: http.MethodGet, path: "/user", token: alice.Token, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoJSON(tt.method, tt.path, tt.token, nil)
+			testutils.ExpectStatus(t, rec, tt.want)
+			// status pada body selalu teks standar dari status code
+			if tt.want >= http.StatusBadRequest {
+				if status := testutils.Decode[models.ErrorResponse](t, rec).Status; status != http.StatusText(tt.want) {
+					t.Fatalf("status = %q, want %q", status, http.StatusText(tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestChangePasswordRevokesOlderSessions(t *testing.T) {
+	env := newTestEnv(t)
+	// token lama dan ganti password terjadi di detik yang sama
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	rec := env.DoJSON(http.MethodPatch, "/auth/password", alice.Token, models.ChangePasswordRequest{CurrentPassword: "Password123!", NewPassword: "NewPassword123!"})
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	fresh := testutils.Decode[models.ResponseLogin](t, rec).Token
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "token issued before the change", token: alice.Token, want: http.StatusUnauthorized},
+		{name: "token issued by the change", token: fresh, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/user", tt.token, nil), tt.want)
+		})
+	}
+}
+
+func TestChangePasswordKeepsPendingEmailChange(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/password/forgot", "", models.EmailRequest{Email: "alice@example.com"}), http.StatusOK)
+	resetToken := env.mailer.NextToken(t)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPatch, "/auth/email", alice.Token, models.ChangeEmailRequest{Email: "alice.new@example.com", Password: "Password123!"}), http.StatusOK)
+	emailToken := env.mailer.NextToken(t)
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPatch, "/auth/password", alice.Token, models.ChangePasswordRequest{CurrentPassword: "Password123!", NewPassword: "NewPassword123!"}), http.StatusOK)
+
+	tests := []struct {
+		name string
+		path string
+		body any
+		want int
+	}{
+		// link reset lama tidak boleh menimpa password yang baru diganti
+		{name: "reset token revoked", path: "/auth/password/reset", body: models.ResetPasswordRequest{Token: resetToken, Password: "Another123!"}, want: http.StatusBadRequest},
+		{name: "email change still pending", path: "/auth/email/confirm", body: models.TokenRequest{Token: emailToken}, want: http.StatusOK},
+		{name: "login with new email and password", path: "/auth", body: models.AuthRequest{Email: "alice.new@example.com", Password: "NewPassword123!"}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, tt.path, "", tt.body), tt.want)
+		})
+	}
+}
+
+func TestSessionVersion(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	// dua kali ganti password berturut-turut, semuanya di detik yang sama
+	passwords := []string{"Password123!", "NewPassword123!", "OtherPassword123!"}
+	tokens := []string{alice.Token}
+	for i := 1; i < len(passwords); i++ {
+		rec := env.DoJSON(http.MethodPatch, "/auth/password", tokens[i-1], models.ChangePasswordRequest{CurrentPassword: passwords[i-1], NewPassword: passwords[i]})
+		testutils.ExpectStatus(t, rec, http.StatusOK)
+		tokens = append(tokens, testutils.Decode[models.ResponseLogin](t, rec).Token)
+	}
+
+	// versi sesi yang tidak cocok dengan Redis, termasuk setelah key hilang
+	stale := pkg.NewJWTClaims(alice.ID)
+	stale.SessionVersion = 7
+	staleToken, err := stale.GenToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "before first revocation", token: tokens[0], want: http.StatusUnauthorized},
+		{name: "previous version", token: tokens[1], want: http.StatusUnauthorized},
+		{name: "current version", token: tokens[2], want: http.StatusOK},
+		{name: "unknown version", token: staleToken, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/user", tt.token, nil), tt.want)
+		})
+	}
+}
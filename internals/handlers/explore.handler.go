@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+const (
+	// maksimal post dari satu author dalam satu sesi explore
+	exploreMaxPerAuthor = 2
+	// jumlah kandidat teratas dari Redis yang masuk snapshot satu sesi
+	exploreMaxCandidates = 500
+)
+
+// GetExplorePosts godoc
+// @Summary Explore trending posts
+// @Description Trending posts from accounts I don't follow, ranked by time-decayed likes and comments, with at most 2 posts per author.
+// @Description The ranking is snapshotted on the first page, so later pages never repeat or skip posts. Cursors expire after 30 minutes.
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid or expired cursor"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/post/explore [get]
+func (h *PostHandler) GetExplorePosts(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	session, offset, err := utils.DecodeExploreCursor(ctx.Query("cursor"))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid cursor", err)
+		return
+	}
+	limit := utils.ParseLimit(ctx.Query("limit"))
+	rctx := ctx.Request.Context()
+
+	var items []models.PostFeed
+	hasMore := false
+	if session == "" {
+		var ranked []models.PostFeed
+		session, ranked, err = h.newExploreSession(rctx, uid)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get explore posts", err)
+			return
+		}
+		hasMore = len(ranked) > limit
+		items = ranked[:min(limit, len(ranked))]
+	} else {
+		// satu id lebih untuk mengetahui apakah masih ada halaman berikutnya
+		ids, ok, err := utils.GetExploreSession(rctx, h.rdb, session, offset, limit+1)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get explore posts", err)
+			return
+		}
+		if !ok {
+			utils.HandleError(ctx, http.StatusBadRequest, "cursor expired, reload explore from the first page", errors.New("explore session expired"))
+			return
+		}
+		hasMore = len(ids) > limit
+		ids = ids[:min(limit, len(ids))]
+
+		posts, err := h.repo.GetExplorePosts(rctx, uid, ids)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get explore posts", err)
+			return
+		}
+		// urutan mengikuti snapshot, post yang dihapus atau diblokir sejak
+		// snapshot dibuat dilewati
+		byID := make(map[int]models.PostFeed, len(posts))
+		for _, p := range posts {
+			byID[p.ID] = p
+		}
+		items = make([]models.PostFeed, 0, len(ids))
+		for _, id := range ids {
+			if post, ok := byID[id]; ok {
+				items = append(items, post)
+			}
+		}
+	}
+
+	h.applyViewerState(rctx, uid, items)
+	page := models.Page[models.PostFeed]{Items: items}
+	if hasMore {
+		page.NextCursor = utils.EncodeExploreCursor(session, offset+limit)
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.PostFeed]]{
+		Success: true,
+		Message: "Success Get Explore Posts",
+		Data:    page,
+	})
+}
+
+// newExploreSession membuat snapshot ranking explore untuk viewer: kandidat
+// teratas yang lolos filter viewer, dengan batas exploreMaxPerAuthor post per
+// author untuk seluruh sesi
+func (h *PostHandler) newExploreSession(ctx context.Context, uid int) (string, []models.PostFeed, error) {
+	ids, err := utils.GetExploreIDs(ctx, h.rdb, 0, exploreMaxCandidates)
+	if err != nil {
+		return "", nil, err
+	}
+	posts, err := h.repo.GetExplorePosts(ctx, uid, ids)
+	if err != nil {
+		return "", nil, err
+	}
+	byID := make(map[int]models.PostFeed, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	ranked := []models.PostFeed{}
+	rankedIDs := []int{}
+	perAuthor := make(map[int]int)
+	for _, id := range ids {
+		post, ok := byID[id]
+		if !ok || perAuthor[post.AuthorID] >= exploreMaxPerAuthor {
+			continue
+		}
+		perAuthor[post.AuthorID]++
+		ranked = append(ranked, post)
+		rankedIDs = append(rankedIDs, id)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	session := base64.RawURLEncoding.EncodeToString(b)
+	if err := utils.SaveExploreSession(ctx, h.rdb, session, rankedIDs); err != nil {
+		return "", nil, err
+	}
+	return session, ranked, nil
+}
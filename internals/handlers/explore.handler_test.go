@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+// post dengan skor explore tertentu
+func (e *testEnv) createExplorePost(t *testing.T, author testUser, score float64) int {
+	t.Helper()
+	id := e.createPost(t, author, "trending")
+	ctx := context.Background()
+	// epoch dipasang ExploreJob saat seed, di sini explore dimulai kosong
+	if _, seeded, err := utils.ExploreEpoch(ctx, e.rdb); err != nil {
+		t.Fatal(err)
+	} else if !seeded {
+		if err := utils.SeedExplore(ctx, e.rdb, time.Now(), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := utils.TrackExplorePost(ctx, e.rdb, id, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RecordEngagement(ctx, e.rdb, id, score, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func (e *testEnv) explorePage(t *testing.T, token, cursor string) models.Page[models.PostFeed] {
+	t.Helper()
+	rec := e.DoJSON(http.MethodGet, "/post/explore?limit=2&cursor="+url.QueryEscape(cursor), token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	return testutils.Decode[models.Response[models.Page[models.PostFeed]]](t, rec).Data
+}
+
+func postIDs(posts []models.PostFeed) []int {
+	ids := make([]int, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestExplorePagination(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+	dave := env.createUser(t, "dave@example.com", "dave", "Dave Miller")
+
+	b1 := env.createExplorePost(t, bob, 7)
+	b2 := env.createExplorePost(t, bob, 6)
+	env.createExplorePost(t, alice, 5)
+	c1 := env.createExplorePost(t, charlie, 4)
+	env.createExplorePost(t, bob, 3)
+	d1 := env.createExplorePost(t, dave, 2)
+	c2 := env.createExplorePost(t, charlie, 1)
+
+	first := env.explorePage(t, alice.Token, "")
+	if got := postIDs(first.Items); len(got) != 2 || got[0] != b1 || got[1] != b2 {
+		t.Fatalf("first page = %v, want [%d %d]", got, b1, b2)
+	}
+
+	// skor berubah di antara halaman tidak menggeser urutan sesi
+	if err := utils.RecordEngagement(context.Background(), env.rdb, c2, 100, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// post ketiga bob tidak muncul karena batas per author berlaku untuk
+	// seluruh sesi, bukan per halaman
+	second := env.explorePage(t, alice.Token, first.NextCursor)
+	if got := postIDs(second.Items); len(got) != 2 || got[0] != c1 || got[1] != d1 {
+		t.Fatalf("second page = %v, want [%d %d]", got, c1, d1)
+	}
+	third := env.explorePage(t, alice.Token, second.NextCursor)
+	if got := postIDs(third.Items); len(got) != 1 || got[0] != c2 || third.NextCursor != "" {
+		t.Fatalf("third page = %+v, want [%d] and no cursor", third, c2)
+	}
+
+	// retry cursor yang sama menghasilkan halaman yang sama
+	if again := env.explorePage(t, alice.Token, second.NextCursor); len(again.Items) != 1 || again.Items[0].ID != c2 {
+		t.Fatalf("retried page = %v, want [%d]", postIDs(again.Items), c2)
+	}
+
+	// sesi baru memakai skor terbaru
+	if fresh := env.explorePage(t, alice.Token, ""); fresh.Items[0].ID != c2 {
+		t.Fatalf("new session first page = %v, want %d first", postIDs(fresh.Items), c2)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   int
+	}{
+		{name: "invalid cursor", cursor: "not-a-cursor", want: http.StatusBadRequest},
+		{name: "unknown session", cursor: utils.EncodeExploreCursor("unknown", 2), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/post/explore?cursor="+url.QueryEscape(tt.cursor), alice.Token, nil), tt.want)
+		})
+	}
+
+	t.Run("expired session", func(t *testing.T) {
+		env.redis.FastForward(utils.ExploreSessionTTL + time.Second)
+		testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/post/explore?limit=2&cursor="+url.QueryEscape(first.NextCursor), alice.Token, nil), http.StatusBadRequest)
+	})
+}
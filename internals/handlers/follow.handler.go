@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+type followLister func(ctx context.Context, ownerID, viewerID int, q models.FollowQuery) ([]models.Follow, error)
+
+// GetUserFollowers godoc
+// @Summary Get a user's followers
+// @Description Get followers of another user, subject to their follow_list_visibility setting
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "List is private"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/{id}/followers [get]
+func (h *UserHandler) GetUserFollowers(ctx *gin.Context) {
+	uid, targetID, ok := h.followListTarget(ctx)
+	if !ok {
+		return
+	}
+
+	h.respondFollowList(ctx, h.repo.GetFollowers, targetID, uid, "Success Get Followers")
+}
+
+// GetUserFollowing godoc
+// @Summary Get who a user follows
+// @Description Get accounts another user follows, subject to their follow_list_visibility setting
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "List is private"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/{id}/following [get]
+func (h *UserHandler) GetUserFollowing(ctx *gin.Context) {
+	uid, targetID, ok := h.followListTarget(ctx)
+	if !ok {
+		return
+	}
+
+	h.respondFollowList(ctx, h.repo.GetFollowing, targetID, uid, "Success Get Followings")
+}
+
+// GetMutualFollowers godoc
+// @Summary Followed by people you know
+// @Description Get people I follow who also follow this user, with the total count
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "List is private"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/{id}/mutual [get]
+func (h *UserHandler) GetMutualFollowers(ctx *gin.Context) {
+	uid, targetID, ok := h.followListTarget(ctx)
+	if !ok {
+		return
+	}
+
+	q, ok := parseFollowQuery(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.followPage(ctx.Request.Context(), h.repo.GetMutualFollowers, targetID, uid, q)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get mutual followers", err)
+		return
+	}
+
+	total, err := h.repo.CountMutualFollowers(ctx.Request.Context(), targetID, uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get mutual followers", err)
+		return
+	}
+	page.Total = &total
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.Follow]]{
+		Success: true,
+		Message: "Success Get Mutual Followers",
+		Data:    *page,
+	})
+}
+
+// followListTarget membaca user dari :id dan memastikan viewer boleh melihat list follow-nya
+func (h *UserHandler) followListTarget(ctx *gin.Context) (uid int, targetID int, ok bool) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return 0, 0, false
+	}
+
+	targetID, ok = h.targetUserID(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+	if targetID == uid {
+		return uid, targetID, true
+	}
+
+	visibility, viewerFollows, found, err := h.repo.GetFollowListAccess(ctx.Request.Context(), targetID, uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get user", err)
+		return 0, 0, false
+	}
+	if !found {
+		utils.HandleDomainError(ctx, repositories.ErrUserNotFound, "")
+		return 0, 0, false
+	}
+
+	switch visibility {
+	case utils.VisibilityPublic:
+		return uid, targetID, true
+	case utils.VisibilityFollowers:
+		if viewerFollows {
+			return uid, targetID, true
+		}
+		utils.HandleDomainError(ctx, repositories.ErrFollowListFollowersOnly, "")
+	default:
+		utils.HandleDomainError(ctx, repositories.ErrFollowListPrivate, "")
+	}
+	return 0, 0, false
+}
+
+// respondFollowList menulis satu halaman list follow sebagai response
+func (h *UserHandler) respondFollowList(ctx *gin.Context, list followLister, ownerID, viewerID int, message string) {
+	q, ok := parseFollowQuery(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.followPage(ctx.Request.Context(), list, ownerID, viewerID, q)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get follow list", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.Follow]]{
+		Success: true,
+		Message: message,
+		Data:    *page,
+	})
+}
+
+// followPage mengambil limit+1 baris untuk tahu apakah masih ada halaman berikutnya
+func (h *UserHandler) followPage(ctx context.Context, list followLister, ownerID, viewerID int, q models.FollowQuery) (*models.Page[models.Follow], error) {
+	limit := q.Limit
+	q.Limit = limit + 1
+
+	items, err := list(ctx, ownerID, viewerID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[models.Follow]{Items: items}
+	if len(items) > limit {
+		last := items[limit-1]
+		page.Items = items[:limit]
+		page.NextCursor = utils.EncodeCursor(last.FollowedAt, last.ID)
+	}
+	return page, nil
+}
+
+func parseFollowQuery(ctx *gin.Context) (models.FollowQuery, bool) {
+	cursorTime, cursorID, err := utils.DecodeCursor(ctx.Query("cursor"))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid cursor", err)
+		return models.FollowQuery{}, false
+	}
+
+	return models.FollowQuery{
+		Search:     utils.SearchPattern(ctx.Query("q")),
+		Limit:      utils.ParseLimit(ctx.Query("limit")),
+		CursorTime: cursorTime,
+		CursorID:   cursorID,
+	}, true
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// batas waktu tiap pengecekan dependency di /readyz
+const readinessCheckTimeout = 2 * time.Second
+
+// draining di-set saat sinyal shutdown diterima, /readyz langsung menjawab 503
+// supaya load balancer berhenti mengirim request selama server menyelesaikan
+// request yang sedang berjalan
+var draining atomic.Bool
+
+// SetDraining menandai proses sedang shutdown (atau batal, untuk test)
+func SetDraining(v bool) {
+	draining.Store(v)
+}
+
+type HealthHandler struct {
+	repo             repositories.HealthStore
+	rdb              *redis.Client
+	migrationVersion int64
+}
+
+// migrationVersion adalah versi schema minimal yang dibutuhkan binary ini
+func NewHealthHandler(repo repositories.HealthStore, rdb *redis.Client, migrationVersion int64) *HealthHandler {
+	return &HealthHandler{repo: repo, rdb: rdb, migrationVersion: migrationVersion}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Returns 200 as long as the process is able to serve HTTP
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.ResponseAny
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.Response[models.HealthStatus]{
+		Success: true,
+		Message: "OK",
+		Data:    models.HealthStatus{Status: "ok"},
+	})
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Checks Postgres, Redis and that the database schema is migrated to the version this build expects.
+// @Description Returns 503 as soon as the server starts shutting down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.ResponseAny
+// @Failure 503 {object} models.ResponseAny "A dependency is not ready"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(ctx *gin.Context) {
+	if draining.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, models.Response[models.HealthStatus]{
+			Success: false,
+			Message: "Shutting Down",
+			Data:    models.HealthStatus{Status: "draining"},
+		})
+		return
+	}
+
+	checks := map[string]func(context.Context) error{
+		"postgres":   h.repo.Ping,
+		"redis":      func(c context.Context) error { return h.rdb.Ping(c).Err() },
+		"migrations": h.checkMigrations,
+	}
+
+	status := models.HealthStatus{Status: "ok", Checks: make(map[string]string, len(checks))}
+	for name, check := range checks {
+		c, cancel := context.WithTimeout(ctx.Request.Context(), readinessCheckTimeout)
+		err := check(c)
+		cancel()
+
+		// detail error hanya di log, probe tidak butuh token
+		if err != nil {
+			utils.Logger(ctx).Warn("Readiness check failed", "check", name, "error", err.Error())
+			status.Status = "unavailable"
+			status.Checks[name] = "unavailable"
+			continue
+		}
+		status.Checks[name] = "ok"
+	}
+
+	if status.Status != "ok" {
+		ctx.JSON(http.StatusServiceUnavailable, models.Response[models.HealthStatus]{
+			Success: false,
+			Message: "Not Ready",
+			Data:    status,
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, models.Response[models.HealthStatus]{
+		Success: true,
+		Message: "Ready",
+		Data:    status,
+	})
+}
+
+// schema boleh lebih baru (rolling deploy setelah migrate up), tapi tidak boleh
+// lebih lama atau dirty
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	version, dirty, err := h.repo.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < h.migrationVersion {
+		return fmt.Errorf("schema version %d, want at least %d", version, h.migrationVersion)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/ntisrangga142/chat/internals/handlers"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+)
+
+func TestLiveness(t *testing.T) {
+	env := newTestEnv(t)
+	// probe tidak butuh token dan tetap hidup walau dependency mati
+	env.redis.Close()
+
+	rec := env.DoJSON(http.MethodGet, "/healthz", "", nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	if status := testutils.Decode[models.Response[models.HealthStatus]](t, rec).Data.Status; status != "ok" {
+		t.Fatalf("status = %q, want ok", status)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(env *testEnv)
+		want       int
+		failChecks []string
+	}{
+		{name: "all dependencies up", setup: func(env *testEnv) {}, want: http.StatusOK},
+		{name: "postgres down", setup: func(env *testEnv) { env.db.PingErr = errors.New("connection refused") }, want: http.StatusServiceUnavailable, failChecks: []string{"postgres", "migrations"}},
+		{name: "redis down", setup: func(env *testEnv) { env.redis.Close() }, want: http.StatusServiceUnavailable, failChecks: []string{"redis"}},
+		{name: "schema behind", setup: func(env *testEnv) { env.db.MigrationVersion-- }, want: http.StatusServiceUnavailable, failChecks: []string{"migrations"}},
+		{name: "schema dirty", setup: func(env *testEnv) { env.db.MigrationDirty = true }, want: http.StatusServiceUnavailable, failChecks: []string{"migrations"}},
+		{name: "schema ahead", setup: func(env *testEnv) { env.db.MigrationVersion++ }, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tt.setup(env)
+
+			rec := env.DoJSON(http.MethodGet, "/readyz", "", nil)
+			testutils.ExpectStatus(t, rec, tt.want)
+
+			checks := testutils.Decode[models.Response[models.HealthStatus]](t, rec).Data.Checks
+			for name, result := range checks {
+				if failed := result != "ok"; failed != slices.Contains(tt.failChecks, name) {
+					t.Fatalf("checks = %v, want only %v failing", checks, tt.failChecks)
+				}
+				// detail error tidak dibocorkan ke client
+				if result != "ok" && result != "unavailable" {
+					t.Fatalf("check %s = %q, want unavailable", name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessDraining(t *testing.T) {
+	env := newTestEnv(t)
+	handlers.SetDraining(true)
+	t.Cleanup(func() { handlers.SetDraining(false) })
+
+	// dependency sehat, tapi server sedang shutdown
+	rec := env.DoJSON(http.MethodGet, "/readyz", "", nil)
+	testutils.ExpectStatus(t, rec, http.StatusServiceUnavailable)
+	if status := testutils.Decode[models.Response[models.HealthStatus]](t, rec).Data.Status; status != "draining" {
+		t.Fatalf("status = %q, want draining", status)
+	}
+
+	// liveness tetap 200 supaya proses tidak di-restart saat drain
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/healthz", "", nil), http.StatusOK)
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/repositories/memory"
+	"github.com/ntisrangga142/chat/internals/routers"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+var testConfig = testutils.NewConfig("chat-test")
+
+func TestMain(m *testing.M) {
+	testutils.Main(m, testConfig)
+}
+
+type testEnv struct {
+	*testutils.Client
+	db     *memory.DB
+	stores repositories.Stores
+	mailer *testutils.CaptureMailer
+	redis  *miniredis.Miniredis
+	rdb    *redis.Client
+}
+
+// router lengkap dengan repository in-memory dan miniredis
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newTestEnvWith(t, nil, nil)
+}
+
+// newTestEnvWith memasang provider OIDC, dan wrap bisa mengganti store
+// (misal untuk mensimulasikan error database) sebelum router dibuat
+func newTestEnvWith(t *testing.T, providers map[string]*pkg.OIDCProvider, wrap func(*repositories.Stores)) *testEnv {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	db := memory.NewDB()
+	stores := memory.NewStores(db)
+	if wrap != nil {
+		wrap(&stores)
+	}
+	mailer := testutils.NewCaptureMailer()
+
+	return &testEnv{
+		Client: &testutils.Client{Router: routers.NewRouter(testConfig, stores, rdb, mailer, providers)},
+		db:     db,
+		stores: stores,
+		mailer: mailer,
+		redis:  mr,
+		rdb:    rdb,
+	}
+}
+
+type testUser struct {
+	ID    int
+	Token string
+}
+
+// buat akun terverifikasi dengan username & fullname, lengkap dengan access token
+func (e *testEnv) createUser(t *testing.T, email, username, fullname string) testUser {
+	t.Helper()
+	ctx := context.Background()
+
+	hash := pkg.NewHashConfig()
+	hash.UseRecommended()
+	password, err := hash.GenHash("Password123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := e.stores.Auth.Register(ctx, email, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.stores.Auth.VerifyEmail(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.stores.User.ChangeUsername(ctx, id, username); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.stores.User.UpdateProfile(ctx, id, map[string]any{"fullname": fullname}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := pkg.NewJWTClaims(id).GenToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testUser{ID: id, Token: token}
+}
+
+func (e *testEnv) createPost(t *testing.T, author testUser, caption string) int {
+	t.Helper()
+	post, err := e.stores.Post.CreatePost(context.Background(), models.CreatePostRequest{Caption: caption}, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post.ID
+}
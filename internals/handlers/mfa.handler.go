@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// jumlah recovery code yang dibuat saat 2FA diaktifkan
+const recoveryCodeCount = 10
+
+// completeLogin menerbitkan access token, atau token challenge jika 2FA aktif
+func (h *AuthHandler) completeLogin(ctx *gin.Context, userID int) {
+	mfa, err := h.repo.GetMFA(ctx.Request.Context(), userID)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get 2fa status", err)
+		return
+	}
+
+	if mfa != nil && mfa.EnabledAt != nil {
+		claims := pkg.NewMFAClaims(userID)
+		mfaToken, err := claims.GenToken()
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed generate token", err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, models.ResponseLogin{
+			Success:     true,
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
+		return
+	}
+
+	h.issueAccessToken(ctx, userID)
+}
+
+// issueAccessToken menerbitkan access token setelah semua faktor login lolos
+func (h *AuthHandler) issueAccessToken(ctx *gin.Context, userID int) {
+	// Login mengaktifkan kembali akun yang dinonaktifkan atau
+	// membatalkan penghapusan yang masih dalam masa tenggang
+	if err := h.repo.RestoreAccount(ctx.Request.Context(), userID); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to restore account", err)
+		return
+	}
+
+	token, err := h.newAccessToken(ctx, userID)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed generate token", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseLogin{
+		Success: true,
+		Message: "Login successful",
+		Token:   token,
+	})
+}
+
+// newAccessToken membuat access token dengan versi sesi user saat ini
+func (h *AuthHandler) newAccessToken(ctx *gin.Context, userID int) (string, error) {
+	version, err := utils.SessionVersion(ctx.Request.Context(), h.rdb, userID)
+	if err != nil {
+		return "", err
+	}
+	claims := pkg.NewJWTClaims(userID)
+	claims.SessionVersion = version
+	return claims.GenToken()
+}
+
+// EnrollMFA godoc
+// @Summary Start 2FA enrollment
+// @Description Generate a new TOTP secret and otpauth URI. 2FA is active after confirming a code.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ResponseAny "TOTP secret"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "2FA already enabled"
+// @Failure 429 {object} models.ErrorResponse "Too many requests"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	mfa, err := h.repo.GetMFA(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get 2fa status", err)
+		return
+	}
+	if mfa != nil && mfa.EnabledAt != nil {
+		utils.HandleError(ctx, http.StatusConflict, "two-factor authentication is already enabled", errors.New("mfa already enabled"))
+		return
+	}
+
+	account, err := h.repo.GetAccountByID(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to get account")
+		return
+	}
+
+	secret, err := pkg.GenTOTPSecret()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to generate secret", err)
+		return
+	}
+	if err := h.repo.SaveMFASecret(ctx.Request.Context(), uid, secret); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to save secret", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.MFAEnrollment]{
+		Success: true,
+		Message: "Scan the URI with your authenticator app, then confirm with a code",
+		Data: models.MFAEnrollment{
+			Secret:     secret,
+			OTPAuthURI: h.totp.URI(h.app.MFAIssuer, account.Email, secret),
+		},
+	})
+}
+
+// EnableMFA godoc
+// @Summary Confirm 2FA enrollment
+// @Description Confirm the TOTP secret with a code and receive single-use recovery codes
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP Code"
+// @Success 200 {object} models.ResponseAny "Recovery codes"
+// @Failure 400 {object} models.ErrorResponse "Invalid code"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/mfa/enable [post]
+func (h *AuthHandler) EnableMFA(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	mfa, err := h.repo.GetMFA(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get 2fa status", err)
+		return
+	}
+	if mfa == nil || mfa.EnabledAt != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "no pending 2fa enrollment", errors.New("no pending enrollment"))
+		return
+	}
+
+	step, ok, err := h.totp.Validate(mfa.Secret, req.Code, mfa.LastUsedStep)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to validate code", err)
+		return
+	}
+	if !ok {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid code", errors.New("invalid totp code"))
+		return
+	}
+
+	codes, err := pkg.GenRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to generate recovery codes", err)
+		return
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := pkg.SignOneTimeToken(code)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to hash recovery codes", err)
+			return
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := h.repo.EnableMFA(ctx.Request.Context(), uid, step, hashes); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to enable 2fa", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.RecoveryCodes]{
+		Success: true,
+		Message: "Two-factor authentication enabled, store the recovery codes somewhere safe",
+		Data:    models.RecoveryCodes{Codes: codes},
+	})
+}
+
+// VerifyMFA godoc
+// @Summary Complete 2FA login
+// @Description Exchange the mfa_token from login and a TOTP or recovery code for an access token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFAVerifyRequest true "MFA Verify Request"
+// @Success 200 {object} models.ResponseLogin "Login successful"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Invalid token or code"
+// @Failure 429 {object} models.ErrorResponse "Too many failed attempts"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(ctx *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	var claims pkg.Claims
+	if err := claims.VerifyMFAToken(req.MFAToken); err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid or expired mfa token", fmt.Errorf("invalid mfa token: %v", err))
+		return
+	}
+
+	locked, err := utils.IsMFALocked(ctx.Request.Context(), h.rdb, claims.UserId)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to check 2fa attempts", err)
+		return
+	}
+	if locked {
+		utils.HandleError(ctx, http.StatusTooManyRequests, "too many failed attempts, try again later", errors.New("mfa locked"))
+		return
+	}
+
+	// Token challenge dipakai sebelum kode dicek, kode salah berarti login ulang
+	fresh, err := utils.ConsumeToken(ctx.Request.Context(), h.rdb, req.MFAToken, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to revoke mfa token", err)
+		return
+	}
+	if !fresh {
+		utils.HandleError(ctx, http.StatusUnauthorized, "mfa token already used", errors.New("mfa token already used"))
+		return
+	}
+
+	mfa, err := h.repo.GetMFA(ctx.Request.Context(), claims.UserId)
+	if err != nil || mfa == nil || mfa.EnabledAt == nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "two-factor authentication is not enabled", fmt.Errorf("mfa not enabled: %v", err))
+		return
+	}
+
+	ok, err := h.checkMFACode(ctx, mfa, req.Code)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to validate code", err)
+		return
+	}
+	if !ok {
+		if err := utils.RecordMFAFailure(ctx.Request.Context(), h.rdb, claims.UserId); err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to record 2fa attempt", err)
+			return
+		}
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid code", errors.New("invalid mfa code"))
+		return
+	}
+
+	if err := utils.ResetMFAFailures(ctx.Request.Context(), h.rdb, claims.UserId); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to reset 2fa attempts", err)
+		return
+	}
+
+	h.issueAccessToken(ctx, claims.UserId)
+}
+
+// DisableMFA godoc
+// @Summary Disable 2FA
+// @Description Disable two-factor authentication. Requires the password and a TOTP or recovery code.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.MFADisableRequest true "MFA Disable Request"
+// @Success 200 {object} models.ResponseAny "2FA disabled"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Wrong password or code"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/mfa [delete]
+func (h *AuthHandler) DisableMFA(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	var req models.MFADisableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if ok := h.checkPassword(ctx, uid, req.Password); !ok {
+		return
+	}
+
+	mfa, err := h.repo.GetMFA(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get 2fa status", err)
+		return
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "two-factor authentication is not enabled", errors.New("mfa not enabled"))
+		return
+	}
+
+	ok, err := h.checkMFACode(ctx, mfa, req.Code)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to validate code", err)
+		return
+	}
+	if !ok {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid code", errors.New("invalid mfa code"))
+		return
+	}
+
+	if err := h.repo.DisableMFA(ctx.Request.Context(), uid); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to disable 2fa", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// checkMFACode menerima kode TOTP atau recovery code
+func (h *AuthHandler) checkMFACode(ctx *gin.Context, mfa *models.MFA, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if strings.Contains(code, "-") {
+		hash, err := pkg.SignOneTimeToken(strings.ToLower(code))
+		if err != nil {
+			return false, err
+		}
+		return h.repo.UseRecoveryCode(ctx.Request.Context(), mfa.AccountID, hash)
+	}
+
+	step, ok, err := h.totp.Validate(mfa.Secret, code, mfa.LastUsedStep)
+	if err != nil || !ok {
+		return false, err
+	}
+	return h.repo.UseMFAStep(ctx.Request.Context(), mfa.AccountID, step)
+}
@@ -0,0 +1,177 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// aktifkan 2FA untuk user, kembalikan secret, kode yang dipakai saat
+// aktivasi dan recovery codes
+func (e *testEnv) enableMFA(t *testing.T, user testUser) (string, string, []string) {
+	t.Helper()
+	rec := e.DoJSON(http.MethodPost, "/auth/mfa/enroll", user.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	secret := testutils.Decode[models.Response[models.MFAEnrollment]](t, rec).Data.Secret
+
+	code := totpCode(t, secret, 0)
+	rec = e.DoJSON(http.MethodPost, "/auth/mfa/enable", user.Token, models.MFACodeRequest{Code: code})
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	return secret, code, testutils.Decode[models.Response[models.RecoveryCodes]](t, rec).Data.Codes
+}
+
+// kode TOTP untuk step sekarang + offset
+func totpCode(t *testing.T, secret string, offset int) string {
+	t.Helper()
+	totp := pkg.NewTOTP()
+	code, err := totp.Code(secret, time.Now().Add(time.Duration(offset)*totp.Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func (e *testEnv) loginMFA(t *testing.T, email string) string {
+	t.Helper()
+	rec := e.DoJSON(http.MethodPost, "/auth", "", models.AuthRequest{Email: email, Password: "Password123!"})
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	res := testutils.Decode[models.ResponseLogin](t, rec)
+	if !res.MFARequired || res.MFAToken == "" || res.Token != "" {
+		t.Fatalf("login = %+v, want mfa challenge without access token", res)
+	}
+	return res.MFAToken
+}
+
+// token challenge tanpa lewat POST /auth, supaya test tidak habis oleh rate limit auth
+func (e *testEnv) mfaToken(t *testing.T, user testUser) string {
+	t.Helper()
+	token, err := pkg.NewMFAClaims(user.ID).GenToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestMFALogin(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	secret, enableCode, recovery := env.enableMFA(t, alice)
+
+	mfaToken := env.loginMFA(t, "alice@example.com")
+	// token challenge bukan access token
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/user", mfaToken, nil), http.StatusUnauthorized)
+
+	tests := []struct {
+		name string
+		// token challenge baru jika kosong
+		token     string
+		code      string
+		want      int
+		wantToken bool
+		// pakai lagi token dari kasus sebelumnya
+		reuse bool
+	}{
+		{name: "code from enable is replayed", token: mfaToken, code: enableCode, want: http.StatusUnauthorized},
+		{name: "wrong code", code: "000000", want: http.StatusUnauthorized},
+		{name: "token is spent by a wrong code", reuse: true, code: totpCode(t, secret, 1), want: http.StatusUnauthorized},
+		{name: "access token as mfa token", token: alice.Token, code: totpCode(t, secret, 1), want: http.StatusUnauthorized},
+		{name: "next step code", code: totpCode(t, secret, 1), want: http.StatusOK, wantToken: true},
+		{name: "mfa token is single use", reuse: true, code: recovery[0], want: http.StatusUnauthorized},
+	}
+	var token string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switch {
+			case tt.token != "":
+				token = tt.token
+			case !tt.reuse:
+				token = env.mfaToken(t, alice)
+			}
+			rec := env.DoJSON(http.MethodPost, "/auth/mfa/verify", "", models.MFAVerifyRequest{MFAToken: token, Code: tt.code})
+			testutils.ExpectStatus(t, rec, tt.want)
+			if tt.wantToken {
+				token := testutils.Decode[models.ResponseLogin](t, rec).Token
+				testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/user", token, nil), http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestMFALockout(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	secret, _, _ := env.enableMFA(t, alice)
+
+	verify := func(code string) *httptest.ResponseRecorder {
+		return env.DoJSON(http.MethodPost, "/auth/mfa/verify", "", models.MFAVerifyRequest{MFAToken: env.mfaToken(t, alice), Code: code})
+	}
+
+	for range utils.MaxMFAFailures {
+		testutils.ExpectStatus(t, verify("000000"), http.StatusUnauthorized)
+	}
+	// kode benar pun ditolak selama akun dikunci
+	testutils.ExpectStatus(t, verify(totpCode(t, secret, 1)), http.StatusTooManyRequests)
+
+	env.redis.FastForward(utils.MFALockout)
+	testutils.ExpectStatus(t, verify(totpCode(t, secret, 1)), http.StatusOK)
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	_, _, recovery := env.enableMFA(t, alice)
+
+	tests := []struct {
+		name string
+		code string
+		want int
+	}{
+		{name: "recovery code", code: recovery[0], want: http.StatusOK},
+		{name: "same recovery code again", code: recovery[0], want: http.StatusUnauthorized},
+		{name: "other recovery code in upper case", code: strings.ToUpper(recovery[1]), want: http.StatusOK},
+		{name: "unknown recovery code", code: "aaaaa-bbbbb", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfaToken := env.loginMFA(t, "alice@example.com")
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/auth/mfa/verify", "", models.MFAVerifyRequest{MFAToken: mfaToken, Code: tt.code}), tt.want)
+		})
+	}
+}
+
+func TestDisableMFA(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	secret, _, recovery := env.enableMFA(t, alice)
+
+	tests := []struct {
+		name string
+		req  models.MFADisableRequest
+		want int
+	}{
+		{name: "wrong password", req: models.MFADisableRequest{Password: "Wrong123!", Code: totpCode(t, secret, 1)}, want: http.StatusUnauthorized},
+		{name: "missing password", req: models.MFADisableRequest{Code: totpCode(t, secret, 1)}, want: http.StatusBadRequest},
+		{name: "wrong code", req: models.MFADisableRequest{Password: "Password123!", Code: "000000"}, want: http.StatusUnauthorized},
+		{name: "password and recovery code", req: models.MFADisableRequest{Password: "Password123!", Code: recovery[0]}, want: http.StatusOK},
+		{name: "already disabled", req: models.MFADisableRequest{Password: "Password123!", Code: recovery[1]}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodDelete, "/auth/mfa", alice.Token, tt.req), tt.want)
+		})
+	}
+
+	// tanpa 2FA login langsung mendapat access token
+	rec := env.DoJSON(http.MethodPost, "/auth", "", models.AuthRequest{Email: "alice@example.com", Password: "Password123!"})
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	if res := testutils.Decode[models.ResponseLogin](t, rec); res.Token == "" || res.MFARequired {
+		t.Fatalf("login = %+v, want access token", res)
+	}
+}
@@ -10,24 +10,33 @@ import (
 )
 
 type NotificationHandler struct {
-	repo *repositories.NotificationRepository
+	repo repositories.NotificationStore
 }
 
-func NewNotificationHandler(repo *repositories.NotificationRepository) *NotificationHandler {
+func NewNotificationHandler(repo repositories.NotificationStore) *NotificationHandler {
 	return &NotificationHandler{repo: repo}
 }
 
-// Get unread notifications
+// GetUnreadNotifications godoc
+// @Summary Get Unread Notifications
+// @Description Unread likes, comments, follows and mentions for the logged in user
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ResponseAny
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/notif [get]
 func (h *NotificationHandler) GetUnreadNotifications(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	notifications, err := h.repo.GetUnreadNotifications(ctx, uid)
+	notifications, err := h.repo.GetUnreadNotifications(ctx, uid, nil, 0, 0)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed to get notifications", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get notifications", err)
 		return
 	}
 
@@ -37,3 +46,40 @@ func (h *NotificationHandler) GetUnreadNotifications(ctx *gin.Context) {
 		Data:    notifications,
 	})
 }
+
+// GetUnreadNotificationsPage adalah GetUnreadNotifications dengan cursor
+// pagination untuk /v2/notifications
+func (h *NotificationHandler) GetUnreadNotificationsPage(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	limit := utils.ParseLimit(ctx.Query("limit"))
+	cursorTime, cursorID, err := utils.DecodeCursor(ctx.Query("cursor"))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid cursor", err)
+		return
+	}
+
+	notifications, err := h.repo.GetUnreadNotifications(ctx.Request.Context(), uid, cursorTime, cursorID, limit+1)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get notifications", err)
+		return
+	}
+
+	// items selalu array, null tidak dikenali EnvelopeV2 sebagai halaman
+	page := models.Page[models.Notification]{Items: append([]models.Notification{}, notifications...)}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.CursorID)
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.Notification]]{
+		Success: true,
+		Message: "Success Get Unread Notifications",
+		Data:    page,
+	})
+}
@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+)
+
+func TestNotifications(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	postID := env.createPost(t, alice, "Sunset")
+
+	actions := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   int
+	}{
+		{name: "follow", method: http.MethodPost, path: "/user/alice", want: http.StatusCreated},
+		{name: "like", method: http.MethodPost, path: "/post/" + strconv.Itoa(postID) + "/like", want: http.StatusNoContent},
+		{name: "comment with mention", method: http.MethodPost, path: "/post/comment", body: models.CreateCommentRequest{PostID: postID, Comment: "@alice mantap"}, want: http.StatusCreated},
+	}
+	for _, tt := range actions {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, tt.path, bob.Token, tt.body), tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/notif", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	notifs := testutils.Decode[models.Response[models.NotificationList]](t, rec).Data
+
+	got := make(map[string]int)
+	for _, n := range notifs {
+		if n.FromID != bob.ID {
+			t.Fatalf("notification %+v not from bob", n)
+		}
+		got[n.Type]++
+	}
+	for _, typ := range []string{"follow", "like", "comment", "mention"} {
+		if got[typ] != 1 {
+			t.Fatalf("notification types = %v, want one %s", got, typ)
+		}
+	}
+
+	// aksi sendiri tidak menghasilkan notifikasi
+	rec = env.DoJSON(http.MethodGet, "/notif", bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	if notifs := testutils.Decode[models.Response[models.NotificationList]](t, rec).Data; len(notifs) != 0 {
+		t.Fatalf("bob notifications = %+v, want none", notifs)
+	}
+}
+
+func TestNotificationsPagination(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	postID := env.createPost(t, alice, "Sunset")
+
+	// semua notifikasi di mikrodetik yang sama, komentar dan mention juga
+	// berasal dari user yang sama
+	at := time.Now().Add(-time.Hour)
+	env.db.Now = func() time.Time { return at }
+	t.Cleanup(func() { env.db.Now = time.Now })
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/user/alice", bob.Token, nil), http.StatusCreated)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/post/"+strconv.Itoa(postID)+"/like", bob.Token, nil), http.StatusNoContent)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/post/comment", bob.Token, models.CreateCommentRequest{PostID: postID, Comment: "@alice mantap"}), http.StatusCreated)
+
+	rec := env.DoJSON(http.MethodGet, "/v1/notif", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	var want []string
+	for _, n := range testutils.Decode[models.Response[models.NotificationList]](t, rec).Data {
+		want = append(want, n.Type)
+	}
+
+	pages := v2Pages[models.Notification](t, env, "/v2/notifications?limit=1", alice.Token)
+	var got []string
+	for _, page := range pages {
+		for _, n := range page {
+			got = append(got, n.Type)
+		}
+	}
+	if len(want) != 4 || !slices.Equal(got, want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+
+	// notifikasi bob kosong tetap berbentuk halaman
+	if pages := v2Pages[models.Notification](t, env, "/v2/notifications?limit=1", bob.Token); len(pages) != 1 || len(pages[0]) != 0 {
+		t.Fatalf("bob pages = %v, want one empty page", pages)
+	}
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/v2/notifications?cursor=not-a-cursor", alice.Token, nil), http.StatusBadRequest)
+}
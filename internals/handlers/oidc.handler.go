@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// masa berlaku state login OIDC
+const oidcStateTTL = 10 * time.Minute
+
+type oidcState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	// nonce yang harus kembali di id_token
+	Nonce string `json:"nonce"`
+}
+
+// string acak base64url untuk state dan nonce
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OIDCLogin godoc
+// @Summary Social login
+// @Description Redirect to the OIDC provider login page (authorization code + PKCE)
+// @Tags Auth
+// @Param provider path string true "Provider name, e.g. google"
+// @Success 302 "Redirect to provider"
+// @Failure 404 {object} models.ErrorResponse "Unknown provider"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/auth/oidc/{provider}/login [get]
+func (h *AuthHandler) OIDCLogin(ctx *gin.Context) {
+	provider, ok := h.providers[ctx.Param("provider")]
+	if !ok {
+		utils.HandleError(ctx, http.StatusNotFound, "unknown login provider", fmt.Errorf("unknown provider %q", ctx.Param("provider")))
+		return
+	}
+
+	verifier, challenge, err := pkg.GenPKCE()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to start login", err)
+		return
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to start login", err)
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to start login", err)
+		return
+	}
+
+	redisKey := fmt.Sprintf("OIDC-State:%s", state)
+	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, oidcState{Provider: provider.Name, CodeVerifier: verifier, Nonce: nonce}, oidcStateTTL/time.Minute); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to start login", err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadGateway, "login provider unavailable", err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Social login callback
+// @Description Handle the provider redirect, link or create the account and return a JWT token
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "Provider name, e.g. google"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} models.ResponseLogin "Login successful"
+// @Failure 400 {object} models.ErrorResponse "Invalid state or code"
+// @Failure 409 {object} models.ErrorResponse "Email belongs to an unverified account"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 502 {object} models.ErrorResponse "Provider error"
+// @Router /v1/auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) OIDCCallback(ctx *gin.Context) {
+	provider, ok := h.providers[ctx.Param("provider")]
+	if !ok {
+		utils.HandleError(ctx, http.StatusNotFound, "unknown login provider", fmt.Errorf("unknown provider %q", ctx.Param("provider")))
+		return
+	}
+
+	if errMsg := ctx.Query("error"); errMsg != "" {
+		utils.HandleError(ctx, http.StatusBadRequest, "login was cancelled", errors.New(errMsg))
+		return
+	}
+
+	code, state := ctx.Query("code"), ctx.Query("state")
+	if code == "" || state == "" {
+		utils.HandleError(ctx, http.StatusBadRequest, "missing code or state", errors.New("missing code or state"))
+		return
+	}
+
+	// State hanya bisa dipakai sekali
+	raw, err := h.rdb.GetDel(ctx.Request.Context(), fmt.Sprintf("OIDC-State:%s", state)).Bytes()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid or expired state", err)
+		return
+	}
+	var saved oidcState
+	if err := json.Unmarshal(raw, &saved); err != nil || saved.Provider != provider.Name {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid or expired state", fmt.Errorf("state mismatch: %v", err))
+		return
+	}
+
+	token, err := provider.Exchange(ctx.Request.Context(), code, saved.CodeVerifier)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "failed to exchange code", err)
+		return
+	}
+
+	if token.IDToken == "" {
+		utils.HandleError(ctx, http.StatusBadGateway, "login provider did not return an id token", errors.New("empty id_token"))
+		return
+	}
+	// sub dan email_verified hanya dipercaya dari id_token yang terverifikasi
+	info, err := provider.VerifyIDToken(ctx.Request.Context(), token.IDToken, saved.Nonce)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid id token", err)
+		return
+	}
+
+	// sebagian provider hanya mengirim email lewat userinfo
+	if info.Email == "" {
+		extra, err := provider.UserInfo(ctx.Request.Context(), token.AccessToken)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusBadGateway, "failed to get user info", err)
+			return
+		}
+		if extra.Subject != info.Subject {
+			utils.HandleError(ctx, http.StatusBadGateway, "failed to get user info", fmt.Errorf("userinfo subject %q does not match id token", extra.Subject))
+			return
+		}
+		info.Email = extra.Email
+	}
+
+	accountID, err := h.resolveIdentity(ctx, provider.Name, info)
+	if err != nil {
+		return
+	}
+
+	h.completeLogin(ctx, accountID)
+}
+
+// resolveIdentity mencari akun untuk identitas eksternal: dari tabel
+// identitas, lewat email terverifikasi, atau membuat akun baru
+func (h *AuthHandler) resolveIdentity(ctx *gin.Context, provider string, info *pkg.OIDCUserInfo) (int, error) {
+	rctx := ctx.Request.Context()
+
+	accountID, err := h.repo.GetAccountByIdentity(rctx, provider, info.Subject)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to find identity", err)
+		return 0, err
+	}
+	if accountID != 0 {
+		return accountID, nil
+	}
+
+	email := strings.ToLower(strings.TrimSpace(info.Email))
+	if email == "" || !bool(info.EmailVerified) {
+		err := errors.New("provider did not return a verified email")
+		utils.HandleError(ctx, http.StatusBadRequest, "a verified email is required", err)
+		return 0, err
+	}
+
+	// Hubungkan ke akun lokal dengan email yang sama
+	account, err := h.repo.Login(rctx, email)
+	if err == nil {
+		// Akun lokal yang belum diverifikasi tidak boleh diambil alih
+		if account.VerifiedAt == nil {
+			err := errors.New("local account not verified")
+			utils.HandleError(ctx, http.StatusConflict, "please verify your email before linking a social login", err)
+			return 0, err
+		}
+		if err := h.repo.LinkIdentity(rctx, account.ID, provider, info.Subject, email); err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to link identity", err)
+			return 0, err
+		}
+		return account.ID, nil
+	}
+	// akun baru hanya dibuat jika email memang belum terdaftar, bukan saat
+	// database bermasalah
+	if !errors.Is(err, repositories.ErrAccountNotFound) {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get account", err)
+		return 0, err
+	}
+
+	// Buat akun baru dengan profile kosong
+	accountID, err = h.repo.RegisterWithIdentity(rctx, email, provider, info.Subject)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to create account", err)
+		return 0, err
+	}
+	utils.SignupsTotal.WithLabelValues(provider).Inc()
+	return accountID, nil
+}
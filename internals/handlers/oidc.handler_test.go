@@ -0,0 +1,394 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+const (
+	mockClientID     = "chat-client"
+	mockClientSecret = "chat-secret"
+	mockRedirectURL  = "http://localhost:8080/auth/oidc/mock/callback"
+)
+
+// identitas yang dikembalikan provider mock
+type mockIdentity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type mockGrant struct {
+	challenge string
+	nonce     string
+	identity  mockIdentity
+	tamper    func(jwt.MapClaims) *rsa.PrivateKey
+}
+
+// mockOIDC adalah issuer OIDC lokal: discovery, jwks, authorize, token dan userinfo
+type mockOIDC struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu   sync.Mutex
+	next mockIdentity
+	// mengubah claims id_token login berikutnya, key selain nil dipakai
+	// untuk menandatangani
+	tamper func(jwt.MapClaims) *rsa.PrivateKey
+	codes  map[string]mockGrant
+	tokens map[string]mockIdentity
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{key: key, codes: map[string]mockGrant{}, tokens: map[string]mockIdentity{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.srv.URL,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"userinfo_endpoint":      m.srv.URL + "/userinfo",
+			"jwks_uri":               m.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pkg.JWKS{Keys: []pkg.JWK{{
+			Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "mock-1",
+			N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("GET /authorize", m.authorize)
+	mux.HandleFunc("POST /token", m.token)
+	mux.HandleFunc("GET /userinfo", m.userinfo)
+
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+func (m *mockOIDC) provider() map[string]*pkg.OIDCProvider {
+	return map[string]*pkg.OIDCProvider{
+		"mock": pkg.NewOIDCProvider("mock", m.srv.URL, mockClientID, mockClientSecret, mockRedirectURL, nil),
+	}
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (m *mockOIDC) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != mockClientID || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	code := randomString()
+	m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), identity: m.next, tamper: m.tamper}
+	m.mu.Unlock()
+
+	http.Redirect(w, r, q.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {q.Get("state")}}.Encode(), http.StatusFound)
+}
+
+func (m *mockOIDC) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// code hanya bisa ditukar sekali
+	grant, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok, r.PostForm.Get("grant_type") != "authorization_code",
+		r.PostForm.Get("client_id") != mockClientID, r.PostForm.Get("client_secret") != mockClientSecret,
+		r.PostForm.Get("redirect_uri") != mockRedirectURL,
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	accessToken := randomString()
+	m.tokens[accessToken] = grant.identity
+	json.NewEncoder(w).Encode(map[string]string{"access_token": accessToken, "token_type": "Bearer", "id_token": m.idToken(grant)})
+}
+
+func (m *mockOIDC) idToken(grant mockGrant) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": m.srv.URL, "aud": mockClientID, "sub": grant.identity.Subject,
+		"email": grant.identity.Email, "email_verified": grant.identity.EmailVerified,
+		"nonce": grant.nonce, "iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+	}
+	key := m.key
+	if grant.tamper != nil {
+		if other := grant.tamper(claims); other != nil {
+			key = other
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "mock-1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (m *mockOIDC) userinfo(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	identity, ok := m.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	m.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	json.NewEncoder(w).Encode(identity)
+}
+
+// login mengikuti redirect ke provider sebagai identity, kembalikan path
+// callback ke aplikasi
+func (m *mockOIDC) login(t *testing.T, env *testEnv, identity mockIdentity) string {
+	t.Helper()
+	rec := env.Do(http.MethodGet, "/auth/oidc/mock/login", "", nil, "")
+	testutils.ExpectStatus(t, rec, http.StatusFound)
+
+	m.mu.Lock()
+	m.next = identity
+	m.mu.Unlock()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want 302", res.StatusCode)
+	}
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "/auth/oidc/mock/callback?" + callback.RawQuery
+}
+
+// user id dari access token di response login
+func loginUserID(t *testing.T, rec *httptest.ResponseRecorder) int {
+	t.Helper()
+	var claims pkg.Claims
+	if err := claims.VerifyToken(testutils.Decode[models.ResponseLogin](t, rec).Token); err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	return claims.UserId
+}
+
+func TestOIDCLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity mockIdentity
+		// akun lokal dengan email identity, "" jika tidak ada
+		local     string
+		want      int
+		wantLocal bool
+	}{
+		{name: "new account", identity: mockIdentity{Subject: "sub-1", Email: "new@example.com", EmailVerified: true}, want: http.StatusOK},
+		{name: "links verified local account", identity: mockIdentity{Subject: "sub-2", Email: "Alice@Example.com", EmailVerified: true}, local: "verified", want: http.StatusOK, wantLocal: true},
+		{name: "refuses unverified local account", identity: mockIdentity{Subject: "sub-3", Email: "alice@example.com", EmailVerified: true}, local: "unverified", want: http.StatusConflict},
+		{name: "provider email not verified", identity: mockIdentity{Subject: "sub-4", Email: "new@example.com"}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newMockOIDC(t)
+			env := newTestEnvWith(t, provider.provider(), nil)
+
+			var localID int
+			switch tt.local {
+			case "verified":
+				localID = env.createUser(t, "alice@example.com", "alice", "Alice Johnson").ID
+			case "unverified":
+				id, err := env.stores.Auth.Register(context.Background(), "alice@example.com", "hash")
+				if err != nil {
+					t.Fatal(err)
+				}
+				localID = id
+			}
+
+			rec := env.Do(http.MethodGet, provider.login(t, env, tt.identity), "", nil, "")
+			testutils.ExpectStatus(t, rec, tt.want)
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			uid := loginUserID(t, rec)
+			if tt.wantLocal && uid != localID {
+				t.Fatalf("logged in as %d, want local account %d", uid, localID)
+			}
+			if !tt.wantLocal {
+				account, err := env.stores.Auth.Login(context.Background(), tt.identity.Email)
+				if err != nil || account.ID != uid {
+					t.Fatalf("new account = %+v, %v, want id %d", account, err, uid)
+				}
+			}
+
+			// login berikutnya memakai identitas yang sudah terhubung
+			rec = env.Do(http.MethodGet, provider.login(t, env, tt.identity), "", nil, "")
+			testutils.ExpectStatus(t, rec, http.StatusOK)
+			if again := loginUserID(t, rec); again != uid {
+				t.Fatalf("second login as %d, want %d", again, uid)
+			}
+		})
+	}
+}
+
+func TestOIDCStateAndPKCE(t *testing.T) {
+	provider := newMockOIDC(t)
+	env := newTestEnvWith(t, provider.provider(), nil)
+	identity := mockIdentity{Subject: "sub-1", Email: "new@example.com", EmailVerified: true}
+
+	first := provider.login(t, env, identity)
+	second := provider.login(t, env, identity)
+	valid := provider.login(t, env, identity)
+	firstQuery, _ := url.ParseQuery(strings.SplitN(first, "?", 2)[1])
+	secondQuery, _ := url.ParseQuery(strings.SplitN(second, "?", 2)[1])
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "unknown state", path: "/auth/oidc/mock/callback?" + url.Values{"code": {firstQuery.Get("code")}, "state": {"bogus"}}.Encode(), want: http.StatusBadRequest},
+		// code dari login pertama dengan verifier login kedua
+		{name: "code bound to another verifier", path: "/auth/oidc/mock/callback?" + url.Values{"code": {firstQuery.Get("code")}, "state": {secondQuery.Get("state")}}.Encode(), want: http.StatusBadRequest},
+		{name: "missing code", path: "/auth/oidc/mock/callback?state=" + url.QueryEscape(firstQuery.Get("state")), want: http.StatusBadRequest},
+		{name: "cancelled at provider", path: "/auth/oidc/mock/callback?error=access_denied", want: http.StatusBadRequest},
+		{name: "unknown provider", path: "/auth/oidc/other/callback?" + firstQuery.Encode(), want: http.StatusNotFound},
+		{name: "valid callback", path: valid, want: http.StatusOK},
+		{name: "state is single use", path: valid, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.Do(http.MethodGet, tt.path, "", nil, ""), tt.want)
+		})
+	}
+}
+
+func TestOIDCIDToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit := func(key string, value any) func(jwt.MapClaims) *rsa.PrivateKey {
+		return func(claims jwt.MapClaims) *rsa.PrivateKey {
+			if value == nil {
+				delete(claims, key)
+			} else {
+				claims[key] = value
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(jwt.MapClaims) *rsa.PrivateKey
+		want   int
+	}{
+		{name: "valid", want: http.StatusOK},
+		{name: "nonce from another login", tamper: edit("nonce", "other-nonce"), want: http.StatusBadRequest},
+		{name: "missing nonce", tamper: edit("nonce", nil), want: http.StatusBadRequest},
+		{name: "other audience", tamper: edit("aud", "other-client"), want: http.StatusBadRequest},
+		{name: "several audiences without azp", tamper: edit("aud", []string{mockClientID, "other-client"}), want: http.StatusBadRequest},
+		{name: "several audiences with azp", tamper: func(claims jwt.MapClaims) *rsa.PrivateKey {
+			claims["aud"], claims["azp"] = []string{mockClientID, "other-client"}, mockClientID
+			return nil
+		}, want: http.StatusOK},
+		{name: "other issuer", tamper: edit("iss", "https://evil.example"), want: http.StatusBadRequest},
+		{name: "expired", tamper: edit("exp", time.Now().Add(-time.Hour).Unix()), want: http.StatusBadRequest},
+		{name: "missing exp", tamper: edit("exp", nil), want: http.StatusBadRequest},
+		{name: "signed by another key", tamper: func(jwt.MapClaims) *rsa.PrivateKey { return otherKey }, want: http.StatusBadRequest},
+		// email_verified dari userinfo tidak dipercaya
+		{name: "email_verified only in userinfo", tamper: edit("email_verified", nil), want: http.StatusBadRequest},
+		{name: "email only in userinfo", tamper: edit("email", nil), want: http.StatusOK},
+		{name: "userinfo for another subject", tamper: func(claims jwt.MapClaims) *rsa.PrivateKey {
+			claims["sub"] = "sub-other"
+			delete(claims, "email")
+			return nil
+		}, want: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newMockOIDC(t)
+			env := newTestEnvWith(t, provider.provider(), nil)
+			provider.mu.Lock()
+			provider.tamper = tt.tamper
+			provider.mu.Unlock()
+
+			path := provider.login(t, env, mockIdentity{Subject: "sub-1", Email: "new@example.com", EmailVerified: true})
+			testutils.ExpectStatus(t, env.Do(http.MethodGet, path, "", nil, ""), tt.want)
+		})
+	}
+}
+
+// AuthStore yang gagal saat mencari akun lewat email
+type failingLoginStore struct {
+	repositories.AuthStore
+	registered atomic.Int32
+}
+
+func (s *failingLoginStore) Login(ctx context.Context, email string) (*models.Account, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (s *failingLoginStore) RegisterWithIdentity(ctx context.Context, email, provider, subject string) (int, error) {
+	s.registered.Add(1)
+	return s.AuthStore.RegisterWithIdentity(ctx, email, provider, subject)
+}
+
+func TestOIDCLoginDatabaseError(t *testing.T) {
+	provider := newMockOIDC(t)
+	store := &failingLoginStore{}
+	env := newTestEnvWith(t, provider.provider(), func(stores *repositories.Stores) {
+		store.AuthStore = stores.Auth
+		stores.Auth = store
+	})
+
+	rec := env.Do(http.MethodGet, provider.login(t, env, mockIdentity{Subject: "sub-1", Email: "alice@example.com", EmailVerified: true}), "", nil, "")
+	testutils.ExpectStatus(t, rec, http.StatusInternalServerError)
+	if n := store.registered.Load(); n != 0 {
+		t.Fatalf("RegisterWithIdentity called %d times, want no account created on database error", n)
+	}
+}
@@ -2,74 +2,188 @@ package handlers
 
 import (
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/rankers"
 	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/ntisrangga142/chat/internals/utils"
 	"github.com/redis/go-redis/v9"
 )
 
 type PostHandler struct {
-	repo *repositories.PostRepository
-	rdb  *redis.Client
+	repo   repositories.PostStore
+	rdb    *redis.Client
+	ranker rankers.FeedRanker
 }
 
-func NewPostHandler(repo *repositories.PostRepository, rdb *redis.Client) *PostHandler {
-	return &PostHandler{repo: repo, rdb: rdb}
+func NewPostHandler(repo repositories.PostStore, rdb *redis.Client, ranker rankers.FeedRanker) *PostHandler {
+	return &PostHandler{repo: repo, rdb: rdb, ranker: ranker}
 }
 
+// mode feed following
+const (
+	feedModeLatest = "latest"
+	feedModeRanked = "ranked"
+)
+
 // GetFollowingPosts godoc
 // @Summary Get Following Posts
-// @Description Get posts from accounts that the user follows
+// @Description Get posts from accounts that the user follows. Default is newest first; mode=ranked orders by recency, my interactions with the author and engagement.
 // @Tags Posts
 // @Security BearerAuth
 // @Produce json
+// @Param mode query string false "latest (default) or ranked" Enums(latest, ranked)
 // @Success 200 {object} models.ResponsePostList
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/following [get]
+// @Router /v1/post [get]
 func (h *PostHandler) GetFollowingPosts(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	var cachedData models.PostFeed
-	var redisKey = fmt.Sprintf("Chat-ListPosts-%d", uid)
-	if err := utils.CacheHit(ctx.Request.Context(), h.rdb, redisKey, &cachedData); err == nil {
-		ctx.JSON(http.StatusOK, models.Response[any]{
-			Success: true,
-			Message: "Success Get List Post (from cache)",
-			Data:    cachedData,
-		})
+	mode, ok := feedMode(ctx)
+	if !ok {
 		return
 	}
 
-	posts, err := h.repo.GetFollowingPosts(ctx, uid)
+	posts, fromCache, err := h.followingFeed(ctx, uid, mode)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to get posts", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get posts", err)
 		return
 	}
+	h.applyViewerState(ctx.Request.Context(), uid, posts)
 
-	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, posts, 2); err != nil {
-		log.Println("Failed to set redis cache:", err)
+	message := "Success Get Post Followings"
+	if fromCache {
+		message = "Success Get List Post (from cache)"
 	}
-
 	ctx.JSON(http.StatusOK, models.Response[any]{
 		Success: true,
-		Message: "Success Get Post Followings",
+		Message: message,
 		Data:    posts,
 	})
 }
 
+// GetFollowingPostsPage adalah GetFollowingPosts dengan cursor pagination untuk
+// /v2/posts. Mode latest membaca satu halaman langsung dari database, mode
+// ranked memotong feed ranked (dari cache) setelah post cursor.
+func (h *PostHandler) GetFollowingPostsPage(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	mode, ok := feedMode(ctx)
+	if !ok {
+		return
+	}
+	limit := utils.ParseLimit(ctx.Query("limit"))
+	cursorTime, cursorID, err := utils.DecodeCursor(ctx.Query("cursor"))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusBadRequest, "invalid cursor", err)
+		return
+	}
+
+	// satu post lebih untuk mengetahui apakah masih ada halaman berikutnya
+	var posts []models.PostFeed
+	if mode == feedModeLatest {
+		posts, err = h.repo.GetFollowingPosts(ctx.Request.Context(), uid, cursorTime, cursorID, limit+1)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get posts", err)
+			return
+		}
+	} else {
+		ranked, _, err := h.followingFeed(ctx, uid, mode)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get posts", err)
+			return
+		}
+		start := 0
+		if cursorTime != nil {
+			// urutan ranked bisa berubah setelah cache diperbarui, halaman
+			// berikutnya dimulai setelah posisi post cursor saat ini
+			start = slices.IndexFunc(ranked, func(p models.PostFeed) bool { return p.ID == cursorID })
+			if start < 0 {
+				utils.HandleError(ctx, http.StatusBadRequest, "cursor expired, reload the feed from the first page", fmt.Errorf("post %d is no longer in the ranked feed", cursorID))
+				return
+			}
+			start++
+		}
+		posts = ranked[start:min(start+limit+1, len(ranked))]
+	}
+
+	// items selalu array, null tidak dikenali EnvelopeV2 sebagai halaman
+	if posts == nil {
+		posts = []models.PostFeed{}
+	}
+	page := models.Page[models.PostFeed]{Items: posts}
+	if len(posts) > limit {
+		page.Items = posts[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	h.applyViewerState(ctx.Request.Context(), uid, page.Items)
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.PostFeed]]{
+		Success: true,
+		Message: "Success Get Post Followings",
+		Data:    page,
+	})
+}
+
+// feedMode membaca query mode, menulis response 400 jika tidak dikenal
+func feedMode(ctx *gin.Context) (string, bool) {
+	mode := ctx.DefaultQuery("mode", feedModeLatest)
+	if mode != feedModeLatest && mode != feedModeRanked {
+		utils.HandleError(ctx, http.StatusBadRequest, "mode must be latest or ranked", fmt.Errorf("invalid feed mode %q", mode))
+		return "", false
+	}
+	return mode, true
+}
+
+// followingFeed mengembalikan seluruh feed following sesuai mode, di-cache
+// 2 menit per user dan mode
+func (h *PostHandler) followingFeed(ctx *gin.Context, uid int, mode string) ([]models.PostFeed, bool, error) {
+	var cachedData []models.PostFeed
+	var redisKey = fmt.Sprintf("Chat-ListPosts-%d", uid)
+	if mode == feedModeRanked {
+		redisKey = fmt.Sprintf("Chat-ListPosts-Ranked-%d", uid)
+	}
+	if err := utils.CacheHit(ctx.Request.Context(), h.rdb, redisKey, &cachedData); err == nil {
+		return cachedData, true, nil
+	}
+
+	posts, err := h.repo.GetFollowingPosts(ctx, uid, nil, 0, 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if mode == feedModeRanked {
+		affinity, err := h.repo.GetAuthorAffinity(ctx.Request.Context(), uid)
+		if err != nil {
+			return nil, false, err
+		}
+		posts = h.ranker.Rank(posts, rankers.FeedSignals{Now: time.Now(), Affinity: affinity})
+	}
+
+	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, posts, 2); err != nil {
+		utils.Logger(ctx).Warn("Failed to set redis cache", "error", err)
+	}
+	return posts, false, nil
+}
+
 // GetPostDetail godoc
 // @Summary Get Post Detail
-// @Description Get detail of a single post including author, images, like count, and top comments
+// @Description Get detail of a single post including author, images, like count, top comments and my liked/saved state
 // @Tags Posts
 // @Security BearerAuth
 // @Produce json
@@ -77,18 +191,26 @@ func (h *PostHandler) GetFollowingPosts(ctx *gin.Context) {
 // @Success 200 {object} models.ResponsePostDetail
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse "Post not found"
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/{id} [get]
+// @Router /v1/post/{id} [get]
 func (h *PostHandler) GetPostDetail(ctx *gin.Context) {
-	postID, err := strconv.Atoi(ctx.Param("id"))
+	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Invalid ID", "post id must be number", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
 		return
 	}
 
 	var cachedData models.PostDetail
 	var redisKey = fmt.Sprintf("Chat-PostDetail-%d", postID)
 	if err := utils.CacheHit(ctx.Request.Context(), h.rdb, redisKey, &cachedData); err == nil {
+		// cache bersama hanya menyimpan data post, status viewer ditempel per request
+		cachedData.ViewerState = h.viewerStateFor(ctx.Request.Context(), uid, postID)
 		ctx.JSON(http.StatusOK, models.Response[any]{
 			Success: true,
 			Message: "Success Get Profile User (from cache)",
@@ -99,13 +221,14 @@ func (h *PostHandler) GetPostDetail(ctx *gin.Context) {
 
 	post, err := h.repo.GetPostDetail(ctx.Request.Context(), postID)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Failed", "cannot get post detail", err)
+		utils.HandleDomainError(ctx, err, "cannot get post detail")
 		return
 	}
 
 	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, post, 10); err != nil {
-		log.Println("Failed to set redis cache:", err)
+		utils.Logger(ctx).Warn("Failed to set redis cache", "error", err)
 	}
+	post.ViewerState = h.viewerStateFor(ctx.Request.Context(), uid, postID)
 
 	ctx.JSON(http.StatusOK, models.Response[any]{
 		Success: true,
@@ -116,58 +239,60 @@ func (h *PostHandler) GetPostDetail(ctx *gin.Context) {
 
 // CreatePost godoc
 // @Summary Create Post
-// @Description Create a new post with caption and images (form-data)
+// @Description Create a new post with caption and images (form-data). Caption is at most 2200 characters and required when no image is uploaded; up to 10 jpg/png images of at most 1MB each.
 // @Tags Posts
 // @Security BearerAuth
 // @Accept multipart/form-data
 // @Produce json
-// @Param caption formData string true "Post Caption"
-// @Param images formData []file true "Post Images"
+// @Param caption formData string false "Post Caption"
+// @Param images formData []file false "Post Images"
+// @Param Idempotency-Key header string false "Client generated key, retries with the same key replay the first response"
 // @Success 201 {object} models.ResponseCreatePost
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Request with the same Idempotency-Key in progress"
+// @Failure 422 {object} models.ErrorResponse "Idempotency-Key reused with a different body"
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts [post]
+// @Router /v1/post [post]
 func (h *PostHandler) CreatePost(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	// Ambil caption
-	caption := ctx.PostForm("caption")
-
-	// Ambil file images
-	form, err := ctx.MultipartForm()
-	if err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "failed to parse form-data", err)
+	var form models.CreatePostForm
+	if err := ctx.ShouldBind(&form); err != nil {
+		utils.HandleBindError(ctx, err)
 		return
 	}
 
-	files := form.File["images"]
 	var imagePaths []string
-
-	for _, file := range files {
-		// Simpan file ke folder uploads
-		path := "public/post/" + file.Filename
-		if err := ctx.SaveUploadedFile(file, path); err != nil {
-			utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to save file", err)
+	for i, file := range form.Images {
+		// nama file dibuat server, nama dari client tidak dipakai
+		name, err := utils.SaveUploadedFile(ctx, file, "public/post", fmt.Sprintf("post_%d_%d_%d", uid, time.Now().UnixNano(), i))
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to save file", err)
 			return
 		}
-		imagePaths = append(imagePaths, path)
+		imagePaths = append(imagePaths, "public/post/"+name)
 	}
 
 	req := models.CreatePostRequest{
-		Caption: caption,
+		Caption: form.Caption,
 		Images:  imagePaths,
 	}
 
 	post, err := h.repo.CreatePost(ctx, req, uid)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to create post", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to create post", err)
 		return
 	}
+	utils.PostsCreatedTotal.Inc()
+
+	if err := utils.TrackExplorePost(ctx.Request.Context(), h.rdb, post.ID, post.CreatedAt); err != nil {
+		utils.Logger(ctx).Warn("Failed to track explore post", "error", err)
+	}
 
 	ctx.JSON(http.StatusCreated, models.Response[any]{
 		Success: true,
@@ -183,25 +308,37 @@ func (h *PostHandler) CreatePost(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "Post ID"
 // @Success 204
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse "Post not found"
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/{id}/like [post]
+// @Router /v1/post/{id}/like [post]
 func (h *PostHandler) LikePost(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	postID, _ := strconv.Atoi(ctx.Param("id"))
-	if err := h.repo.CreateLike(ctx, uid, postID); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed to like post", err)
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	liked, err := h.repo.CreateLike(ctx, uid, postID)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "failed to like post")
 		return
 	}
+	if liked {
+		utils.LikesTotal.Inc()
+		if err := utils.RecordEngagement(ctx.Request.Context(), h.rdb, postID, utils.ExploreLikeWeight, time.Now()); err != nil {
+			utils.Logger(ctx).Warn("Failed to update explore score", "error", err)
+		}
+	}
 
 	var redisKey = fmt.Sprintf("Chat-PostDetail-%d", postID)
 	if err := utils.InvalidateCache(ctx, h.rdb, redisKey); err != nil {
-		log.Println("Failed invalidate cache:", err)
+		utils.Logger(ctx).Warn("Failed invalidate cache", "error", err)
 	}
 
 	ctx.Status(http.StatusNoContent)
@@ -214,25 +351,35 @@ func (h *PostHandler) LikePost(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "Post ID"
 // @Success 204
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/{id}/like [delete]
+// @Router /v1/post/{id}/like [delete]
 func (h *PostHandler) UnlikePost(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	postID, _ := strconv.Atoi(ctx.Param("id"))
-	if err := h.repo.DeleteLike(ctx, uid, postID); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Sever Error", "failed to unlike post", err)
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
 		return
 	}
+	likedAt, err := h.repo.DeleteLike(ctx, uid, postID)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to unlike post", err)
+		return
+	}
+	if likedAt != nil {
+		if err := utils.RecordEngagement(ctx.Request.Context(), h.rdb, postID, -utils.ExploreLikeWeight, *likedAt); err != nil {
+			utils.Logger(ctx).Warn("Failed to update explore score", "error", err)
+		}
+	}
 
 	var redisKey = fmt.Sprintf("Chat-PostDetail-%d", postID)
 	if err := utils.InvalidateCache(ctx, h.rdb, redisKey); err != nil {
-		log.Println("Failed invalidate cache:", err)
+		utils.Logger(ctx).Warn("Failed invalidate cache", "error", err)
 	}
 
 	ctx.Status(http.StatusNoContent)
@@ -246,32 +393,53 @@ func (h *PostHandler) UnlikePost(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body models.CreateCommentRequest true "Comment Request"
+// @Param Idempotency-Key header string false "Client generated key, retries with the same key replay the first response"
 // @Success 201
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse "Post not found"
+// @Failure 409 {object} models.ErrorResponse "Request with the same Idempotency-Key in progress"
+// @Failure 422 {object} models.ErrorResponse "Idempotency-Key reused with a different body"
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/{id}/comments [post]
+// @Router /v1/post/comment [post]
 func (h *PostHandler) CreateComment(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
+	// /v2 membawa post id di path dan menang atas post_id di body, /v1 hanya di body
 	var req models.CreateCommentRequest
+	var pathID int
+	if ctx.Param("id") != "" {
+		id, ok := utils.ParseIDParam(ctx, "id")
+		if !ok {
+			return
+		}
+		req.PostID, pathID = id, id
+	}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "invalid request", err)
+		utils.HandleBindError(ctx, err)
 		return
 	}
+	if pathID != 0 {
+		req.PostID = pathID
+	}
 
 	if err := h.repo.CreateComment(ctx, uid, req); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed to create comment", err)
+		utils.HandleDomainError(ctx, err, "failed to create comment")
 		return
 	}
+	utils.CommentsTotal.Inc()
+
+	if err := utils.RecordEngagement(ctx.Request.Context(), h.rdb, req.PostID, utils.ExploreCommentWeight, time.Now()); err != nil {
+		utils.Logger(ctx).Warn("Failed to update explore score", "error", err)
+	}
 
 	var redisKey = fmt.Sprintf("Chat-PostDetail-%d", req.PostID)
 	if err := utils.InvalidateCache(ctx, h.rdb, redisKey); err != nil {
-		log.Println("Failed invalidate cache:", err)
+		utils.Logger(ctx).Warn("Failed invalidate cache", "error", err)
 	}
 
 	ctx.Status(http.StatusCreated)
@@ -285,14 +453,19 @@ func (h *PostHandler) CreateComment(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Post ID"
 // @Success 200 {object} models.ResponseGetComment
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse "Post not found"
 // @Failure 500 {object} models.ErrorResponse
-// @Router /posts/{id}/comments [get]
+// @Router /v1/post/{id}/comment [get]
 func (h *PostHandler) GetAllCommentsByPost(ctx *gin.Context) {
-	postID, _ := strconv.Atoi(ctx.Param("id"))
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
 	comments, err := h.repo.GetAllCommentsByPost(ctx, postID)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed to fetch comments", err)
+		utils.HandleDomainError(ctx, err, "failed to fetch comments")
 		return
 	}
 	ctx.JSON(http.StatusOK, models.Response[any]{
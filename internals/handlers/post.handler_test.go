@@ -0,0 +1,431 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+func TestCreatePost(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	tests := []struct {
+		name      string
+		token     string
+		fields    map[string]string
+		want      int
+		wantField string
+	}{
+		{name: "caption only", token: alice.Token, fields: map[string]string{"caption": "Liburan di pantai"}, want: http.StatusCreated},
+		{name: "without token", token: "", fields: map[string]string{"caption": "x"}, want: http.StatusUnauthorized},
+		{name: "no caption and no images", token: alice.Token, fields: map[string]string{}, want: http.StatusBadRequest, wantField: "caption"},
+		{name: "caption too long", token: alice.Token, fields: map[string]string{"caption": strings.Repeat("a", 2201)}, want: http.StatusBadRequest, wantField: "caption"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoForm(http.MethodPost, "/post", tt.token, tt.fields)
+			testutils.ExpectStatus(t, rec, tt.want)
+			if tt.wantField != "" {
+				if res := testutils.Decode[models.ErrorResponse](t, rec); len(res.Details) != 1 || res.Details[0].Field != tt.wantField {
+					t.Fatalf("details = %+v, want error on %s", res.Details, tt.wantField)
+				}
+			}
+			if tt.want != http.StatusCreated {
+				return
+			}
+			post := testutils.Decode[models.Response[models.Post]](t, rec).Data
+			if post.ID == 0 || post.AccountID != alice.ID || post.Caption != tt.fields["caption"] {
+				t.Fatalf("post = %+v, want caption owned by alice", post)
+			}
+		})
+	}
+}
+
+func TestCreatePostImages(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	// file upload ditulis relatif ke working directory
+	t.Chdir(t.TempDir())
+
+	upload := func(filenames ...string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, name := range filenames {
+			part, _ := w.CreateFormFile("images", name)
+			part.Write([]byte("fake image"))
+		}
+		w.Close()
+		return env.Do(http.MethodPost, "/post", alice.Token, &buf, w.FormDataContentType())
+	}
+
+	rec := upload("../../etc/passwd.png")
+	testutils.ExpectStatus(t, rec, http.StatusCreated)
+	post := testutils.Decode[models.Response[models.Post]](t, rec).Data
+	var count int
+	if detail, err := env.stores.Post.GetPostDetail(context.Background(), post.ID); err == nil {
+		for _, img := range detail.Images {
+			count++
+			if strings.Contains(img, "..") || !strings.HasPrefix(img, "public/post/post_") {
+				t.Fatalf("image path = %q, want server generated name", img)
+			}
+		}
+	}
+	if count != 1 {
+		t.Fatalf("images = %d, want 1", count)
+	}
+
+	rec = upload("notes.txt")
+	testutils.ExpectStatus(t, rec, http.StatusBadRequest)
+	if res := testutils.Decode[models.ErrorResponse](t, rec); len(res.Details) != 1 || res.Details[0].Code != "invalid_image" {
+		t.Fatalf("details = %+v, want invalid_image", res.Details)
+	}
+
+	names := make([]string, 11)
+	for i := range names {
+		names[i] = fmt.Sprintf("%d.jpg", i)
+	}
+	rec = upload(names...)
+	testutils.ExpectStatus(t, rec, http.StatusBadRequest)
+	if res := testutils.Decode[models.ErrorResponse](t, rec); len(res.Details) != 1 || res.Details[0].Code != "too_many" {
+		t.Fatalf("details = %+v, want too_many", res.Details)
+	}
+}
+
+func TestCreatePostIdempotent(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	// form dibangun ulang setiap kali, seperti client yang retry (boundary berbeda)
+	create := func(key, caption string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		w.WriteField("caption", caption)
+		w.Close()
+		req := httptest.NewRequest(http.MethodPost, "/post", &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+alice.Token)
+		req.Header.Set("Idempotency-Key", key)
+		rec := httptest.NewRecorder()
+		env.Router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := create("retry-1", "Liburan")
+	testutils.ExpectStatus(t, first, http.StatusCreated)
+	retry := create("retry-1", "Liburan")
+	testutils.ExpectStatus(t, retry, http.StatusCreated)
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("retry was not replayed")
+	}
+	if a, b := testutils.Decode[models.Response[models.Post]](t, first).Data.ID, testutils.Decode[models.Response[models.Post]](t, retry).Data.ID; a != b {
+		t.Fatalf("retry created post %d, want replay of post %d", b, a)
+	}
+
+	rec := create("retry-1", "Caption lain")
+	testutils.ExpectStatus(t, rec, http.StatusUnprocessableEntity)
+	if res := testutils.Decode[models.ErrorResponse](t, rec); res.ErrorCode != "idempotency_key_reused" {
+		t.Fatalf("error_code = %q, want idempotency_key_reused", res.ErrorCode)
+	}
+}
+
+func TestLikeAndViewerState(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+	ctx := context.Background()
+
+	// charlie mengikuti alice dan bob
+	env.stores.User.Follow(ctx, alice.ID, charlie.ID)
+	env.stores.User.Follow(ctx, bob.ID, charlie.ID)
+	postID := env.createPost(t, alice, "Sunset")
+	path := "/post/" + strconv.Itoa(postID)
+
+	likes := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{name: "bob likes", method: http.MethodPost, token: bob.Token, want: http.StatusNoContent},
+		{name: "bob likes again", method: http.MethodPost, token: bob.Token, want: http.StatusNoContent},
+		{name: "alice likes own post", method: http.MethodPost, token: alice.Token, want: http.StatusNoContent},
+		{name: "alice unlikes", method: http.MethodDelete, token: alice.Token, want: http.StatusNoContent},
+	}
+	for _, tt := range likes {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, path+"/like", tt.token, nil), tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, path, bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	detail := testutils.Decode[models.Response[models.PostDetail]](t, rec).Data
+	if detail.Likes != 1 || detail.ViewerState == nil || !detail.LikedByMe || detail.IsOwner {
+		t.Fatalf("detail for bob = %+v %+v, want one like by bob", detail, detail.ViewerState)
+	}
+
+	// viewer state tidak ikut ter-cache: alice melihat dari cache yang sama
+	rec = env.DoJSON(http.MethodGet, path, alice.Token, nil)
+	detail = testutils.Decode[models.Response[models.PostDetail]](t, rec).Data
+	if detail.ViewerState == nil || detail.LikedByMe || !detail.IsOwner {
+		t.Fatalf("detail for alice = %+v, want owner without like", detail.ViewerState)
+	}
+
+	rec = env.DoJSON(http.MethodGet, "/post", charlie.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	feed := testutils.Decode[models.Response[[]models.PostFeed]](t, rec).Data
+	if len(feed) != 1 || feed[0].ID != postID {
+		t.Fatalf("feed = %+v, want alice's post", feed)
+	}
+	if vs := feed[0].ViewerState; vs == nil || vs.LikedBy == nil || len(vs.LikedBy.Names) != 1 || vs.LikedBy.Names[0] != "Bob Smith" {
+		t.Fatalf("viewer state = %+v, want liked by Bob Smith", vs)
+	}
+}
+
+func TestFollowingFeedModes(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+	ctx := context.Background()
+	env.stores.User.Follow(ctx, bob.ID, alice.ID)
+	env.stores.User.Follow(ctx, charlie.ID, alice.ID)
+
+	createAt := func(author testUser, age time.Duration) int {
+		env.db.Now = func() time.Time { return time.Now().Add(-age) }
+		defer func() { env.db.Now = time.Now }()
+		return env.createPost(t, author, "post")
+	}
+	bobOld := createAt(bob, 48*time.Hour)
+	bobNew := createAt(bob, time.Hour)
+	charlieNew := createAt(charlie, 0)
+
+	// alice sering berinteraksi dengan bob
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/post/"+strconv.Itoa(bobOld)+"/like", alice.Token, nil), http.StatusNoContent)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/post/comment", alice.Token, models.CreateCommentRequest{PostID: bobOld, Comment: "Nice"}), http.StatusCreated)
+
+	tests := []struct {
+		name string
+		mode string
+		want []int
+	}{
+		{name: "latest by default", want: []int{charlieNew, bobNew, bobOld}},
+		{name: "latest", mode: "latest", want: []int{charlieNew, bobNew, bobOld}},
+		{name: "ranked by affinity", mode: "ranked", want: []int{bobNew, charlieNew, bobOld}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/post"
+			if tt.mode != "" {
+				path += "?mode=" + tt.mode
+			}
+			rec := env.DoJSON(http.MethodGet, path, alice.Token, nil)
+			testutils.ExpectStatus(t, rec, http.StatusOK)
+			feed := testutils.Decode[models.Response[[]models.PostFeed]](t, rec).Data
+			got := make([]int, len(feed))
+			for i, p := range feed {
+				got[i] = p.ID
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("feed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid mode", func(t *testing.T) {
+		testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/post?mode=popular", alice.Token, nil), http.StatusBadRequest)
+	})
+}
+
+func TestSavePosts(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	first := env.createPost(t, alice, "First")
+	second := env.createPost(t, alice, "Second")
+
+	steps := []struct {
+		name   string
+		method string
+		postID int
+		want   int
+	}{
+		{name: "save first", method: http.MethodPost, postID: first, want: http.StatusNoContent},
+		{name: "save second", method: http.MethodPost, postID: second, want: http.StatusNoContent},
+		{name: "save twice", method: http.MethodPost, postID: second, want: http.StatusNoContent},
+		{name: "save missing post", method: http.MethodPost, postID: 9999, want: http.StatusNotFound},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, "/post/"+strconv.Itoa(tt.postID)+"/save", bob.Token, nil), tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/post/saved?limit=1", bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	page := testutils.Decode[models.Response[models.Page[models.SavedPost]]](t, rec).Data
+	if len(page.Items) != 1 || page.Items[0].ID != second || page.NextCursor == "" {
+		t.Fatalf("first page = %+v, want latest save and a cursor", page)
+	}
+	if vs := page.Items[0].ViewerState; vs == nil || !vs.SavedByMe {
+		t.Fatalf("viewer state = %+v, want saved_by_me", vs)
+	}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodDelete, "/post/"+strconv.Itoa(second)+"/save", bob.Token, nil), http.StatusNoContent)
+	rec = env.DoJSON(http.MethodGet, "/post/saved", bob.Token, nil)
+	page = testutils.Decode[models.Response[models.Page[models.SavedPost]]](t, rec).Data
+	if len(page.Items) != 1 || page.Items[0].ID != first {
+		t.Fatalf("saved after unsave = %+v, want first only", page.Items)
+	}
+}
+
+func TestComments(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	postID := env.createPost(t, alice, "Sunset")
+
+	tests := []struct {
+		name      string
+		body      any
+		want      int
+		wantField string
+	}{
+		{name: "valid", body: models.CreateCommentRequest{PostID: postID, Comment: "Keren banget!"}, want: http.StatusCreated},
+		{name: "malformed body", body: "not an object", want: http.StatusBadRequest},
+		{name: "empty comment", body: models.CreateCommentRequest{PostID: postID}, want: http.StatusBadRequest, wantField: "comment"},
+		{name: "blank comment", body: models.CreateCommentRequest{PostID: postID, Comment: "   "}, want: http.StatusBadRequest, wantField: "comment"},
+		{name: "comment too long", body: models.CreateCommentRequest{PostID: postID, Comment: strings.Repeat("a", 1001)}, want: http.StatusBadRequest, wantField: "comment"},
+		{name: "missing post id", body: map[string]string{"comment": "hai"}, want: http.StatusBadRequest, wantField: "post_id"},
+		{name: "unknown post", body: models.CreateCommentRequest{PostID: 999, Comment: "hai"}, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoJSON(http.MethodPost, "/post/comment", bob.Token, tt.body)
+			testutils.ExpectStatus(t, rec, tt.want)
+			if tt.wantField != "" {
+				if res := testutils.Decode[models.ErrorResponse](t, rec); len(res.Details) != 1 || res.Details[0].Field != tt.wantField {
+					t.Fatalf("details = %+v, want error on %s", res.Details, tt.wantField)
+				}
+			}
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/post/"+strconv.Itoa(postID)+"/comment", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	comments := testutils.Decode[models.Response[[]models.Comment]](t, rec).Data
+	if len(comments) != 1 || comments[0].AccountID != bob.ID {
+		t.Fatalf("comments = %+v, want bob's comment", comments)
+	}
+
+	rec = env.DoJSON(http.MethodGet, "/post/"+strconv.Itoa(postID), bob.Token, nil)
+	if vs := testutils.Decode[models.Response[models.PostDetail]](t, rec).Data.ViewerState; vs == nil || !vs.CommentedByMe {
+		t.Fatalf("viewer state = %+v, want commented_by_me", vs)
+	}
+}
+
+func TestPostDetailNotFound(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	rec := env.DoJSON(http.MethodGet, "/post/999", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusNotFound)
+	if res := testutils.Decode[models.ErrorResponse](t, rec); res.ErrorCode != "post_not_found" {
+		t.Fatalf("error_code = %q, want post_not_found", res.ErrorCode)
+	}
+}
+
+func TestPostIDValidation(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+		code   string
+	}{
+		{name: "like non numeric id", method: http.MethodPost, path: "/post/abc/like", want: http.StatusBadRequest, code: "validation_failed"},
+		{name: "like post zero", method: http.MethodPost, path: "/post/0/like", want: http.StatusBadRequest, code: "validation_failed"},
+		{name: "like unknown post", method: http.MethodPost, path: "/post/999/like", want: http.StatusNotFound, code: "post_not_found"},
+		{name: "unlike non numeric id", method: http.MethodDelete, path: "/post/abc/like", want: http.StatusBadRequest, code: "validation_failed"},
+		{name: "comments of unknown post", method: http.MethodGet, path: "/post/999/comment", want: http.StatusNotFound, code: "post_not_found"},
+		{name: "save non numeric id", method: http.MethodPost, path: "/post/abc/save", want: http.StatusBadRequest, code: "validation_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoJSON(tt.method, tt.path, alice.Token, nil)
+			testutils.ExpectStatus(t, rec, tt.want)
+			if res := testutils.Decode[models.ErrorResponse](t, rec); res.ErrorCode != tt.code {
+				t.Fatalf("error_code = %q, want %q", res.ErrorCode, tt.code)
+			}
+		})
+	}
+}
+
+func TestFollowingFeedPagination(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	env.stores.User.Follow(context.Background(), bob.ID, alice.ID)
+
+	// semua post di mikrodetik yang sama, urutan ditentukan id
+	at := time.Now().Add(-time.Hour)
+	env.db.Now = func() time.Time { return at }
+	for range 5 {
+		env.createPost(t, bob, "post")
+	}
+	env.db.Now = time.Now
+
+	for _, mode := range []string{"latest", "ranked"} {
+		t.Run(mode, func(t *testing.T) {
+			rec := env.DoJSON(http.MethodGet, "/v1/post?mode="+mode, alice.Token, nil)
+			testutils.ExpectStatus(t, rec, http.StatusOK)
+			want := postIDs(testutils.Decode[models.Response[[]models.PostFeed]](t, rec).Data)
+
+			pages := v2Pages[models.PostFeed](t, env, "/v2/posts?limit=2&mode="+mode, alice.Token)
+			var got []int
+			for _, page := range pages {
+				got = append(got, postIDs(page)...)
+			}
+			if len(pages) != 3 || !slices.Equal(got, want) {
+				t.Fatalf("pages = %v, want %v in 3 pages", got, want)
+			}
+		})
+	}
+
+	// feed kosong tetap berbentuk halaman
+	if pages := v2Pages[models.PostFeed](t, env, "/v2/posts?limit=2", bob.Token); len(pages) != 1 || len(pages[0]) != 0 {
+		t.Fatalf("empty feed pages = %v, want one empty page", pages)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid cursor", query: "cursor=not-a-cursor"},
+		{name: "invalid mode", query: "mode=popular"},
+		// post cursor sudah tidak ada di feed ranked
+		{name: "ranked cursor left the feed", query: "mode=ranked&cursor=" + utils.EncodeCursor(at, 999)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/v2/posts?"+tt.query, alice.Token, nil), http.StatusBadRequest)
+		})
+	}
+}
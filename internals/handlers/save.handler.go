@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+// Tempel status viewer (liked/saved/owner) ke feed setelah data bersama diambil
+// dari cache/DB. Overlay tidak pernah di-cache; jika gagal, response tetap
+// dikirim tanpa status viewer.
+func (h *PostHandler) applyViewerState(ctx context.Context, uid int, posts []models.PostFeed) {
+	if len(posts) == 0 {
+		return
+	}
+	ids := make([]int, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	states, err := h.repo.GetViewerStates(ctx, uid, ids)
+	if err != nil {
+		utils.Logger(ctx).Warn("Failed to get viewer state", "error", err)
+		return
+	}
+	for i := range posts {
+		posts[i].ViewerState = states[posts[i].ID]
+	}
+}
+
+// Status viewer untuk satu post (post detail)
+func (h *PostHandler) viewerStateFor(ctx context.Context, uid, postID int) *models.ViewerState {
+	states, err := h.repo.GetViewerStates(ctx, uid, []int{postID})
+	if err != nil {
+		utils.Logger(ctx).Warn("Failed to get viewer state", "error", err)
+		return nil
+	}
+	return states[postID]
+}
+
+// SavePost godoc
+// @Summary Save a Post
+// @Description Bookmark a post. Saving an already saved post is a no-op.
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/post/{id}/save [post]
+func (h *PostHandler) SavePost(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	found, err := h.repo.SavePost(ctx.Request.Context(), uid, postID)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to save post", err)
+		return
+	}
+	if !found {
+		utils.HandleDomainError(ctx, repositories.ErrPostNotFound, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnsavePost godoc
+// @Summary Unsave a Post
+// @Description Remove a post from my saved posts
+// @Tags Posts
+// @Security BearerAuth
+// @Param id path int true "Post ID"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/post/{id}/save [delete]
+func (h *PostHandler) UnsavePost(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	postID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.UnsavePost(ctx.Request.Context(), uid, postID); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to unsave post", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetSavedPosts godoc
+// @Summary Get Saved Posts
+// @Description My saved posts, most recently saved first
+// @Tags Posts
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/post/saved [get]
+func (h *PostHandler) GetSavedPosts(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	limit := utils.ParseLimit(ctx.Query("limit"))
+	var cursorTime *time.Time
+	cursorID := 0
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		if cursorTime, cursorID, err = utils.DecodeCursor(cursor); err != nil {
+			utils.HandleError(ctx, http.StatusBadRequest, "invalid cursor", errors.New("invalid cursor"))
+			return
+		}
+	}
+
+	saved, err := h.repo.GetSavedPosts(ctx.Request.Context(), uid, cursorTime, cursorID, limit+1)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get saved posts", err)
+		return
+	}
+
+	page := models.Page[models.SavedPost]{Items: saved}
+	if len(saved) > limit {
+		page.Items = saved[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = utils.EncodeCursor(last.SavedAt, last.ID)
+	}
+
+	feed := make([]models.PostFeed, len(page.Items))
+	for i := range page.Items {
+		feed[i] = page.Items[i].PostFeed
+	}
+	h.applyViewerState(ctx.Request.Context(), uid, feed)
+	for i := range page.Items {
+		page.Items[i].PostFeed = feed[i]
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[models.Page[models.SavedPost]]{
+		Success: true,
+		Message: "Success Get Saved Posts",
+		Data:    page,
+	})
+}
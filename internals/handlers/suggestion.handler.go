@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+// GetSuggestions godoc
+// @Summary Who to follow
+// @Description Get suggested accounts ranked by friends-of-friends, shared hashtags and engagement
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of suggestions (default 20, max 50)"
+// @Success 200 {object} models.ResponseAny
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/suggestions [get]
+func (h *UserHandler) GetSuggestions(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	rctx := ctx.Request.Context()
+	limit := min(utils.ParseLimit(ctx.Query("limit")), utils.SuggestionLimit)
+
+	ids, found, err := utils.GetSuggestionIDs(rctx, h.rdb, uid, limit)
+	if err != nil {
+		utils.Logger(ctx).Warn("Failed to get suggestions from redis", "error", err)
+	}
+
+	// belum pernah dihitung (misal akun baru), hitung langsung lalu simpan
+	if !found {
+		scores, err := h.repo.ComputeSuggestions(rctx, uid, utils.SuggestionLimit)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get suggestions", err)
+			return
+		}
+		if err := utils.SaveSuggestions(rctx, h.rdb, uid, scores, utils.SuggestionTTL); err != nil {
+			utils.Logger(ctx).Warn("Failed to save suggestions", "error", err)
+		}
+		ids = ids[:0]
+		for _, s := range scores[:min(limit, len(scores))] {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	suggestions := []models.Suggestion{}
+	if len(ids) > 0 {
+		profiles, err := h.repo.GetSuggestedProfiles(rctx, uid, ids)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to get suggestions", err)
+			return
+		}
+
+		// urutkan sesuai ranking
+		byID := make(map[int]models.Suggestion, len(profiles))
+		for _, p := range profiles {
+			byID[p.ID] = p
+		}
+		for _, id := range ids {
+			if p, ok := byID[id]; ok {
+				suggestions = append(suggestions, p)
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[[]models.Suggestion]{
+		Success: true,
+		Message: "Success Get Suggestions",
+		Data:    suggestions,
+	})
+}
+
+// DismissSuggestion godoc
+// @Summary Dismiss a suggestion
+// @Description Stop suggesting this account
+// @Tags User
+// @Security BearerAuth
+// @Param id path int true "Suggested User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/suggestions/{id} [delete]
+func (h *UserHandler) DismissSuggestion(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	targetID, ok := utils.ParseIDParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if targetID == uid {
+		utils.HandleFieldError(ctx, "id", "invalid_value", "cannot dismiss yourself")
+		return
+	}
+
+	if err := h.repo.DismissSuggestion(ctx.Request.Context(), uid, targetID); err != nil {
+		utils.HandleDomainError(ctx, err, "failed to dismiss suggestion")
+		return
+	}
+	if err := utils.RemoveSuggestion(ctx.Request.Context(), h.rdb, uid, targetID); err != nil {
+		utils.Logger(ctx).Warn("Failed to remove suggestion from redis", "error", err)
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Block godoc
+// @Summary Block user
+// @Description Block a user by ID or username. Follows in both directions are removed.
+// @Tags User
+// @Security BearerAuth
+// @Param id path string true "Target User ID or username"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.ErrorResponse "Bad Request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/{id}/block [post]
+func (h *UserHandler) Block(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	targetID, ok := h.targetUserID(ctx)
+	if !ok {
+		return
+	}
+	if targetID == uid {
+		utils.HandleError(ctx, http.StatusBadRequest, "cannot block yourself", errors.New("cannot block yourself"))
+		return
+	}
+
+	if err := h.repo.Block(ctx.Request.Context(), uid, targetID); err != nil {
+		utils.HandleDomainError(ctx, err, "failed to block user")
+		return
+	}
+	for _, pair := range [][2]int{{uid, targetID}, {targetID, uid}} {
+		if err := utils.RemoveSuggestion(ctx.Request.Context(), h.rdb, pair[0], pair[1]); err != nil {
+			utils.Logger(ctx).Warn("Failed to remove suggestion from redis", "error", err)
+		}
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Unblock godoc
+// @Summary Unblock user
+// @Description Unblock a user by ID or username
+// @Tags User
+// @Security BearerAuth
+// @Param id path string true "Target User ID or username"
+// @Success 204 "No Content"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/{id}/block [delete]
+func (h *UserHandler) Unblock(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	targetID, ok := h.targetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Unblock(ctx.Request.Context(), uid, targetID); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to unblock user", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,24 +1,30 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"strconv"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntisrangga142/chat/internals/models"
 	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
 	"github.com/redis/go-redis/v9"
 )
 
 type UserHandler struct {
-	repo *repositories.UserRepository
+	repo repositories.UserStore
 	rdb  *redis.Client
 }
 
-func NewUserHandler(repo *repositories.UserRepository, rdb *redis.Client) *UserHandler {
+func NewUserHandler(repo repositories.UserStore, rdb *redis.Client) *UserHandler {
 	return &UserHandler{repo: repo, rdb: rdb}
 }
 
@@ -31,11 +37,11 @@ func NewUserHandler(repo *repositories.UserRepository, rdb *redis.Client) *UserH
 // @Success 200 {object} models.ResponseAny
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/profile [get]
+// @Router /v1/user [get]
 func (h *UserHandler) GetProfile(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "unable to get user's token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
@@ -52,12 +58,12 @@ func (h *UserHandler) GetProfile(ctx *gin.Context) {
 
 	profile, err := h.repo.GetProfile(ctx.Request.Context(), uid)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "unable get profile user", err)
-		// return
+		utils.HandleDomainError(ctx, err, "unable get profile user")
+		return
 	}
 
 	if err := utils.RenewCache(ctx.Request.Context(), h.rdb, redisKey, profile, 10); err != nil {
-		log.Println("Failed to set redis cache:", err)
+		utils.Logger(ctx).Warn("Failed to set redis cache", "error", err)
 	}
 
 	ctx.JSON(http.StatusOK, models.Response[models.Profile]{
@@ -69,60 +75,114 @@ func (h *UserHandler) GetProfile(ctx *gin.Context) {
 
 // UpdateProfile godoc
 // @Summary Update my profile
-// @Description Update profile fields (fullname, phone, and profile picture)
+// @Description Partially update the profile. Send JSON merge-patch (null clears a field) or multipart form-data with optional profile and cover images.
 // @Tags User
 // @Security BearerAuth
-// @Accept multipart/form-data
+// @Accept json,mpfd
 // @Produce json
+// @Param request body models.UpdateProfileRequest false "JSON merge-patch (application/merge-patch+json or application/json)"
 // @Param fullname formData string false "Full Name"
 // @Param phone formData string false "Phone Number"
+// @Param bio formData string false "Bio (max 160 characters)"
+// @Param website formData string false "Website URL"
+// @Param location formData string false "Location"
+// @Param pronouns formData string false "Pronouns"
+// @Param birthdate formData string false "Birthdate (YYYY-MM-DD)"
+// @Param birthdate_visibility formData string false "public, followers or private"
 // @Param img formData file false "Profile Image"
+// @Param cover formData file false "Cover Image"
 // @Success 200 {object} models.ResponseAny "Profile updated successfully"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 415 {object} models.ErrorResponse "Unsupported content type"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/profile [put]
+// @Router /v1/user [patch]
 func (h *UserHandler) UpdateProfile(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	updates := make(map[string]any)
-
-	// ambil field dari form-data (jika ada)
-	if fullname := ctx.PostForm("fullname"); fullname != "" {
-		updates["fullname"] = fullname
+	var patch map[string]*string
+	isForm := false
+	switch contentType := ctx.ContentType(); contentType {
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		isForm = true
+		patch, err = formProfilePatch(ctx)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusBadRequest, "invalid form data", err)
+			return
+		}
+	case "application/merge-patch+json", "application/json":
+		patch, err = utils.DecodeMergePatch(ctx.Request.Body)
+		if err != nil {
+			utils.HandleError(ctx, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	default:
+		utils.HandleError(ctx, http.StatusUnsupportedMediaType,
+			"use application/merge-patch+json or multipart/form-data", fmt.Errorf("unsupported content type %q", contentType))
+		return
 	}
-	if phone := ctx.PostForm("phone"); phone != "" {
-		updates["phone"] = phone
+
+	updates, err := utils.ValidateProfilePatch(patch)
+	if err != nil {
+		utils.HandleDomainError(ctx, err, "invalid profile data")
+		return
 	}
 
-	// Upload image jika ada
-	file, err := ctx.FormFile("img")
-	if err == nil {
-		destDir := "public/profile"
-		filename := fmt.Sprintf("profile_%d", uid)
-
-		path, saveErr := utils.SaveUploadedFile(ctx, file, destDir, filename)
-		if saveErr != nil {
-			utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "Upload Failed", saveErr)
+	// gambar lama dihapus dari disk jika dikosongkan lewat null
+	_, removeImg := updates["img"]
+	_, removeCover := updates["cover_img"]
+	var current *models.Profile
+	if removeImg || removeCover {
+		if current, err = h.repo.GetProfile(ctx.Request.Context(), uid); err != nil {
+			utils.HandleError(ctx, http.StatusInternalServerError, "failed to update profile", err)
 			return
 		}
+	}
 
-		updates["img"] = path
+	// Upload image jika ada
+	if isForm {
+		uploads := []struct{ field, column, dir, prefix string }{
+			{"img", "img", utils.ProfileImgDir, "profile"},
+			{"cover", "cover_img", utils.CoverImgDir, "cover"},
+		}
+		for _, up := range uploads {
+			file, err := ctx.FormFile(up.field)
+			if err != nil {
+				continue
+			}
+			path, saveErr := utils.SaveUploadedFile(ctx, file, up.dir, fmt.Sprintf("%s_%d", up.prefix, uid))
+			if saveErr != nil {
+				utils.HandleError(ctx, http.StatusBadRequest, "Upload Failed", saveErr)
+				return
+			}
+			updates[up.column] = path
+		}
 	}
 
 	// Update ke DB
 	if err := h.repo.UpdateProfile(ctx.Request.Context(), uid, updates); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Server Error", "failed to update profile", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to update profile", err)
 		return
 	}
 
+	if removeImg && updates["img"] == nil && current.Img != nil {
+		if err := utils.RemoveUploadedFile(utils.ProfileImgDir, filepath.Join(utils.ProfileImgDir, *current.Img)); err != nil {
+			utils.Logger(ctx).Warn("Failed to remove profile image", "error", err)
+		}
+	}
+	if removeCover && updates["cover_img"] == nil && current.CoverImg != nil {
+		if err := utils.RemoveUploadedFile(utils.CoverImgDir, filepath.Join(utils.CoverImgDir, *current.CoverImg)); err != nil {
+			utils.Logger(ctx).Warn("Failed to remove cover image", "error", err)
+		}
+	}
+
 	var redisKey = fmt.Sprintf("Chat-Profile-%d", uid)
 	if err := utils.InvalidateCache(ctx, h.rdb, redisKey); err != nil {
-		log.Println("Failed invalidate cache:", err)
+		utils.Logger(ctx).Warn("Failed invalidate cache", "error", err)
 	}
 
 	ctx.JSON(http.StatusOK, models.Response[any]{
@@ -131,41 +191,72 @@ func (h *UserHandler) UpdateProfile(ctx *gin.Context) {
 	})
 }
 
+// formProfilePatch membaca field profile dari form-data, field kosong diabaikan
+func formProfilePatch(ctx *gin.Context) (map[string]*string, error) {
+	if err := ctx.Request.ParseMultipartForm(8 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+
+	patch := make(map[string]*string)
+	for field, values := range ctx.Request.PostForm {
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		patch[field] = &values[0]
+	}
+	return patch, nil
+}
+
 // Follow godoc
 // @Summary Follow user
-// @Description Follow another user by ID
+// @Description Follow another user by ID or username
 // @Tags User
 // @Security BearerAuth
-// @Param id path int true "Target User ID"
+// @Param id path string true "Target User ID or username"
 // @Produce json
 // @Success 201 {object} models.ResponseAny "Success Followed"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Blocked"
+// @Failure 404 {object} models.ErrorResponse "User not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/{id}/follow [post]
+// @Router /v1/user/{id} [post]
 func (h *UserHandler) Follow(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	targetID, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "invalid id", err)
+	targetID, ok := h.targetUserID(ctx)
+	if !ok {
 		return
 	}
 
 	if targetID == uid {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "cannot follow yourself", nil)
+		utils.HandleError(ctx, http.StatusBadRequest, "cannot follow yourself", nil)
+		return
+	}
+
+	blocked, err := h.repo.IsBlocked(ctx.Request.Context(), uid, targetID)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to follow user", err)
+		return
+	}
+	if blocked {
+		utils.HandleDomainError(ctx, repositories.ErrUserBlocked, "")
 		return
 	}
 
 	if err := h.repo.Follow(ctx, targetID, uid); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to follow user", err)
+		utils.HandleDomainError(ctx, err, "failed to follow user")
 		return
 	}
 
+	if err := utils.RemoveSuggestion(ctx.Request.Context(), h.rdb, uid, targetID); err != nil {
+		utils.Logger(ctx).Warn("Failed to remove suggestion from redis", "error", err)
+	}
+
 	ctx.JSON(http.StatusCreated, models.Response[any]{
 		Success: true,
 		Message: "Success Followed",
@@ -174,30 +265,29 @@ func (h *UserHandler) Follow(ctx *gin.Context) {
 
 // Unfollow godoc
 // @Summary Unfollow user
-// @Description Unfollow another user by ID
+// @Description Unfollow another user by ID or username
 // @Tags User
 // @Security BearerAuth
-// @Param id path int true "Target User ID"
+// @Param id path string true "Target User ID or username"
 // @Success 204 "No Content"
 // @Failure 400 {object} models.ErrorResponse "Bad Request"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/{id}/unfollow [delete]
+// @Router /v1/user/{id} [delete]
 func (h *UserHandler) Unfollow(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	targetID, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		utils.HandleError(ctx, http.StatusBadRequest, "Bad Request", "invalid id", err)
+	targetID, ok := h.targetUserID(ctx)
+	if !ok {
 		return
 	}
 
 	if err := h.repo.Unfollow(ctx, targetID, uid); err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to unfollow user", err)
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to unfollow user", err)
 		return
 	}
 
@@ -206,60 +296,241 @@ func (h *UserHandler) Unfollow(ctx *gin.Context) {
 
 // GetFollowers godoc
 // @Summary Get followers
-// @Description Get list of users who follow me
+// @Description Get users who follow me, newest first, with cursor pagination and name search
 // @Tags User
 // @Security BearerAuth
 // @Produce json
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/followers [get]
+// @Router /v1/user/follower [get]
 func (h *UserHandler) GetFollowers(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	h.respondFollowList(ctx, h.repo.GetFollowers, uid, uid, "Success Get Followers")
+}
+
+// GetFollowing godoc
+// @Summary Get following
+// @Description Get users I follow, newest first, with cursor pagination and name search
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search by name or username"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} models.ResponseAny
+// @Failure 400 {object} models.ErrorResponse "Invalid cursor"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/following [get]
+func (h *UserHandler) GetFollowing(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	followers, err := h.repo.GetFollowers(ctx, uid)
+	h.respondFollowList(ctx, h.repo.GetFollowing, uid, uid, "Success Get Followings")
+}
+
+// masa tenggang sebelum akun dihapus permanen
+const deletionGracePeriod = 30 * 24 * time.Hour
+
+// Deactivate godoc
+// @Summary Deactivate my account
+// @Description Temporarily hide the profile, posts, comments and likes. Logging in again reactivates the account.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.DeleteAccountRequest false "Password confirmation"
+// @Success 200 {object} models.ResponseAny "Account deactivated"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/deactivate [post]
+func (h *UserHandler) Deactivate(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to get followers", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
+	var req models.DeleteAccountRequest
+	// body boleh kosong untuk akun tanpa password (login OIDC)
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if ok := h.confirmPassword(ctx, uid, req.Password); !ok {
+		return
+	}
+
+	if err := h.repo.Deactivate(ctx.Request.Context(), uid); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to deactivate account", err)
+		return
+	}
+
+	if err := utils.RevokeSessions(ctx.Request.Context(), h.rdb, uid); err != nil {
+		utils.Logger(ctx).Warn("Failed to revoke sessions", "error", err)
+	}
+
 	ctx.JSON(http.StatusOK, models.Response[any]{
 		Success: true,
-		Message: "Success Get Followers",
-		Data:    followers,
+		Message: "Account deactivated, login again to reactivate it",
 	})
 }
 
-// GetFollowing godoc
-// @Summary Get following
-// @Description Get list of users I follow
+// DeleteAccount godoc
+// @Summary Delete my account
+// @Description Schedule the account for permanent deletion after a grace period. Logging in again cancels the deletion.
 // @Tags User
 // @Security BearerAuth
+// @Accept json
 // @Produce json
-// @Success 200 {object} models.ResponseAny
+// @Param request body models.DeleteAccountRequest false "Password confirmation"
+// @Success 200 {object} models.ResponseAny "Deletion scheduled"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
-// @Router /users/following [get]
-func (h *UserHandler) GetFollowing(ctx *gin.Context) {
+// @Router /v1/user [delete]
+func (h *UserHandler) DeleteAccount(ctx *gin.Context) {
 	uid, err := utils.GetUserIDFromJWT(ctx)
 	if err != nil {
-		utils.HandleError(ctx, http.StatusUnauthorized, "Unauthorized", "invalid token", err)
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
 		return
 	}
 
-	followings, err := h.repo.GetFollowing(ctx, uid)
-	if err != nil {
-		utils.HandleError(ctx, http.StatusInternalServerError, "Internal Error", "failed to get following", err)
+	var req models.DeleteAccountRequest
+	// body boleh kosong untuk akun tanpa password (login OIDC)
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if ok := h.confirmPassword(ctx, uid, req.Password); !ok {
+		return
+	}
+
+	deleteAfter := time.Now().Add(deletionGracePeriod)
+	if err := h.repo.ScheduleDeletion(ctx.Request.Context(), uid, deleteAfter); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to delete account", err)
 		return
 	}
 
+	if err := utils.RevokeSessions(ctx.Request.Context(), h.rdb, uid); err != nil {
+		utils.Logger(ctx).Warn("Failed to revoke sessions", "error", err)
+	}
+
 	ctx.JSON(http.StatusOK, models.Response[any]{
 		Success: true,
-		Message: "Success Get Followings",
-		Data:    followings,
+		Message: fmt.Sprintf("Account will be permanently deleted on %s, login again before that date to cancel", deleteAfter.Format(time.DateOnly)),
+	})
+}
+
+// ExportData godoc
+// @Summary Export my data
+// @Description Build a ZIP of profile, posts, media, comments, likes and follows and return a signed download link
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} models.ResponseAny "Export link"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/export [post]
+func (h *UserHandler) ExportData(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	data, err := h.repo.GetExportData(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to collect data", err)
+		return
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to create export", err)
+		return
+	}
+	filename := fmt.Sprintf("%d-%s.zip", uid, hex.EncodeToString(b))
+
+	if err := utils.BuildExportZip(data, filepath.Join(utils.ExportDir, filename)); err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to create export", err)
+		return
+	}
+
+	expiresAt := time.Now().Add(utils.ExportLinkTTL)
+	query, err := utils.SignDownload(filename, expiresAt)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to sign download link", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.Response[models.ExportLink]{
+		Success: true,
+		Message: "Export is ready",
+		Data: models.ExportLink{
+			URL:       ctx.FullPath() + "/download?" + query,
+			ExpiresAt: expiresAt,
+		},
 	})
 }
+
+// DownloadExport godoc
+// @Summary Download data export
+// @Description Download the export ZIP through a signed, expiring link
+// @Tags User
+// @Produce application/zip
+// @Param file query string true "File name"
+// @Param expires query int true "Expiry (unix)"
+// @Param sig query string true "Signature"
+// @Success 200 {file} file
+// @Failure 403 {object} models.ErrorResponse "Invalid or expired link"
+// @Failure 404 {object} models.ErrorResponse "Export not found"
+// @Router /v1/user/export/download [get]
+func (h *UserHandler) DownloadExport(ctx *gin.Context) {
+	file := ctx.Query("file")
+	if err := utils.VerifyDownload(file, ctx.Query("expires"), ctx.Query("sig")); err != nil {
+		utils.HandleError(ctx, http.StatusForbidden, "invalid or expired download link", err)
+		return
+	}
+
+	path := filepath.Join(utils.ExportDir, filepath.Base(file))
+	if _, err := os.Stat(path); err != nil {
+		utils.HandleError(ctx, http.StatusNotFound, "export not found", err)
+		return
+	}
+
+	ctx.FileAttachment(path, "export.zip")
+}
+
+// confirmPassword wajib untuk akun yang punya password, menulis response error jika gagal
+func (h *UserHandler) confirmPassword(ctx *gin.Context, uid int, password string) bool {
+	hashedPassword, err := h.repo.GetAccountPassword(ctx.Request.Context(), uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "user not found", err)
+		return false
+	}
+	if hashedPassword == "" {
+		return true
+	}
+
+	match, err := pkg.NewHashConfig().ComparePasswordAndHash(password, hashedPassword)
+	if err != nil || !match {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid password", fmt.Errorf("invalid password: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,320 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+func TestGetAndUpdateProfile(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "merge patch", contentType: "application/merge-patch+json", body: `{"bio":"Coffee and code","website":"alice.dev"}`, want: http.StatusOK},
+		{name: "invalid field value", contentType: "application/json", body: `{"birthdate":"2999-01-01"}`, want: http.StatusBadRequest},
+		{name: "unknown field", contentType: "application/json", body: `{"email":"x@example.com"}`, want: http.StatusBadRequest},
+		{name: "unsupported media type", contentType: "text/plain", body: `bio=x`, want: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.Do(http.MethodPatch, "/user", alice.Token, strings.NewReader(tt.body), tt.contentType)
+			testutils.ExpectStatus(t, rec, tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/user", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	profile := testutils.Decode[models.Response[models.Profile]](t, rec).Data
+	if profile.Bio == nil || *profile.Bio != "Coffee and code" {
+		t.Fatalf("bio = %v, want updated bio", profile.Bio)
+	}
+	if profile.Website == nil || *profile.Website != "https://alice.dev" {
+		t.Fatalf("website = %v, want normalized url", profile.Website)
+	}
+}
+
+func TestFollowAndLists(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+
+	follows := []struct {
+		name   string
+		method string
+		token  string
+		target string
+		want   int
+	}{
+		{name: "follow by id", method: http.MethodPost, token: alice.Token, target: strconv.Itoa(bob.ID), want: http.StatusCreated},
+		{name: "follow by handle", method: http.MethodPost, token: charlie.Token, target: "bob.smith", want: http.StatusCreated},
+		{name: "follow again is idempotent", method: http.MethodPost, token: charlie.Token, target: "bob.smith", want: http.StatusCreated},
+		{name: "follow back", method: http.MethodPost, token: bob.Token, target: "alice", want: http.StatusCreated},
+		{name: "charlie follows alice", method: http.MethodPost, token: charlie.Token, target: "alice", want: http.StatusCreated},
+		{name: "unknown handle", method: http.MethodPost, token: alice.Token, target: "nobody", want: http.StatusNotFound},
+		{name: "unknown id", method: http.MethodPost, token: alice.Token, target: "999", want: http.StatusNotFound},
+	}
+	for _, tt := range follows {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, "/user/"+tt.target, tt.token, nil), tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/user/follower", bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	page := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data
+	if len(page.Items) != 2 {
+		t.Fatalf("bob has %d followers, want 2", len(page.Items))
+	}
+	for _, f := range page.Items {
+		if f.ID == alice.ID && (!f.IsFollowing || !f.FollowsYou) {
+			t.Fatalf("alice row = %+v, want mutual follow flags", f)
+		}
+	}
+
+	// pagination satu per halaman
+	rec = env.DoJSON(http.MethodGet, "/user/follower?limit=1", bob.Token, nil)
+	first := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data
+	if len(first.Items) != 1 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v, want one item and a cursor", first)
+	}
+	rec = env.DoJSON(http.MethodGet, "/user/follower?limit=1&cursor="+url.QueryEscape(first.NextCursor), bob.Token, nil)
+	second := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data
+	if len(second.Items) != 1 || second.NextCursor != "" || second.Items[0].ID == first.Items[0].ID {
+		t.Fatalf("second page = %+v, want the other follower and no cursor", second)
+	}
+
+	// search
+	rec = env.DoJSON(http.MethodGet, "/user/follower?q=char", bob.Token, nil)
+	if items := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data.Items; len(items) != 1 || items[0].ID != charlie.ID {
+		t.Fatalf("search result = %+v, want charlie", items)
+	}
+
+	// charlie mengikuti bob, dan bob mengikuti alice
+	rec = env.DoJSON(http.MethodGet, "/user/alice/mutual", charlie.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	mutual := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data
+	if len(mutual.Items) != 1 || mutual.Items[0].ID != bob.ID || mutual.Total == nil || *mutual.Total != 1 {
+		t.Fatalf("mutual = %+v, want bob only", mutual)
+	}
+
+	// unfollow (soft delete) mengeluarkan dari list
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodDelete, "/user/bob.smith", charlie.Token, nil), http.StatusNoContent)
+	rec = env.DoJSON(http.MethodGet, "/user/follower", bob.Token, nil)
+	if items := testutils.Decode[models.Response[models.Page[models.Follow]]](t, rec).Data.Items; len(items) != 1 {
+		t.Fatalf("bob has %d followers after unfollow, want 1", len(items))
+	}
+}
+
+func TestFollowListPrivacy(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+	ctx := context.Background()
+
+	if err := env.stores.User.Follow(ctx, alice.ID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		visibility string
+		viewer     testUser
+		want       int
+	}{
+		{name: "public", visibility: "public", viewer: charlie, want: http.StatusOK},
+		{name: "followers only, follower", visibility: "followers", viewer: bob, want: http.StatusOK},
+		{name: "followers only, stranger", visibility: "followers", viewer: charlie, want: http.StatusForbidden},
+		{name: "private, follower", visibility: "private", viewer: bob, want: http.StatusForbidden},
+		{name: "private, owner", visibility: "private", viewer: alice, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := env.stores.User.UpdateProfile(ctx, alice.ID, map[string]any{"follow_list_visibility": tt.visibility}); err != nil {
+				t.Fatal(err)
+			}
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/user/alice/followers", tt.viewer.Token, nil), tt.want)
+		})
+	}
+}
+
+func TestBlockPreventsFollow(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "bob follows alice", method: http.MethodPost, path: "/user/alice", token: bob.Token, want: http.StatusCreated},
+		{name: "alice blocks bob", method: http.MethodPost, path: "/user/bob.smith/block", token: alice.Token, want: http.StatusNoContent},
+		{name: "bob cannot follow", method: http.MethodPost, path: "/user/alice", token: bob.Token, want: http.StatusForbidden},
+		{name: "alice cannot follow", method: http.MethodPost, path: "/user/bob.smith", token: alice.Token, want: http.StatusForbidden},
+		{name: "alice unblocks bob", method: http.MethodDelete, path: "/user/bob.smith/block", token: alice.Token, want: http.StatusNoContent},
+		{name: "bob follows again", method: http.MethodPost, path: "/user/alice", token: bob.Token, want: http.StatusCreated},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, tt.path, tt.token, nil), tt.want)
+		})
+	}
+
+	// block menghapus follow lama, follow baru dibuat ulang
+	profile, err := env.stores.User.GetProfile(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.FollowerCount != 1 {
+		t.Fatalf("alice follower count = %d, want 1", profile.FollowerCount)
+	}
+}
+
+func TestPurgedAccountIsInactive(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	bobID := strconv.Itoa(bob.ID)
+
+	// akun "Deleted User" setelah purge tidak lagi punya delete_after
+	if _, err := env.stores.User.PurgeAccount(context.Background(), bob.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "follow purged account", method: http.MethodPost, path: "/user/" + bobID, want: http.StatusNotFound},
+		{name: "followers of purged account", method: http.MethodGet, path: "/user/" + bobID + "/followers", want: http.StatusNotFound},
+		{name: "block purged account", method: http.MethodPost, path: "/user/" + bobID + "/block", want: http.StatusNotFound},
+		{name: "dismiss purged account", method: http.MethodDelete, path: "/user/suggestions/" + bobID, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, tt.path, alice.Token, nil), tt.want)
+		})
+	}
+
+	// job suggestion tidak memproses akun yang sudah di-purge
+	ids, err := env.stores.User.GetActiveAccountIDs(context.Background(), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		if id == bob.ID {
+			t.Fatalf("active account ids = %v, want purged account %d excluded", ids, bob.ID)
+		}
+	}
+}
+
+func TestChangeUsernameAndRedirect(t *testing.T) {
+	env := newTestEnv(t)
+	// username awal dibuat sebelum cooldown berlaku
+	env.db.Now = func() time.Time { return time.Now().Add(-31 * 24 * time.Hour) }
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	env.db.Now = time.Now
+
+	tests := []struct {
+		name     string
+		token    string
+		username string
+		want     int
+	}{
+		{name: "invalid", token: alice.Token, username: "a", want: http.StatusBadRequest},
+		{name: "reserved", token: alice.Token, username: "admin", want: http.StatusBadRequest},
+		{name: "taken case-insensitive", token: alice.Token, username: "Bob.Smith", want: http.StatusConflict},
+		{name: "valid", token: alice.Token, username: "alice.j", want: http.StatusOK},
+		{name: "cooldown", token: alice.Token, username: "alice.k", want: http.StatusTooManyRequests},
+		{name: "old handle on hold", token: bob.Token, username: "alice", want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodPatch, "/user/username", tt.token, models.ChangeUsernameRequest{Username: tt.username}), tt.want)
+		})
+	}
+
+	rec := env.DoJSON(http.MethodGet, "/user/by-handle/alice", bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusTemporaryRedirect)
+	if loc := rec.Header().Get("Location"); loc != "/user/by-handle/alice.j" {
+		t.Fatalf("Location = %q, want redirect to the new handle", loc)
+	}
+
+	rec = env.DoJSON(http.MethodGet, "/user/by-handle/ALICE.J", bob.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	if p := testutils.Decode[models.Response[models.PublicProfile]](t, rec).Data; p.ID != alice.ID {
+		t.Fatalf("profile id = %d, want %d", p.ID, alice.ID)
+	}
+}
+
+// setiap segmen statis di bawah /user dan /users harus dicadangkan, kalau
+// tidak user dengan handle itu tertutup oleh route statis
+func TestReservedUsernamesCoverUserRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	router := env.Router.(*gin.Engine)
+
+	for _, route := range router.Routes() {
+		segments := strings.Split(strings.TrimPrefix(route.Path, "/"), "/")
+		for i, segment := range segments[:len(segments)-1] {
+			if segment != "user" && segment != "users" {
+				continue
+			}
+			for _, next := range segments[i+1:] {
+				if next == "" || strings.HasPrefix(next, ":") || strings.HasPrefix(next, "*") {
+					continue
+				}
+				if err := utils.ValidateUsername(next); err == nil {
+					t.Errorf("%s %s: handle %q is allowed but shadowed by a static route", route.Method, route.Path, next)
+				}
+			}
+			break
+		}
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@example.com", "alice", "Alice Johnson")
+	bob := env.createUser(t, "bob@example.com", "bob.smith", "Bob Smith")
+	charlie := env.createUser(t, "charlie@example.com", "charlie", "Charlie Brown")
+	ctx := context.Background()
+
+	// alice -> bob -> charlie: charlie adalah friend-of-friend alice
+	env.stores.User.Follow(ctx, bob.ID, alice.ID)
+	env.stores.User.Follow(ctx, charlie.ID, bob.ID)
+
+	rec := env.DoJSON(http.MethodGet, "/user/suggestions", alice.Token, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	suggestions := testutils.Decode[models.Response[[]models.Suggestion]](t, rec).Data
+	if len(suggestions) != 1 || suggestions[0].ID != charlie.ID || suggestions[0].MutualCount != 1 {
+		t.Fatalf("suggestions = %+v, want charlie with one mutual", suggestions)
+	}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodDelete, "/user/suggestions/"+strconv.Itoa(charlie.ID), alice.Token, nil), http.StatusNoContent)
+	rec = env.DoJSON(http.MethodGet, "/user/suggestions", alice.Token, nil)
+	if suggestions := testutils.Decode[models.Response[[]models.Suggestion]](t, rec).Data; len(suggestions) != 0 {
+		t.Fatalf("suggestions after dismiss = %+v, want none", suggestions)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+const (
+	// jeda minimal antar penggantian username
+	usernameCooldown = 30 * 24 * time.Hour
+	// handle lama ditahan untuk pemiliknya selama ini sebelum bisa diklaim akun lain
+	usernameHoldPeriod = 90 * 24 * time.Hour
+)
+
+// ChangeUsername godoc
+// @Summary Set or change my username
+// @Description Set a unique, case-insensitive handle. After the first change a cooldown applies, and the old handle keeps redirecting to the new one.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeUsernameRequest true "New username"
+// @Success 200 {object} models.ResponseAny "Username updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid username"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 409 {object} models.ErrorResponse "Username already taken"
+// @Failure 429 {object} models.ErrorResponse "Username changed too recently"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/username [patch]
+func (h *UserHandler) ChangeUsername(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	var req models.ChangeUsernameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.HandleBindError(ctx, err)
+		return
+	}
+
+	if err := utils.ValidateUsername(req.Username); err != nil {
+		utils.HandleFieldError(ctx, "username", "invalid_format", err.Error())
+		return
+	}
+
+	rctx := ctx.Request.Context()
+	current, changedAt, err := h.repo.GetUsername(rctx, uid)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get username", err)
+		return
+	}
+
+	// hanya kapitalisasi yang berubah, tidak kena cooldown
+	if current != nil && *current == req.Username {
+		ctx.JSON(http.StatusOK, models.Response[any]{Success: true, Message: "Username unchanged"})
+		return
+	}
+	if changedAt != nil && current != nil && utils.NormalizeUsername(*current) != utils.NormalizeUsername(req.Username) {
+		if next := changedAt.Add(usernameCooldown); time.Now().Before(next) {
+			ctx.Header("Retry-After", strconv.Itoa(int(time.Until(next).Seconds())))
+			utils.HandleError(ctx, http.StatusTooManyRequests,
+				fmt.Sprintf("username can be changed again on %s", next.Format(time.DateOnly)), errors.New("username cooldown"))
+			return
+		}
+	}
+
+	taken, err := h.repo.IsUsernameTaken(rctx, uid, req.Username, usernameHoldPeriod)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to check username", err)
+		return
+	}
+	if taken {
+		utils.HandleDomainError(ctx, repositories.ErrUsernameTaken, "")
+		return
+	}
+
+	if err := h.repo.ChangeUsername(rctx, uid, req.Username); err != nil {
+		utils.HandleDomainError(ctx, err, "failed to change username")
+		return
+	}
+
+	if err := utils.InvalidateCache(rctx, h.rdb, fmt.Sprintf("Chat-Profile-%d", uid)); err != nil {
+		utils.Logger(ctx).Warn("Failed invalidate cache", "error", err)
+	}
+
+	ctx.JSON(http.StatusOK, models.Response[any]{
+		Success: true,
+		Message: "Username updated successfully",
+	})
+}
+
+// GetProfileByHandle godoc
+// @Summary Get profile by handle
+// @Description Look up a user by username (case-insensitive). Old handles redirect to the current one.
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param handle path string true "Username, with or without @"
+// @Success 200 {object} models.ResponseAny
+// @Success 307 "Redirect to the current handle"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/user/by-handle/{handle} [get]
+func (h *UserHandler) GetProfileByHandle(ctx *gin.Context) {
+	uid, err := utils.GetUserIDFromJWT(ctx)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	handle := utils.NormalizeUsername(ctx.Param("handle"))
+	rctx := ctx.Request.Context()
+
+	profile, err := h.repo.GetProfileByUsername(rctx, uid, handle)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get profile", err)
+		return
+	}
+	if profile != nil {
+		ctx.JSON(http.StatusOK, models.Response[models.PublicProfile]{
+			Success: true,
+			Message: "Success Get Profile User",
+			Data:    *profile,
+		})
+		return
+	}
+
+	current, err := h.repo.GetUsernameRedirect(rctx, handle)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get profile", err)
+		return
+	}
+	if current == "" {
+		utils.HandleDomainError(ctx, repositories.ErrUserNotFound.WithCause(fmt.Errorf("unknown handle %q", handle)), "")
+		return
+	}
+
+	// redirect ke route yang sama (/user, /v1/user atau /v2/users) dengan handle
+	// baru. Sementara, bukan 301, karena handle lama bisa diklaim user lain
+	// setelah masa tahan habis dan 301 di-cache browser.
+	location := strings.TrimSuffix(ctx.FullPath(), ":handle") + url.PathEscape(current)
+	if query := ctx.Request.URL.RawQuery; query != "" {
+		location += "?" + query
+	}
+	ctx.Redirect(http.StatusTemporaryRedirect, location)
+}
+
+// targetUserID membaca param :id yang boleh berupa id numerik atau handle,
+// menulis response error jika tidak ditemukan
+func (h *UserHandler) targetUserID(ctx *gin.Context) (int, bool) {
+	param := ctx.Param("id")
+	if id, err := strconv.Atoi(param); err == nil {
+		return id, true
+	}
+
+	id, err := h.repo.GetIDByUsername(ctx.Request.Context(), utils.NormalizeUsername(param))
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to find user", err)
+		return 0, false
+	}
+	if id == 0 {
+		utils.HandleDomainError(ctx, repositories.ErrUserNotFound.WithCause(fmt.Errorf("unknown handle %q", param)), "")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,152 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+)
+
+// bentuk response /v2 dengan data bertipe
+type envelope[T any] struct {
+	Data  T                     `json:"data"`
+	Error *models.EnvelopeError `json:"error"`
+	Meta  models.EnvelopeMeta   `json:"meta"`
+}
+
+// v2Pages mengikuti next_cursor dari halaman pertama path sampai habis dan
+// mengembalikan isi setiap halaman
+func v2Pages[T any](t *testing.T, env *testEnv, path, token string) [][]T {
+	t.Helper()
+	var pages [][]T
+	cursor := ""
+	for {
+		target := path
+		if cursor != "" {
+			target += "&cursor=" + url.QueryEscape(cursor)
+		}
+		rec := env.DoJSON(http.MethodGet, target, token, nil)
+		testutils.ExpectStatus(t, rec, http.StatusOK)
+		res := testutils.Decode[envelope[[]T]](t, rec)
+		if res.Meta.Pagination == nil {
+			t.Fatalf("%s: response has no meta.pagination", target)
+		}
+		pages = append(pages, res.Data)
+		if !res.Meta.Pagination.HasMore {
+			return pages
+		}
+		if len(pages) > 100 {
+			t.Fatalf("%s: pagination does not end", path)
+		}
+		cursor = res.Meta.Pagination.NextCursor
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@mail.com", "alice", "Alice Doe")
+
+	tests := []struct {
+		name           string
+		path           string
+		wantDeprecated bool
+	}{
+		{name: "unversioned alias", path: "/user", wantDeprecated: true},
+		{name: "v1", path: "/v1/user", wantDeprecated: true},
+		{name: "v2", path: "/v2/users/me", wantDeprecated: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.DoJSON(http.MethodGet, tt.path, alice.Token, nil)
+			testutils.ExpectStatus(t, rec, http.StatusOK)
+			if deprecated := rec.Header().Get("Deprecation") != ""; deprecated != tt.wantDeprecated {
+				t.Fatalf("deprecated = %v, want %v", deprecated, tt.wantDeprecated)
+			}
+		})
+	}
+
+	// v1 tetap memakai bentuk response lama
+	rec := env.DoJSON(http.MethodGet, "/v1/user", alice.Token, nil)
+	if res := testutils.Decode[models.Response[models.Profile]](t, rec); !res.Success || res.Data.Username == nil || *res.Data.Username != "alice" {
+		t.Fatalf("v1 profile = %+v, want alice", res)
+	}
+}
+
+func TestV2Envelope(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.createUser(t, "alice@mail.com", "alice", "Alice Doe")
+	bob := env.createUser(t, "bob@mail.com", "bob", "Bob Smith")
+	charlie := env.createUser(t, "charlie@mail.com", "charlie", "Charlie Brown")
+	postID := env.createPost(t, alice, "hello")
+
+	t.Run("login", func(t *testing.T) {
+		rec := env.DoJSON(http.MethodPost, "/v2/auth/login", "", map[string]string{"email": "alice@mail.com", "password": "Password123!"})
+		testutils.ExpectStatus(t, rec, http.StatusOK)
+		if res := testutils.Decode[envelope[models.LoginData]](t, rec); res.Data.Token == "" || res.Error != nil {
+			t.Fatalf("login = %+v, want token in data", res)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec := env.DoJSON(http.MethodGet, "/v2/posts/999", alice.Token, nil)
+		testutils.ExpectStatus(t, rec, http.StatusNotFound)
+		res := testutils.Decode[envelope[*models.PostDetail]](t, rec)
+		if res.Data != nil || res.Error == nil || res.Error.Code != "post_not_found" {
+			t.Fatalf("response = %+v, want post_not_found error", res)
+		}
+	})
+
+	t.Run("comment with post id from path", func(t *testing.T) {
+		path := "/v2/posts/" + strconv.Itoa(postID) + "/comments"
+		rec := env.DoJSON(http.MethodPost, path, bob.Token, map[string]string{"comment": "nice"})
+		testutils.ExpectStatus(t, rec, http.StatusCreated)
+		if res := testutils.Decode[envelope[any]](t, rec); res.Data != nil || res.Error != nil || res.Meta.RequestID == "" {
+			t.Fatalf("response = %+v, want empty envelope", res)
+		}
+
+		rec = env.DoJSON(http.MethodGet, path, bob.Token, nil)
+		testutils.ExpectStatus(t, rec, http.StatusOK)
+		if res := testutils.Decode[envelope[[]models.Comment]](t, rec); len(res.Data) != 1 {
+			t.Fatalf("comments = %+v, want 1", res.Data)
+		}
+
+		rec = env.DoJSON(http.MethodPost, path, bob.Token, map[string]string{"comment": " "})
+		testutils.ExpectStatus(t, rec, http.StatusBadRequest)
+		if res := testutils.Decode[envelope[any]](t, rec); res.Error == nil || len(res.Error.Details) != 1 || res.Error.Details[0].Field != "comment" {
+			t.Fatalf("response = %+v, want comment field error", res)
+		}
+	})
+
+	t.Run("pagination meta", func(t *testing.T) {
+		for _, follower := range []testUser{bob, charlie} {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/v2/users/"+strconv.Itoa(alice.ID)+"/follow", follower.Token, nil), http.StatusCreated)
+		}
+
+		rec := env.DoJSON(http.MethodGet, "/v2/users/me/followers?limit=1", alice.Token, nil)
+		testutils.ExpectStatus(t, rec, http.StatusOK)
+		first := testutils.Decode[envelope[[]models.Follow]](t, rec)
+		if len(first.Data) != 1 || first.Meta.Pagination == nil || !first.Meta.Pagination.HasMore {
+			t.Fatalf("first page = %+v, want one item with more pages", first)
+		}
+
+		rec = env.DoJSON(http.MethodGet, "/v2/users/me/followers?limit=1&cursor="+first.Meta.Pagination.NextCursor, alice.Token, nil)
+		second := testutils.Decode[envelope[[]models.Follow]](t, rec)
+		if len(second.Data) != 1 || second.Meta.Pagination == nil || second.Meta.Pagination.HasMore {
+			t.Fatalf("second page = %+v, want last page", second)
+		}
+	})
+	t.Run("old handle redirects within v2", func(t *testing.T) {
+		if err := env.stores.User.ChangeUsername(context.Background(), bob.ID, "bob.s"); err != nil {
+			t.Fatal(err)
+		}
+		rec := env.DoJSON(http.MethodGet, "/v2/users/by-handle/bob?ref=share", alice.Token, nil)
+		testutils.ExpectStatus(t, rec, http.StatusTemporaryRedirect)
+		if loc := rec.Header().Get("Location"); loc != "/v2/users/by-handle/bob.s?ref=share" {
+			t.Fatalf("Location = %q, want v2 path with the query kept", loc)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+type WellKnownHandler struct{}
+
+func NewWellKnownHandler() *WellKnownHandler {
+	return &WellKnownHandler{}
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys used to verify access tokens, for other services
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} pkg.JWKS
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(ctx *gin.Context) {
+	jwks, err := pkg.GetJWKS()
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to load keys", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwks)
+}
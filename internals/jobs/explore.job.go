@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// ExploreJob merawat skor explore di Redis: mengisi ulang saat cold start,
+// menggeser epoch decay dan membuang post yang sudah keluar window
+type ExploreJob struct {
+	repo     repositories.PostStore
+	rdb      *redis.Client
+	interval time.Duration
+}
+
+func NewExploreJob(repo repositories.PostStore, rdb *redis.Client, interval time.Duration) *ExploreJob {
+	return &ExploreJob{repo: repo, rdb: rdb, interval: interval}
+}
+
+// Start menjalankan job secara periodik sampai ctx dibatalkan
+func (j *ExploreJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *ExploreJob) Run(ctx context.Context) {
+	// hanya satu instance API yang menjalankan job dalam satu interval
+	ok, err := j.rdb.SetNX(ctx, "Lock:ExploreJob", time.Now().Unix(), j.interval).Result()
+	if err != nil {
+		slog.Error("Explore job lock failed", "job", "explore", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	_, seeded, err := utils.ExploreEpoch(ctx, j.rdb)
+	if err != nil {
+		slog.Error("Explore job failed", "job", "explore", "error", err)
+		return
+	}
+	if !seeded {
+		j.seed(ctx)
+		return
+	}
+
+	if err := utils.MaintainExplore(ctx, j.rdb); err != nil {
+		slog.Error("Explore job failed", "job", "explore", "error", err)
+	}
+}
+
+func (j *ExploreJob) seed(ctx context.Context) {
+	epoch := time.Now()
+	seeds, err := j.repo.GetExploreSeed(ctx, epoch.Add(-utils.ExploreWindow), epoch,
+		utils.ExploreTau, utils.ExploreLikeWeight, utils.ExploreCommentWeight)
+	if err != nil {
+		slog.Error("Explore seed failed", "job", "explore", "error", err)
+		return
+	}
+
+	created := make(map[int]time.Time, len(seeds))
+	scores := make(map[int]float64, len(seeds))
+	for _, s := range seeds {
+		created[s.PostID] = s.CreatedAt
+		scores[s.PostID] = s.Score
+	}
+	if err := utils.SeedExplore(ctx, j.rdb, epoch, created, scores); err != nil {
+		slog.Error("Explore seed failed", "job", "explore", "error", err)
+		return
+	}
+	slog.Info("Explore seeded", "job", "explore", "posts", len(seeds))
+}
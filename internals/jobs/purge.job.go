@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// PurgeJob menghapus permanen akun yang masa tenggangnya sudah habis
+// dan membersihkan file export yang sudah kedaluwarsa
+type PurgeJob struct {
+	repo      repositories.UserStore
+	rdb       *redis.Client
+	interval  time.Duration
+	exportTTL time.Duration
+}
+
+func NewPurgeJob(repo repositories.UserStore, rdb *redis.Client, interval, exportTTL time.Duration) *PurgeJob {
+	return &PurgeJob{repo: repo, rdb: rdb, interval: interval, exportTTL: exportTTL}
+}
+
+// Start menjalankan job secara periodik sampai ctx dibatalkan
+func (j *PurgeJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PurgeJob) Run(ctx context.Context) {
+	// hanya satu instance API yang menjalankan job dalam satu interval
+	ok, err := j.rdb.SetNX(ctx, "Lock:PurgeJob", time.Now().Unix(), j.interval).Result()
+	if err != nil {
+		slog.Error("Purge job lock failed", "job", "purge", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	ids, err := j.repo.GetAccountsDueForPurge(ctx, 100)
+	if err != nil {
+		slog.Error("Purge job failed", "job", "purge", "error", err)
+		return
+	}
+
+	for _, uid := range ids {
+		purged, err := j.repo.PurgeAccount(ctx, uid)
+		if err != nil {
+			slog.Error("Failed to purge account", "job", "purge", "account_id", uid, "error", err)
+			continue
+		}
+		if purged == nil {
+			continue
+		}
+
+		for _, img := range purged.PostImgs {
+			if err := utils.RemoveUploadedFile(utils.PostImgDir, img); err != nil {
+				slog.Warn("Failed to remove file", "job", "purge", "file", img, "error", err)
+			}
+		}
+		if purged.ProfileImg != nil {
+			if err := utils.RemoveUploadedFile(utils.ProfileImgDir, filepath.Join(utils.ProfileImgDir, *purged.ProfileImg)); err != nil {
+				slog.Warn("Failed to remove file", "job", "purge", "file", *purged.ProfileImg, "error", err)
+			}
+		}
+		if purged.CoverImg != nil {
+			if err := utils.RemoveUploadedFile(utils.CoverImgDir, filepath.Join(utils.CoverImgDir, *purged.CoverImg)); err != nil {
+				slog.Warn("Failed to remove file", "job", "purge", "file", *purged.CoverImg, "error", err)
+			}
+		}
+		j.clearCache(ctx, uid, purged)
+		slog.Info("Account purged", "job", "purge", "account_id", uid)
+	}
+
+	j.cleanExports()
+}
+
+// clearCache membuang post dan profile akun yang dipurge dari Redis supaya
+// tidak muncul lagi di explore, detail post, profile dan feed follower
+func (j *PurgeJob) clearCache(ctx context.Context, uid int, purged *models.PurgedAccount) {
+	if err := utils.UntrackExplorePosts(ctx, j.rdb, purged.PostIDs); err != nil {
+		slog.Warn("Failed to remove posts from explore", "job", "purge", "account_id", uid, "error", err)
+	}
+
+	keys := []string{fmt.Sprintf("Chat-Profile-%d", uid)}
+	for _, id := range purged.PostIDs {
+		keys = append(keys, fmt.Sprintf("Chat-PostDetail-%d", id))
+	}
+	for _, id := range purged.FollowerIDs {
+		keys = append(keys, fmt.Sprintf("Chat-ListPosts-%d", id), fmt.Sprintf("Chat-ListPosts-Ranked-%d", id))
+	}
+	if err := j.rdb.Del(ctx, keys...).Err(); err != nil {
+		slog.Warn("Failed to invalidate cache", "job", "purge", "account_id", uid, "error", err)
+	}
+}
+
+func (j *PurgeJob) cleanExports() {
+	entries, err := os.ReadDir(utils.ExportDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < j.exportTTL {
+			continue
+		}
+		if err := os.Remove(filepath.Join(utils.ExportDir, entry.Name())); err != nil {
+			slog.Warn("Failed to remove export", "job", "purge", "file", entry.Name(), "error", err)
+		}
+	}
+}
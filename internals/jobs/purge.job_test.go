@@ -0,0 +1,98 @@
+package jobs_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ntisrangga142/chat/internals/jobs"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories/memory"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPurgeClearsCache(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	stores := memory.NewStores(memory.NewDB())
+
+	register := func(email string) int {
+		t.Helper()
+		id, err := stores.Auth.Register(ctx, email, "hash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stores.Auth.VerifyEmail(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	alice, bob, carol := register("alice@example.com"), register("bob@example.com"), register("carol@example.com")
+	if err := stores.User.Follow(ctx, alice, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := stores.Post.CreatePost(ctx, models.CreatePostRequest{Caption: "hello"}, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := stores.Post.CreatePost(ctx, models.CreatePostRequest{Caption: "still here"}, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{post.ID, other.ID} {
+		if err := utils.TrackExplorePost(ctx, rdb, id, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purgedKeys := []string{
+		"Chat-Profile-" + strconv.Itoa(alice),
+		"Chat-PostDetail-" + strconv.Itoa(post.ID),
+		"Chat-ListPosts-" + strconv.Itoa(bob),
+		"Chat-ListPosts-Ranked-" + strconv.Itoa(bob),
+	}
+	keptKeys := []string{
+		"Chat-PostDetail-" + strconv.Itoa(other.ID),
+		"Chat-ListPosts-" + strconv.Itoa(carol),
+	}
+	for _, key := range append(purgedKeys, keptKeys...) {
+		mr.Set(key, "cached")
+	}
+
+	if err := stores.User.ScheduleDeletion(ctx, alice, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	jobs.NewPurgeJob(stores.User, rdb, time.Hour, time.Hour).Run(ctx)
+
+	for _, key := range purgedKeys {
+		if mr.Exists(key) {
+			t.Errorf("%s still cached after purge", key)
+		}
+	}
+	for _, key := range keptKeys {
+		if !mr.Exists(key) {
+			t.Errorf("%s removed, want unrelated cache kept", key)
+		}
+	}
+
+	ids, err := utils.GetExploreIDs(ctx, rdb, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != other.ID {
+		t.Fatalf("explore ids = %v, want only %d", ids, other.ID)
+	}
+	created, err := rdb.ZRange(ctx, "Chat-Explore-Created", 0, -1).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0] != strconv.Itoa(other.ID) {
+		t.Fatalf("explore created = %v, want only %d", created, other.ID)
+	}
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// SuggestionJob menghitung ulang saran who-to-follow semua akun aktif ke Redis
+type SuggestionJob struct {
+	repo     repositories.UserStore
+	rdb      *redis.Client
+	interval time.Duration
+}
+
+func NewSuggestionJob(repo repositories.UserStore, rdb *redis.Client, interval time.Duration) *SuggestionJob {
+	return &SuggestionJob{repo: repo, rdb: rdb, interval: interval}
+}
+
+// Start menjalankan job secara periodik sampai ctx dibatalkan
+func (j *SuggestionJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *SuggestionJob) Run(ctx context.Context) {
+	// hanya satu instance API yang menjalankan job dalam satu interval
+	ok, err := j.rdb.SetNX(ctx, "Lock:SuggestionJob", time.Now().Unix(), j.interval).Result()
+	if err != nil {
+		slog.Error("Suggestion job lock failed", "job", "suggestion", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	count, lastID := 0, 0
+	for {
+		ids, err := j.repo.GetActiveAccountIDs(ctx, lastID, 500)
+		if err != nil {
+			slog.Error("Suggestion job failed", "job", "suggestion", "error", err)
+			return
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, uid := range ids {
+			scores, err := j.repo.ComputeSuggestions(ctx, uid, utils.SuggestionLimit)
+			if err != nil {
+				slog.Error("Failed to compute suggestions", "job", "suggestion", "account_id", uid, "error", err)
+				continue
+			}
+			if err := utils.SaveSuggestions(ctx, j.rdb, uid, scores, utils.SuggestionTTL); err != nil {
+				slog.Error("Failed to save suggestions", "job", "suggestion", "account_id", uid, "error", err)
+				continue
+			}
+			count++
+		}
+		lastID = ids[len(ids)-1]
+	}
+
+	slog.Info("Suggestions computed", "job", "suggestion", "accounts", count)
+}
@@ -21,7 +21,7 @@ func Authentication(ctx *gin.Context) {
 	// ambil token dari header
 	bearerToken := ctx.GetHeader("Authorization")
 	if bearerToken == "" {
-		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", "Authorization header is missing, please login first")
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Authorization header is missing, please login first")
 		ctx.Abort()
 		return
 	}
@@ -29,14 +29,14 @@ func Authentication(ctx *gin.Context) {
 	// Bearer token
 	tokens := strings.Split(bearerToken, " ")
 	if len(tokens) != 2 {
-		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", "Invalid authorization format, expected 'Bearer <token>'")
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Invalid authorization format, expected 'Bearer <token>'")
 		ctx.Abort()
 		return
 	}
 
 	token := tokens[1]
 	if token == "" {
-		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", "Access token is missing, please login first")
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Access token is missing, please login first")
 		ctx.Abort()
 		return
 	}
@@ -44,12 +44,12 @@ func Authentication(ctx *gin.Context) {
 	// cek apakah token sudah di-blacklist
 	isBlacklisted, err := utils.IsBlacklisted(ctx, RDB, token)
 	if err != nil {
-		utils.HandleMiddlewareError(ctx, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to verify session", err)
 		ctx.Abort()
 		return
 	}
 	if isBlacklisted {
-		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", "Token has been revoked, please login again")
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Token has been revoked, please login again")
 		ctx.Abort()
 		return
 	}
@@ -58,21 +58,43 @@ func Authentication(ctx *gin.Context) {
 	var claims pkg.Claims
 	if err := claims.VerifyToken(token); err != nil {
 		if strings.Contains(err.Error(), jwt.ErrTokenInvalidIssuer.Error()) {
-			utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", jwt.ErrTokenInvalidIssuer.Error())
+			utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, jwt.ErrTokenInvalidIssuer.Error())
 			ctx.Abort()
 			return
 		}
 		if strings.Contains(err.Error(), jwt.ErrTokenExpired.Error()) {
-			utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Unauthorized Access", jwt.ErrTokenExpired.Error())
+			utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, jwt.ErrTokenExpired.Error())
 			ctx.Abort()
 			return
 		}
-		utils.HandleMiddlewareError(ctx, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		// penyebab detail (signature, kid, format) hanya dicatat di log
+		utils.HandleError(ctx, http.StatusUnauthorized, "Invalid access token", err)
 		ctx.Abort()
 		return
 	}
 
-	// simpan claims ke context
+	// token challenge 2FA bukan access token
+	if claims.Purpose != "" {
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Invalid access token")
+		ctx.Abort()
+		return
+	}
+
+	// cek apakah semua sesi user sudah dicabut (misal setelah reset password)
+	isRevoked, err := utils.IsSessionRevoked(ctx, RDB, claims.UserId, claims.SessionVersion)
+	if err != nil {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to verify session", err)
+		ctx.Abort()
+		return
+	}
+	if isRevoked {
+		utils.HandleMiddlewareError(ctx, http.StatusUnauthorized, "Session has been revoked, please login again")
+		ctx.Abort()
+		return
+	}
+
+	// simpan claims ke context, log berikutnya ikut membawa user_id
 	ctx.Set("claims", claims)
+	utils.SetRequestLogger(ctx, utils.Logger(ctx).With("user_id", claims.UserId))
 	ctx.Next()
 }
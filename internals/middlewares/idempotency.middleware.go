@@ -0,0 +1,211 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+	idempotencyLockTTL        = time.Minute
+	idempotencyReusedCode     = "idempotency_key_reused"
+	idempotencyInProgressCode = "idempotency_key_in_progress"
+	// body terbesar yang dibaca untuk fingerprint: 10 gambar post ditambah field form
+	maxIdempotentBodySize = 10*utils.MaxImageSize + 1<<20
+)
+
+// lama response disimpan, diisi dari IDEMPOTENCY_TTL
+var idempotencyTTL = defaultIdempotencyTTL
+
+// InitIdempotency memasang IDEMPOTENCY_TTL dari config
+func InitIdempotency(ttl time.Duration) {
+	idempotencyTTL = ttl
+	if ttl <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+}
+
+// response pertama yang diputar ulang untuk request dengan key yang sama
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// hapus lock hanya jika masih milik request ini
+var releaseIdempotencyLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Idempotency menyimpan response POST per user + header Idempotency-Key.
+// Request ulang dengan body yang sama mendapat response pertama, body berbeda
+// dijawab 422, dan duplikat yang datang saat request pertama masih berjalan
+// dijawab 409. Request tanpa header diproses seperti biasa.
+func Idempotency(ctx *gin.Context) {
+	key := ctx.GetHeader(IdempotencyKeyHeader)
+	if ctx.Request.Method != http.MethodPost || key == "" || RDB == nil {
+		ctx.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		utils.HandleFieldError(ctx, IdempotencyKeyHeader, "too_long", "Idempotency-Key must be at most 255 characters")
+		ctx.Abort()
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxIdempotentBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.HandleMiddlewareError(ctx, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		utils.HandleMiddlewareError(ctx, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	rctx := ctx.Request.Context()
+	fingerprint := requestFingerprint(ctx.Request, body)
+	redisKey := "Idempotency:" + KeyByUserOrIP(ctx) + ":" + key
+	lockKey := redisKey + ":lock"
+
+	stored, err := loadIdempotentResponse(rctx, redisKey)
+	if err != nil {
+		// redis bermasalah, request tetap diproses tanpa perlindungan
+		utils.Logger(ctx).Warn("Idempotency store error", "error", err)
+		ctx.Next()
+		return
+	}
+	if stored != nil {
+		replayIdempotentResponse(ctx, stored, fingerprint)
+		return
+	}
+
+	token := newRequestMember()
+	locked, err := RDB.SetNX(rctx, lockKey, token, idempotencyLockTTL).Result()
+	if err != nil {
+		utils.Logger(ctx).Warn("Idempotency store error", "error", err)
+		ctx.Next()
+		return
+	}
+	if !locked {
+		ctx.Header("Retry-After", "1")
+		abortIdempotency(ctx, http.StatusConflict, idempotencyInProgressCode, "a request with this Idempotency-Key is still being processed")
+		return
+	}
+	// request lain bisa menyelesaikan key ini tepat sebelum lock didapat
+	if stored, err := loadIdempotentResponse(rctx, redisKey); err == nil && stored != nil {
+		releaseIdempotencyLock.Run(rctx, RDB, []string{lockKey}, token)
+		replayIdempotentResponse(ctx, stored, fingerprint)
+		return
+	}
+
+	// simpan walau client sudah memutus koneksi
+	storeCtx := context.WithoutCancel(rctx)
+	// lock dilepas juga saat handler panic, supaya retry tidak dijawab 409
+	// sampai idempotencyLockTTL habis
+	defer func() {
+		if err := releaseIdempotencyLock.Run(storeCtx, RDB, []string{lockKey}, token).Err(); err != nil {
+			utils.Logger(ctx).Warn("Failed to release idempotency lock", "error", err)
+		}
+	}()
+
+	writer := &recordingWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = writer
+	ctx.Next()
+
+	status := writer.Status()
+	// 5xx dan 429 tidak disimpan supaya client bisa mencoba lagi
+	if status < http.StatusInternalServerError && status != http.StatusTooManyRequests {
+		res, _ := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err := RDB.Set(storeCtx, redisKey, res, idempotencyTTL).Err(); err != nil {
+			utils.Logger(ctx).Warn("Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+func loadIdempotentResponse(ctx context.Context, redisKey string) (*idempotentResponse, error) {
+	raw, err := RDB.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res idempotentResponse
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func replayIdempotentResponse(ctx *gin.Context, stored *idempotentResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		abortIdempotency(ctx, http.StatusUnprocessableEntity, idempotencyReusedCode, "Idempotency-Key was already used for a different request")
+		return
+	}
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Data(stored.Status, stored.ContentType, stored.Body)
+	ctx.Abort()
+}
+
+func abortIdempotency(ctx *gin.Context, code int, errorCode, message string) {
+	utils.Logger(ctx).Warn(http.StatusText(code), "status_code", code, "error_code", errorCode, "message", message)
+	res := models.NewErrorResponse(http.StatusText(code), message, code)
+	res.ErrorCode = errorCode
+	ctx.AbortWithStatusJSON(code, res)
+}
+
+// Hash method, path dan body. Boundary multipart dibuat acak oleh client
+// setiap kali request dibangun, jadi dibuang sebelum di-hash.
+func requestFingerprint(req *http.Request, body []byte) string {
+	if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter menyalin body response untuk disimpan
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,179 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// router dengan handler yang menghitung berapa kali benar-benar dijalankan
+func newIdempotentRouter(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	middlewares.InitRedis(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	t.Cleanup(func() { middlewares.InitRedis(nil) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.CustomRecovery(middlewares.Recovery), func(ctx *gin.Context) {
+		ctx.Set("claims", pkg.Claims{UserId: 1})
+	}, middlewares.Idempotency)
+	router.POST("/posts", handler)
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(t, func(ctx *gin.Context) {
+		n := calls.Add(1)
+		ctx.JSON(http.StatusCreated, gin.H{"id": n})
+	})
+
+	first := postWithKey(router, "key-1", `{"caption":"a"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201", first.Code)
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		body       string
+		want       int
+		wantCalls  int32
+		wantReplay bool
+	}{
+		{name: "same key and body replays", key: "key-1", body: `{"caption":"a"}`, want: http.StatusCreated, wantCalls: 1, wantReplay: true},
+		{name: "same key other body", key: "key-1", body: `{"caption":"b"}`, want: http.StatusUnprocessableEntity, wantCalls: 1},
+		{name: "new key runs handler", key: "key-2", body: `{"caption":"a"}`, want: http.StatusCreated, wantCalls: 2},
+		{name: "without key runs handler", key: "", body: `{"caption":"a"}`, want: http.StatusCreated, wantCalls: 3},
+		{name: "key too long", key: strings.Repeat("k", 256), body: `{}`, want: http.StatusBadRequest, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postWithKey(router, tt.key, tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("handler calls = %d, want %d", got, tt.wantCalls)
+			}
+			if replayed := rec.Header().Get(middlewares.IdempotentReplayedHeader) == "true"; replayed != tt.wantReplay {
+				t.Fatalf("replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+			if tt.wantReplay && rec.Body.String() != first.Body.String() {
+				t.Fatalf("body = %s, want %s", rec.Body.String(), first.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlightDuplicate(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	router := newIdempotentRouter(t, func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.Status(http.StatusNoContent)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(router, "key-1", `{}`) }()
+	<-started
+
+	rec := postWithKey(router, "key-1", `{}`)
+	if rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("status = %d, want 409 with Retry-After", rec.Code)
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusNoContent {
+		t.Fatalf("first status = %d, want 204", first.Code)
+	}
+	// setelah request pertama selesai, duplikat mendapat response yang disimpan
+	if rec := postWithKey(router, "key-1", `{}`); rec.Code != http.StatusNoContent || rec.Header().Get(middlewares.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("status = %d, want replayed 204", rec.Code)
+	}
+}
+
+func TestIdempotencySkipsServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(t, func(ctx *gin.Context) {
+		if calls.Add(1) == 1 {
+			ctx.JSON(http.StatusInternalServerError, models.NewErrorResponse("Internal Server Error", "boom", http.StatusInternalServerError))
+			return
+		}
+		ctx.Status(http.StatusCreated)
+	})
+
+	postWithKey(router, "key-1", `{}`)
+	if rec := postWithKey(router, "key-1", `{}`); rec.Code != http.StatusCreated || calls.Load() != 2 {
+		t.Fatalf("status = %d, calls = %d, want retry after 500 to run the handler", rec.Code, calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesLockOnPanic(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(t, func(ctx *gin.Context) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		ctx.Status(http.StatusCreated)
+	})
+
+	if rec := postWithKey(router, "key-1", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want 500", rec.Code)
+	}
+	// retry langsung diproses, bukan 409 sampai lock kedaluwarsa
+	if rec := postWithKey(router, "key-1", `{}`); rec.Code != http.StatusCreated || calls.Load() != 2 {
+		t.Fatalf("status = %d, calls = %d, want retry after panic to run the handler", rec.Code, calls.Load())
+	}
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(t, func(ctx *gin.Context) {
+		calls.Add(1)
+		ctx.Status(http.StatusCreated)
+	})
+
+	// tanpa Idempotency-Key body tidak dibaca middleware
+	tests := []struct {
+		name      string
+		key       string
+		want      int
+		wantCalls int32
+	}{
+		{name: "with key", key: "key-1", want: http.StatusRequestEntityTooLarge},
+		{name: "without key", want: http.StatusCreated, wantCalls: 1},
+	}
+	body := `{"caption":"` + strings.Repeat("a", 11<<20) + `"}`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := postWithKey(router, tt.key, body); rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("handler calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// request id dari client hanya dipakai jika aman ditulis ke log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID meneruskan X-Request-ID dari client (atau membuat yang baru),
+// mengirimnya balik di response dan memasang logger per request
+func RequestID(ctx *gin.Context) {
+	requestID := ctx.GetHeader(RequestIDHeader)
+	if !requestIDPattern.MatchString(requestID) {
+		requestID = newRequestID()
+	}
+
+	ctx.Set("request_id", requestID)
+	ctx.Header(RequestIDHeader, requestID)
+
+	logger := slog.Default().With("request_id", requestID)
+	// span dari otelgin, supaya log bisa dicari dari trace dan sebaliknya
+	if span := trace.SpanContextFromContext(ctx.Request.Context()); span.IsValid() {
+		logger = logger.With("trace_id", span.TraceID().String())
+	}
+	utils.SetRequestLogger(ctx, logger)
+	ctx.Next()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger mencatat satu baris log per request setelah handler selesai
+func RequestLogger(ctx *gin.Context) {
+	start := time.Now()
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	attrs := []any{
+		"method", ctx.Request.Method,
+		"route", routeLabel(ctx),
+		"path", ctx.Request.URL.Path,
+		"status", status,
+		"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+		"client_ip", ctx.ClientIP(),
+		"bytes", ctx.Writer.Size(),
+	}
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+	// logger request sudah membawa request_id, dan user_id jika lolos Authentication
+	utils.Logger(ctx).Log(ctx.Request.Context(), level, "request", attrs...)
+}
+
+// Recovery mengganti gin.Recovery supaya panic tercatat di log terstruktur
+// beserta stack trace, client hanya menerima pesan generik
+func Recovery(ctx *gin.Context, recovered any) {
+	utils.Logger(ctx).ErrorContext(ctx.Request.Context(), "Panic recovered",
+		"status_code", http.StatusInternalServerError,
+		"error", fmt.Sprintf("panic: %v", recovered),
+		"stack", string(debug.Stack()),
+	)
+	ctx.AbortWithStatusJSON(http.StatusInternalServerError,
+		models.NewErrorResponse(http.StatusText(http.StatusInternalServerError), "internal server error", http.StatusInternalServerError))
+}
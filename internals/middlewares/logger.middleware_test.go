@@ -0,0 +1,171 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// captureLogs mengarahkan slog.Default ke buffer JSON selama test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func newLoggedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.RequestID, middlewares.RequestLogger, gin.CustomRecovery(middlewares.Recovery))
+	router.GET("/posts/:id", func(ctx *gin.Context) {
+		utils.HandleError(ctx, http.StatusInternalServerError, "failed to get post", errors.New("connection refused"))
+	})
+	router.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+	return router
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{name: "propagated", header: "req-123", wantSame: true},
+		{name: "generated when missing", header: ""},
+		{name: "generated when invalid", header: "bad id\nwith newline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureLogs(t)
+			req := httptest.NewRequest(http.MethodGet, "/posts/1", nil)
+			if tt.header != "" {
+				req.Header.Set(middlewares.RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			newLoggedRouter().ServeHTTP(rec, req)
+
+			got := rec.Header().Get(middlewares.RequestIDHeader)
+			if tt.wantSame && got != tt.header {
+				t.Fatalf("request id = %q, want %q", got, tt.header)
+			}
+			if !tt.wantSame && (got == "" || got == tt.header) {
+				t.Fatalf("request id = %q, want a generated id", got)
+			}
+		})
+	}
+}
+
+func TestRequestLoggerFields(t *testing.T) {
+	buf := captureLogs(t)
+	req := httptest.NewRequest(http.MethodGet, "/posts/42", nil)
+	req.Header.Set(middlewares.RequestIDHeader, "req-abc")
+	rec := httptest.NewRecorder()
+	newLoggedRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "connection refused") {
+		t.Fatalf("response leaks internal error: %s", rec.Body.String())
+	}
+
+	lines := logLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), buf.String())
+	}
+	for _, entry := range lines {
+		if entry["request_id"] != "req-abc" {
+			t.Fatalf("log line without request_id: %v", entry)
+		}
+	}
+
+	handlerLog, requestLog := lines[0], lines[1]
+	if handlerLog["error"] != "connection refused" || handlerLog["level"] != "ERROR" {
+		t.Fatalf("handler log = %v", handlerLog)
+	}
+	if requestLog["msg"] != "request" || requestLog["route"] != "/posts/:id" || requestLog["path"] != "/posts/42" || requestLog["status"] != float64(500) {
+		t.Fatalf("request log = %v", requestLog)
+	}
+}
+
+func TestRecoveryLogsPanic(t *testing.T) {
+	buf := captureLogs(t)
+	rec := httptest.NewRecorder()
+	newLoggedRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "boom") {
+		t.Fatalf("response leaks panic value: %s", rec.Body.String())
+	}
+	var entry map[string]any
+	for _, line := range logLines(t, buf) {
+		if line["msg"] == "Panic recovered" {
+			entry = line
+		}
+	}
+	if entry == nil || entry["error"] != "panic: boom" {
+		t.Fatalf("panic not logged: %s", buf.String())
+	}
+	// stack trace menunjuk ke handler yang panic
+	if stack, _ := entry["stack"].(string); !strings.Contains(stack, "newLoggedRouter") {
+		t.Fatalf("stack = %q, want the panicking handler", stack)
+	}
+}
+
+func TestRequestLoggerTraceID(t *testing.T) {
+	buf := captureLogs(t)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("chat-test", otelgin.WithTracerProvider(provider)), middlewares.RequestID, middlewares.RequestLogger)
+	router.GET("/posts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/1", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	lines := logLines(t, buf)
+	if len(lines) != 1 || lines[0]["trace_id"] != spans[0].SpanContext().TraceID().String() {
+		t.Fatalf("request log = %v, want trace_id %s", lines, spans[0].SpanContext().TraceID())
+	}
+}
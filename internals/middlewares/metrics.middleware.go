@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "chat",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "chat",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "chat",
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+)
+
+// Metrics mencatat rate, error dan durasi per route. Label route memakai
+// template (/posts/:id), bukan path, supaya jumlah series tetap kecil.
+func Metrics(ctx *gin.Context) {
+	start := time.Now()
+	httpInFlight.Inc()
+	defer httpInFlight.Dec()
+
+	ctx.Next()
+
+	route := routeLabel(ctx)
+	httpRequests.WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+	httpDuration.WithLabelValues(ctx.Request.Method, route).Observe(time.Since(start).Seconds())
+}
+
+// path yang tidak cocok dengan route mana pun digabung jadi satu label
+func routeLabel(ctx *gin.Context) string {
+	if route := ctx.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
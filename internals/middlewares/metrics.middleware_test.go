@@ -0,0 +1,66 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrapeMetrics membaca nilai setiap series dari /metrics
+func scrapeMetrics(t *testing.T, router *gin.Engine) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	values := make(map[string]float64)
+	for line := range strings.SplitSeq(rec.Body.String(), "\n") {
+		series, value, ok := strings.Cut(line, " ")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("invalid metric line %q: %v", line, err)
+		}
+		values[series] = v
+	}
+	return values
+}
+
+func TestMetricsUsesRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.Metrics)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/posts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	// counter global, jadi yang dibandingkan selisihnya (aman untuk -count > 1)
+	before := scrapeMetrics(t, router)
+	for _, path := range []string{"/posts/1", "/posts/2", "/does-not-exist"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	after := scrapeMetrics(t, router)
+
+	for series, want := range map[string]float64{
+		`chat_http_requests_total{method="GET",route="/posts/:id",status="204"}`:    2,
+		`chat_http_requests_total{method="GET",route="unmatched",status="404"}`:     1,
+		`chat_http_request_duration_seconds_count{method="GET",route="/posts/:id"}`: 2,
+	} {
+		if got := after[series] - before[series]; got != want {
+			t.Errorf("%s increased by %v, want %v", series, got, want)
+		}
+	}
+	for series := range after {
+		if strings.Contains(series, `route="/posts/1"`) {
+			t.Error("metrics use the raw path as route label")
+		}
+	}
+}
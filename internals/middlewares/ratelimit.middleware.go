@@ -0,0 +1,136 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/configs"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitKeyFunc menentukan identitas client yang dihitung oleh limiter
+type RateLimitKeyFunc func(ctx *gin.Context) string
+
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	KeyBy  RateLimitKeyFunc
+}
+
+// override limit dari config, key-nya nama policy dalam huruf besar (POST_CREATE)
+var rateLimitOverrides map[string]configs.RateLimit
+
+// InitRateLimits memasang override RATELIMIT_<NAME> dari config
+func InitRateLimits(overrides map[string]configs.RateLimit) {
+	rateLimitOverrides = overrides
+}
+
+// NewRateLimitPolicy membuat policy baru. Nilai default bisa dioverride lewat
+// RATELIMIT_<NAME> dengan format "<limit>/<window>", contoh "30/1m".
+func NewRateLimitPolicy(name string, limit int, window time.Duration, keyBy RateLimitKeyFunc) RateLimitPolicy {
+	policy := RateLimitPolicy{Name: name, Limit: limit, Window: window, KeyBy: keyBy}
+
+	if override, ok := rateLimitOverrides[strings.ToUpper(strings.ReplaceAll(name, "-", "_"))]; ok {
+		policy.Limit, policy.Window = override.Limit, override.Window
+	}
+
+	return policy
+}
+
+// KeyByIP menghitung request per alamat IP client
+func KeyByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// KeyByUserOrIP menghitung request per user id jika sudah melewati
+// Authentication, selain itu jatuh ke alamat IP
+func KeyByUserOrIP(ctx *gin.Context) string {
+	if value, ok := ctx.Get("claims"); ok {
+		if claims, ok := value.(pkg.Claims); ok {
+			return fmt.Sprintf("user:%d", claims.UserId)
+		}
+	}
+	return KeyByIP(ctx)
+}
+
+// Sliding window log di atas sorted set. Waktu diambil dari server redis
+// supaya semua instance API memakai jam yang sama.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+func RateLimit(policy RateLimitPolicy) gin.HandlerFunc {
+	keyBy := policy.KeyBy
+	if keyBy == nil {
+		keyBy = KeyByUserOrIP
+	}
+	windowMs := policy.Window.Milliseconds()
+
+	return func(ctx *gin.Context) {
+		redisKey := fmt.Sprintf("RateLimit:%s:%s", policy.Name, keyBy(ctx))
+
+		res, err := slidingWindowScript.Run(ctx.Request.Context(), RDB, []string{redisKey}, windowMs, policy.Limit, newRequestMember()).Int64Slice()
+		if err != nil {
+			// redis bermasalah, jangan blokir semua traffic
+			utils.Logger(ctx).Warn("Rate limiter error", "policy", policy.Name, "error", err)
+			ctx.Next()
+			return
+		}
+
+		allowed, remaining, resetMs := res[0], res[1], res[2]
+		resetSec := (resetMs + 999) / 1000
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.FormatInt(max(remaining, 0), 10))
+		ctx.Header("RateLimit-Reset", strconv.FormatInt(resetSec, 10))
+		ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int64(policy.Window.Seconds())))
+
+		if allowed == 0 {
+			ctx.Header("Retry-After", strconv.FormatInt(resetSec, 10))
+			utils.HandleMiddlewareError(ctx, http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded, retry in %d seconds", resetSec))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func newRequestMember() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Deprecation menandai route sebagai deprecated (RFC 9745). Link mengarah ke
+// dokumentasi pengganti.
+func Deprecation(since time.Time, link string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", deprecation)
+		ctx.Header("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", link))
+		ctx.Next()
+	}
+}
+
+// EnvelopeV2 mengubah response JSON handler (models.Response, models.Page,
+// models.ErrorResponse) menjadi models.Envelope. Handler tetap sama untuk
+// /v1 dan /v2, hanya bentuk response yang berbeda. Response non-JSON seperti
+// redirect dan file diteruskan apa adanya.
+func EnvelopeV2(ctx *gin.Context) {
+	original := ctx.Writer
+	writer := &envelopeWriter{ResponseWriter: original}
+	ctx.Writer = writer
+	defer func() { ctx.Writer = original }()
+
+	ctx.Next()
+
+	if writer.passthrough {
+		return
+	}
+	raw := writer.body.Bytes()
+	if len(raw) == 0 {
+		// sukses tanpa body (contoh ctx.Status(201)) tetap dibungkus, 204 dan
+		// redirect dibiarkan kosong
+		status := writer.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices || status == http.StatusNoContent {
+			return
+		}
+		original.Header().Set("Content-Type", "application/json; charset=utf-8")
+		raw = []byte("{}")
+	}
+	body, err := toEnvelope(ctx, writer.Status(), raw)
+	if err != nil {
+		// bukan bentuk yang dikenal, kirim apa adanya
+		body = raw
+	}
+	original.Header().Del("Content-Length")
+	original.Write(body)
+}
+
+func toEnvelope(ctx *gin.Context, status int, raw []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	envelope := models.Envelope{Meta: models.EnvelopeMeta{RequestID: ctx.GetString("request_id")}}
+	if status >= http.StatusBadRequest {
+		var res models.ErrorResponse
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return nil, err
+		}
+		envelope.Error = &models.EnvelopeError{Code: res.ErrorCode, Message: res.Error, Details: res.Details}
+		return json.Marshal(envelope)
+	}
+
+	data, ok := fields["data"]
+	if !ok {
+		// response tanpa field data (contoh login), sisa field menjadi data
+		delete(fields, "success")
+		delete(fields, "message")
+		if len(fields) > 0 {
+			envelope.Data = fields
+		}
+		return json.Marshal(envelope)
+	}
+
+	var page struct {
+		Items      *json.RawMessage `json:"items"`
+		NextCursor string           `json:"next_cursor"`
+		Total      *int             `json:"total"`
+	}
+	if json.Unmarshal(data, &page) == nil && page.Items != nil {
+		envelope.Data = page.Items
+		envelope.Meta.Pagination = &models.Pagination{NextCursor: page.NextCursor, HasMore: page.NextCursor != "", Total: page.Total}
+		return json.Marshal(envelope)
+	}
+
+	envelope.Data = data
+	return json.Marshal(envelope)
+}
+
+// envelopeWriter menahan body JSON sampai handler selesai
+type envelopeWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	decided     bool
+	passthrough bool
+}
+
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if !w.decided {
+		w.decided = true
+		w.passthrough = !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
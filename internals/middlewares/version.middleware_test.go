@@ -0,0 +1,127 @@
+package middlewares_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+func TestEnvelopeV2(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) { ctx.Set("request_id", "req-1") }, middlewares.EnvelopeV2)
+	router.GET("/profile", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, models.Response[gin.H]{Success: true, Message: "ok", Data: gin.H{"id": 1}})
+	})
+	router.GET("/page", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, models.Response[models.Page[int]]{Success: true, Data: models.Page[int]{Items: []int{1, 2}, NextCursor: "abc"}})
+	})
+	router.GET("/missing", func(ctx *gin.Context) {
+		res := models.NewErrorResponse("Not Found", "post not found", http.StatusNotFound)
+		res.ErrorCode = "post_not_found"
+		ctx.JSON(http.StatusNotFound, res)
+	})
+	router.POST("/login", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"success": true, "message": "Login successful", "token": "jwt"})
+	})
+	router.POST("/comments", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+	router.DELETE("/comments", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+	router.GET("/file", func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/zip", []byte("PK"))
+	})
+
+	tests := []struct {
+		name           string
+		method, path   string
+		wantStatus     int
+		wantData       string
+		wantErrorCode  string
+		wantPagination *models.Pagination
+	}{
+		{name: "data is unwrapped", method: http.MethodGet, path: "/profile", wantStatus: http.StatusOK, wantData: `{"id":1}`},
+		{name: "page becomes pagination meta", method: http.MethodGet, path: "/page", wantStatus: http.StatusOK, wantData: `[1,2]`, wantPagination: &models.Pagination{NextCursor: "abc", HasMore: true}},
+		{name: "error response", method: http.MethodGet, path: "/missing", wantStatus: http.StatusNotFound, wantData: `null`, wantErrorCode: "post_not_found"},
+		{name: "fields without data", method: http.MethodPost, path: "/login", wantStatus: http.StatusOK, wantData: `{"token":"jwt"}`},
+		{name: "empty success body", method: http.MethodPost, path: "/comments", wantStatus: http.StatusCreated, wantData: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			var body struct {
+				Data  json.RawMessage       `json:"data"`
+				Error *models.EnvelopeError `json:"error"`
+				Meta  models.EnvelopeMeta   `json:"meta"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s: %v", rec.Body.String(), err)
+			}
+			if string(body.Data) != tt.wantData {
+				t.Fatalf("data = %s, want %s", body.Data, tt.wantData)
+			}
+			if body.Meta.RequestID != "req-1" {
+				t.Fatalf("meta.request_id = %q, want req-1", body.Meta.RequestID)
+			}
+			if tt.wantErrorCode == "" && body.Error != nil {
+				t.Fatalf("error = %+v, want null", body.Error)
+			}
+			if tt.wantErrorCode != "" && (body.Error == nil || body.Error.Code != tt.wantErrorCode) {
+				t.Fatalf("error = %+v, want code %s", body.Error, tt.wantErrorCode)
+			}
+			if tt.wantPagination == nil && body.Meta.Pagination != nil {
+				t.Fatalf("pagination = %+v, want none", body.Meta.Pagination)
+			}
+			if tt.wantPagination != nil && (body.Meta.Pagination == nil || *body.Meta.Pagination != *tt.wantPagination) {
+				t.Fatalf("pagination = %+v, want %+v", body.Meta.Pagination, tt.wantPagination)
+			}
+		})
+	}
+
+	t.Run("no content stays empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/comments", nil))
+		if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+			t.Fatalf("status = %d, body = %q, want empty 204", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("non JSON passes through", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/file", nil))
+		if rec.Body.String() != "PK" {
+			t.Fatalf("body = %q, want PK", rec.Body.String())
+		}
+	})
+}
+
+func TestDeprecation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	since := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	router.GET("/user", middlewares.Deprecation(since, "/chat/v2/index.html"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/user", nil))
+	if got := rec.Header().Get("Deprecation"); got != "@1792368000" {
+		t.Fatalf("Deprecation = %q, want @1792368000", got)
+	}
+	if got := rec.Header().Get("Link"); got != `</chat/v2/index.html>; rel="deprecation"` {
+		t.Fatalf("Link = %q", got)
+	}
+}
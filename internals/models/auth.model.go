@@ -3,11 +3,53 @@ package models
 import "time"
 
 type AuthRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Password string `json:"password" binding:"required,max=128"`
 }
 
 type BlacklistToken struct {
 	Token     string        `json:"token"`
 	ExpiresAt time.Duration `json:"expires_in"`
 }
+
+type Account struct {
+	ID         int
+	Email      string
+	Password   string
+	VerifiedAt *time.Time
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email,max=254"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token" binding:"required,max=512"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required,max=512"`
+	Password string `json:"password" binding:"required,max=128"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=128"`
+	NewPassword     string `json:"new_password" binding:"required,max=128"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Password string `json:"password" binding:"required,max=128"`
+}
+
+type AccountToken struct {
+	AccountID int
+	Payload   *string
+}
+
+// purpose untuk tabel account_tokens
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeChangeEmail   = "change_email"
+)
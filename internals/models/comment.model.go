@@ -8,6 +8,6 @@ type Comment struct {
 }
 
 type CreateCommentRequest struct {
-	PostID  int    `json:"post_id"`
-	Comment string `json:"comment"`
+	PostID  int    `json:"post_id" binding:"required,gt=0" example:"1"`
+	Comment string `json:"comment" binding:"required,notblank,max=1000" example:"Keren banget!"`
 }
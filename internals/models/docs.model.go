@@ -29,3 +29,10 @@ type ResponseAny struct {
 	Message string `json:"message" example:"Succes Get Comment by Id Post"`
 	Data    any    `json:"data"`
 }
+
+// LoginData adalah isi data login di /v2
+type LoginData struct {
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
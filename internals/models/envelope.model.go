@@ -0,0 +1,27 @@
+package models
+
+// Envelope adalah bentuk semua response /v2. Data terisi saat sukses,
+// Error terisi saat gagal, Meta selalu ada.
+type Envelope struct {
+	Data  any            `json:"data"`
+	Error *EnvelopeError `json:"error"`
+	Meta  EnvelopeMeta   `json:"meta"`
+}
+
+type EnvelopeError struct {
+	Code    string       `json:"code" example:"post_not_found"`
+	Message string       `json:"message" example:"post not found"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+type EnvelopeMeta struct {
+	RequestID  string      `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination untuk list dengan cursor, sama dengan Page di /v1
+type Pagination struct {
+	NextCursor string `json:"next_cursor,omitempty" example:"MTc2MDAwMDAwMDAwMDAwMDo0Mg"`
+	HasMore    bool   `json:"has_more" example:"true"`
+	Total      *int   `json:"total,omitempty" example:"42"`
+}
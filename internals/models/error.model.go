@@ -1,17 +1,117 @@
 package models
 
+import "net/http"
+
 type ErrorResponse struct {
-	Success bool   `json:"success" example:"false"`
-	Status  string `json:"status" example:"HTTP Status Error"`
-	Code    int    `json:"status_code" example:"400"`
-	Error   string `json:"error"  example:"Error Message"`
+	Success   bool         `json:"success" example:"false"`
+	Status    string       `json:"status" example:"HTTP Status Error"`
+	Code      int          `json:"status_code" example:"400"`
+	ErrorCode string       `json:"error_code" example:"validation_failed"`
+	Error     string       `json:"error"  example:"Error Message"`
+	Details   []FieldError `json:"details,omitempty"`
+}
+
+// FieldError menjelaskan kesalahan satu field input, Code stabil untuk terjemahan di client
+type FieldError struct {
+	Field   string `json:"field" example:"email"`
+	Code    string `json:"code" example:"invalid_format"`
+	Message string `json:"message" example:"email must be a valid email address"`
 }
 
 func NewErrorResponse(status, err string, code int) ErrorResponse {
 	return ErrorResponse{
-		Success: false,
-		Status:  status,
-		Code:    code,
-		Error:   err,
+		Success:   false,
+		Status:    status,
+		Code:      code,
+		ErrorCode: ErrorCodeForStatus(code),
+		Error:     err,
+	}
+}
+
+// error_code umum untuk error yang tidak punya kode domain sendiri
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:           "bad_request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not_found",
+	http.StatusConflict:             "conflict",
+	http.StatusUnsupportedMediaType: "unsupported_media_type",
+	http.StatusUnprocessableEntity:  "unprocessable_entity",
+	http.StatusTooManyRequests:      "rate_limited",
+	http.StatusInternalServerError:  "internal_error",
+	http.StatusBadGateway:           "bad_gateway",
+	http.StatusServiceUnavailable:   "unavailable",
+}
+
+func ErrorCodeForStatus(code int) string {
+	if errorCode, ok := statusErrorCodes[code]; ok {
+		return errorCode
+	}
+	if code >= http.StatusInternalServerError {
+		return "internal_error"
+	}
+	return "bad_request"
+}
+
+// ErrorKind menentukan status HTTP dari DomainError, lihat utils.HandleDomainError
+type ErrorKind int
+
+const (
+	KindNotFound ErrorKind = iota + 1
+	KindConflict
+	KindForbidden
+	KindValidation
+)
+
+// DomainError adalah error yang dikembalikan repository untuk kondisi yang
+// bukan kesalahan server. Message aman ditampilkan ke client, Err hanya
+// masuk log.
+type DomainError struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
 	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// Is mencocokkan Code, jadi salinan dari WithCause tetap cocok dengan sentinel-nya
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithCause menyalin error dan menempelkan penyebab aslinya
+func (e *DomainError) WithCause(err error) *DomainError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+func NewNotFoundError(code, message string) *DomainError {
+	return &DomainError{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) *DomainError {
+	return &DomainError{Kind: KindConflict, Code: code, Message: message}
+}
+
+func NewForbiddenError(code, message string) *DomainError {
+	return &DomainError{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// NewValidationError membuat error validasi input. Tanpa fields dipakai
+// untuk input yang salah secara keseluruhan, misalnya token kedaluwarsa.
+func NewValidationError(code, message string, fields ...FieldError) *DomainError {
+	return &DomainError{Kind: KindValidation, Code: code, Message: message, Fields: fields}
 }
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"max=128"`
+}
+
+// Hasil purge akun: file yang harus dihapus dari disk dan id untuk
+// membersihkan cache Redis
+type PurgedAccount struct {
+	PostImgs   []string
+	ProfileImg *string
+	CoverImg   *string
+	PostIDs    []int
+	// follower yang feed-nya memuat post akun ini
+	FollowerIDs []int
+}
+
+// Data yang dimasukkan ke file export akun
+type UserExport struct {
+	Account   ExportAccount   `json:"account"`
+	Profile   Profile         `json:"profile"`
+	Posts     []ExportPost    `json:"posts"`
+	Comments  []ExportComment `json:"comments"`
+	Likes     []ExportLike    `json:"likes"`
+	Followers []Follow        `json:"followers"`
+	Following []Follow        `json:"following"`
+}
+
+type ExportAccount struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportPost struct {
+	ID        int       `json:"id"`
+	Caption   string    `json:"caption"`
+	Images    []string  `json:"images"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportComment struct {
+	ID        int       `json:"id"`
+	PostID    int       `json:"post_id"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportLike struct {
+	PostID    int       `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportLink struct {
+	URL       string    `json:"url" example:"/user/export/download?file=3-abc.zip&expires=1760000000&sig=..."`
+	ExpiresAt time.Time `json:"expires_at"`
+}
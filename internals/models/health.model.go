@@ -0,0 +1,7 @@
+package models
+
+// Status liveness / readiness, checks berisi hasil tiap dependency
+type HealthStatus struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
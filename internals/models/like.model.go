@@ -12,5 +12,5 @@ type Like struct {
 }
 
 type CreateLikeRequest struct {
-	PostID int `json:"post_id"`
+	PostID int `json:"post_id" binding:"required,gt=0"`
 }
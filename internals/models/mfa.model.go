@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type MFA struct {
+	AccountID    int
+	Secret       string
+	LastUsedStep int64
+	EnabledAt    *time.Time
+}
+
+type MFAEnrollment struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/SocialMedia:alice@example.com?secret=JBSWY3DPEHPK3PXP"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required,max=2048"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+type MFADisableRequest struct {
+	Password string `json:"password" binding:"required,max=128"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
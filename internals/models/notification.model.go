@@ -3,12 +3,15 @@ package models
 import "time"
 
 type Notification struct {
-	Type      string    `json:"type"`              // follow, like, comment
+	Type      string    `json:"type"`              // follow, like, comment, mention
 	FromID    int       `json:"from_id"`           // id user yang melakukan aksi
 	FromName  string    `json:"from_name"`         // nama user yang melakukan aksi
-	PostID    *int      `json:"post_id,omitempty"` // kalau like/comment, ada post_id
+	PostID    *int      `json:"post_id,omitempty"` // kalau like/comment/mention, ada post_id
 	Message   string    `json:"message"`
 	CreatedAt time.Time `json:"created_at"`
+	// tie-breaker cursor untuk created_at yang sama, unik lintas jenis:
+	// id baris sumber * 4 + jenis
+	CursorID int `json:"-"`
 }
 
 type NotificationList []Notification
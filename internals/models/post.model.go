@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"mime/multipart"
+	"time"
+)
 
 type Post struct {
 	ID        int        `json:"id"`
@@ -20,6 +23,12 @@ type PostImg struct {
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
+// CreatePostForm form-data dari client, caption boleh kosong jika ada gambar
+type CreatePostForm struct {
+	Caption string                  `form:"caption" binding:"required_without=Images,max=2200"`
+	Images  []*multipart.FileHeader `form:"images" binding:"max=10,dive,image"`
+}
+
 type CreatePostRequest struct {
 	Caption string `form:"caption" json:"caption"`
 	Images  []string
@@ -27,12 +36,38 @@ type CreatePostRequest struct {
 
 // Post Feed
 type PostFeed struct {
-	ID           int      `json:"id" example:"101"`
-	Fullname     string   `json:"fullname" example:"Rangga Putra"`
-	Caption      string   `json:"caption" example:"Liburan di pantai bareng teman-teman!"`
-	Images       []string `json:"images" example:"['public/post/1.jpg','public/post/2.jpg']"`
-	LikeCount    int      `json:"like_count" example:"123"`
-	CommentCount int      `json:"comment_count" example:"45"`
+	ID           int       `json:"id" example:"101"`
+	AuthorID     int       `json:"author_id" example:"7"`
+	Fullname     string    `json:"fullname" example:"Rangga Putra"`
+	Caption      string    `json:"caption" example:"Liburan di pantai bareng teman-teman!"`
+	Images       []string  `json:"images" example:"['public/post/1.jpg','public/post/2.jpg']"`
+	LikeCount    int       `json:"like_count" example:"123"`
+	CommentCount int       `json:"comment_count" example:"45"`
+	CreatedAt    time.Time `json:"created_at" example:"2025-09-20T12:00:00Z"`
+	*ViewerState
+}
+
+// Status post dari sudut pandang user yang sedang login. Tidak ikut disimpan
+// di cache bersama, selalu dihitung per request.
+type ViewerState struct {
+	LikedByMe     bool            `json:"liked_by_me"`
+	SavedByMe     bool            `json:"saved_by_me"`
+	CommentedByMe bool            `json:"commented_by_me"`
+	IsOwner       bool            `json:"is_owner"`
+	LikedBy       *LikedByPreview `json:"liked_by,omitempty"`
+}
+
+// "Disukai oleh X dan N lainnya", nama diambil dari akun yang saya ikuti
+type LikedByPreview struct {
+	Names  []string `json:"names" example:"['Siti Amelia']"`
+	Others int      `json:"others" example:"12"`
+}
+
+// Skor awal explore yang dihitung dari database
+type ExploreSeed struct {
+	PostID    int
+	CreatedAt time.Time
+	Score     float64
 }
 
 // Post Detail
@@ -59,4 +94,11 @@ type PostDetail struct {
 	Images    []string         `json:"images" example:"['public/post/1.jpg','public/post/2.jpg']"`
 	Likes     int              `json:"likes" example:"123"`
 	Comments  []CommentPreview `json:"comments"`
+	*ViewerState
+}
+
+// Post yang disimpan
+type SavedPost struct {
+	PostFeed
+	SavedAt time.Time `json:"saved_at"`
 }
@@ -7,7 +7,16 @@ type Response[T any] struct {
 }
 
 type ResponseLogin struct {
-	Success bool   `json:"success" example:"true"`
-	Message string `json:"message" example:"Request processed successfully"`
-	Token   string `json:"token"`
+	Success     bool   `json:"success" example:"true"`
+	Message     string `json:"message" example:"Request processed successfully"`
+	Token       string `json:"token,omitempty"`
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+}
+
+// Page untuk list dengan cursor pagination, next_cursor kosong berarti halaman terakhir
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
 }
@@ -0,0 +1,17 @@
+package models
+
+// Skor kandidat who-to-follow hasil precompute
+type SuggestionScore struct {
+	ID    int
+	Score float64
+}
+
+// Saran akun untuk diikuti
+type Suggestion struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	Fullname    string `json:"fullname"`
+	Img         string `json:"img"`
+	MutualCount int    `json:"mutual_count"` // jumlah akun yang saya ikuti yang juga mengikuti user ini
+	FollowsYou  bool   `json:"follows_you"`
+}
@@ -1,13 +1,73 @@
 package models
 
+import "time"
+
 type Profile struct {
-	FullName    *string `json:"fullname"`
-	PhoneNumber *string `json:"phone"`
-	Img         *string `json:"img"`
+	Username             *string `json:"username"`
+	FullName             *string `json:"fullname"`
+	PhoneNumber          *string `json:"phone"`
+	Img                  *string `json:"img"`
+	CoverImg             *string `json:"cover_img"`
+	Bio                  *string `json:"bio"`
+	Website              *string `json:"website"`
+	Location             *string `json:"location"`
+	Pronouns             *string `json:"pronouns"`
+	Birthdate            *string `json:"birthdate"`
+	BirthdateVisibility  string  `json:"birthdate_visibility"`
+	FollowListVisibility string  `json:"follow_list_visibility"`
+	FollowerCount        int     `json:"follower_count"`
+	FollowingCount       int     `json:"following_count"`
+}
+
+// Body PATCH /user (JSON merge-patch), null mengosongkan field
+type UpdateProfileRequest struct {
+	FullName             *string `json:"fullname,omitempty" example:"Alice Johnson"`
+	PhoneNumber          *string `json:"phone,omitempty" example:"+6281234567890"`
+	Bio                  *string `json:"bio,omitempty" example:"Coffee, code and mountains"`
+	Website              *string `json:"website,omitempty" example:"https://alice.dev"`
+	Location             *string `json:"location,omitempty" example:"Bandung, Indonesia"`
+	Pronouns             *string `json:"pronouns,omitempty" example:"she/her"`
+	Birthdate            *string `json:"birthdate,omitempty" example:"1998-04-21"`
+	BirthdateVisibility  *string `json:"birthdate_visibility,omitempty" example:"followers" enums:"public,followers,private"`
+	FollowListVisibility *string `json:"follow_list_visibility,omitempty" example:"public" enums:"public,followers,private"`
+	Img                  *string `json:"img,omitempty" swaggertype:"string" example:"null"`
+	CoverImg             *string `json:"cover_img,omitempty" swaggertype:"string" example:"null"`
 }
 
 type Follow struct {
-	ID       int    `json:"id,omitempty"`
-	Fullname string `json:"fullname"`
-	Img      string `json:"img"`
+	ID          int       `json:"id,omitempty"`
+	Username    string    `json:"username"`
+	Fullname    string    `json:"fullname"`
+	Img         string    `json:"img"`
+	IsFollowing bool      `json:"is_following"` // viewer mengikuti user ini
+	FollowsYou  bool      `json:"follows_you"`  // user ini mengikuti viewer
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+// Filter & posisi halaman list follower/following
+type FollowQuery struct {
+	Search     string
+	Limit      int
+	CursorTime *time.Time
+	CursorID   int
+}
+
+// Profile yang bisa dilihat user lain lewat handle
+type PublicProfile struct {
+	ID             int     `json:"id"`
+	Username       string  `json:"username"`
+	FullName       *string `json:"fullname"`
+	Img            *string `json:"img"`
+	CoverImg       *string `json:"cover_img"`
+	Bio            *string `json:"bio"`
+	Website        *string `json:"website"`
+	Location       *string `json:"location"`
+	Pronouns       *string `json:"pronouns"`
+	Birthdate      *string `json:"birthdate,omitempty"` // hanya jika diizinkan birthdate_visibility
+	FollowerCount  int     `json:"follower_count"`
+	FollowingCount int     `json:"following_count"`
+}
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required" example:"alice.j"`
 }
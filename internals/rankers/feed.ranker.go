@@ -0,0 +1,76 @@
+package rankers
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// FeedSignals adalah data tambahan untuk ranking feed satu viewer
+type FeedSignals struct {
+	// waktu acuan recency, diisi dari luar supaya hasil ranking deterministik
+	Now time.Time
+	// jumlah interaksi (like & komentar) viewer ke post milik author, per author id
+	Affinity map[int]int
+}
+
+// FeedRanker mengurutkan post feed, implementasi bisa diganti tanpa mengubah handler
+type FeedRanker interface {
+	Rank(posts []models.PostFeed, signals FeedSignals) []models.PostFeed
+}
+
+// EngagementRanker menilai post dari recency, kedekatan viewer dengan author
+// dan engagement post:
+//
+//	score = 2^(-age/HalfLife) * (1 + AffinityWeight*ln(1+affinity)) * (1 + EngagementWeight*ln(1+likes+CommentWeight*comments))
+type EngagementRanker struct {
+	HalfLife         time.Duration
+	AffinityWeight   float64
+	EngagementWeight float64
+	CommentWeight    float64
+}
+
+func NewEngagementRanker() *EngagementRanker {
+	return &EngagementRanker{
+		HalfLife:         24 * time.Hour,
+		AffinityWeight:   1.0,
+		EngagementWeight: 0.5,
+		CommentWeight:    2.0,
+	}
+}
+
+// Score menghitung skor satu post
+func (r *EngagementRanker) Score(post models.PostFeed, signals FeedSignals) float64 {
+	age := max(signals.Now.Sub(post.CreatedAt), 0)
+	recency := math.Exp2(-age.Hours() / r.HalfLife.Hours())
+
+	affinity := 1 + r.AffinityWeight*math.Log1p(float64(signals.Affinity[post.AuthorID]))
+	engagement := 1 + r.EngagementWeight*math.Log1p(float64(post.LikeCount)+r.CommentWeight*float64(post.CommentCount))
+
+	return recency * affinity * engagement
+}
+
+// Rank mengurutkan post dari skor tertinggi. Skor sama diurutkan dari yang
+// terbaru lalu id terbesar supaya urutan selalu stabil.
+func (r *EngagementRanker) Rank(posts []models.PostFeed, signals FeedSignals) []models.PostFeed {
+	scores := make(map[int]float64, len(posts))
+	for _, p := range posts {
+		scores[p.ID] = r.Score(p, signals)
+	}
+
+	ranked := make([]models.PostFeed, len(posts))
+	copy(ranked, posts)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return ranked
+}
@@ -0,0 +1,124 @@
+package rankers_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/rankers"
+)
+
+// jam acuan tetap, hasil ranking tidak bergantung waktu test dijalankan
+var now = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func post(id, author int, age time.Duration, likes, comments int) models.PostFeed {
+	return models.PostFeed{ID: id, AuthorID: author, CreatedAt: now.Add(-age), LikeCount: likes, CommentCount: comments}
+}
+
+func ids(posts []models.PostFeed) []int {
+	out := make([]int, len(posts))
+	for i, p := range posts {
+		out[i] = p.ID
+	}
+	return out
+}
+
+func TestEngagementRankerScore(t *testing.T) {
+	ranker := rankers.NewEngagementRanker()
+	signals := rankers.FeedSignals{Now: now, Affinity: map[int]int{7: 3}}
+
+	tests := []struct {
+		name string
+		post models.PostFeed
+		want float64
+	}{
+		{name: "new post without signals", post: post(1, 1, 0, 0, 0), want: 1},
+		{name: "one half-life old", post: post(1, 1, 24*time.Hour, 0, 0), want: 0.5},
+		{name: "two half-lives old", post: post(1, 1, 48*time.Hour, 0, 0), want: 0.25},
+		// post dari masa depan (jam server berbeda) dianggap baru
+		{name: "created after now", post: post(1, 1, -time.Hour, 0, 0), want: 1},
+		{name: "affinity", post: post(1, 7, 0, 0, 0), want: 1 + math.Log(4)},
+		{name: "likes", post: post(1, 1, 0, 3, 0), want: 1 + 0.5*math.Log(4)},
+		{name: "comment counts double", post: post(1, 1, 0, 1, 1), want: 1 + 0.5*math.Log(4)},
+		{name: "all signals", post: post(1, 7, 24*time.Hour, 1, 1), want: 0.5 * (1 + math.Log(4)) * (1 + 0.5*math.Log(4))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranker.Score(tt.post, signals); math.Abs(got-tt.want) > 1e-12 {
+				t.Fatalf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngagementRankerRank(t *testing.T) {
+	tests := []struct {
+		name     string
+		posts    []models.PostFeed
+		affinity map[int]int
+		want     []int
+	}{
+		{
+			name:  "recency",
+			posts: []models.PostFeed{post(1, 1, 30*time.Hour, 0, 0), post(2, 1, time.Hour, 0, 0), post(3, 1, 5*time.Hour, 0, 0)},
+			want:  []int{2, 3, 1},
+		},
+		{
+			name:     "affinity",
+			posts:    []models.PostFeed{post(1, 1, time.Hour, 0, 0), post(2, 2, time.Hour, 0, 0), post(3, 3, time.Hour, 0, 0)},
+			affinity: map[int]int{2: 10, 3: 1},
+			want:     []int{2, 3, 1},
+		},
+		{
+			name:  "engagement",
+			posts: []models.PostFeed{post(1, 1, time.Hour, 1, 0), post(2, 1, time.Hour, 0, 3), post(3, 1, time.Hour, 4, 0)},
+			want:  []int{2, 3, 1},
+		},
+		{
+			// teman dekat dengan post 12 jam lalu mengalahkan post baru tanpa interaksi
+			name:     "affinity outweighs a few hours",
+			posts:    []models.PostFeed{post(1, 1, 0, 0, 0), post(2, 2, 12*time.Hour, 0, 0)},
+			affinity: map[int]int{2: 5},
+			want:     []int{2, 1},
+		},
+		{
+			// ramai tapi dua hari lalu kalah dari post baru
+			name:  "recency outweighs old engagement",
+			posts: []models.PostFeed{post(1, 1, 48*time.Hour, 20, 5), post(2, 1, 0, 0, 0)},
+			want:  []int{2, 1},
+		},
+		{
+			// 2 like setara 1 komentar, skor sama diurutkan dari id terbesar
+			name:  "tie on score and created_at",
+			posts: []models.PostFeed{post(4, 1, time.Hour, 2, 0), post(9, 1, time.Hour, 0, 1), post(6, 1, time.Hour, 2, 0)},
+			want:  []int{9, 6, 4},
+		},
+		{
+			// age dibatasi 0, jadi post dari masa depan punya skor sama
+			name:  "tie on score then created_at",
+			posts: []models.PostFeed{post(1, 1, -time.Minute, 0, 0), post(2, 1, -time.Hour, 0, 0), post(3, 1, 0, 0, 0)},
+			want:  []int{2, 1, 3},
+		},
+		{
+			name:  "empty",
+			posts: []models.PostFeed{},
+			want:  []int{},
+		},
+	}
+	ranker := rankers.NewEngagementRanker()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]models.PostFeed(nil), tt.posts...)
+			got := ranker.Rank(tt.posts, rankers.FeedSignals{Now: now, Affinity: tt.affinity})
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Fatalf("Rank = %v, want %v", ids(got), tt.want)
+			}
+			// input tidak ikut terurut
+			if !reflect.DeepEqual(ids(tt.posts), ids(input)) {
+				t.Fatalf("Rank modified its input: %v", ids(tt.posts))
+			}
+		})
+	}
+}
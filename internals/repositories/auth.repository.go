@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ntisrangga142/chat/internals/models"
 )
 
 type Auth struct {
@@ -17,11 +19,11 @@ func NewAuthRepo(Db *pgxpool.Pool) *Auth {
 	return &Auth{db: Db}
 }
 
-func (r *Auth) Register(ctx context.Context, email, password string) error {
+func (r *Auth) Register(ctx context.Context, email, password string) (int, error) {
 	// mulai transaction
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() {
 		if err != nil {
@@ -33,34 +35,334 @@ func (r *Auth) Register(ctx context.Context, email, password string) error {
 	var userID int
 	queryAccount := `INSERT INTO accounts (email, password) VALUES ($1, $2) RETURNING id`
 	if err = tx.QueryRow(ctx, queryAccount, email, password).Scan(&userID); err != nil {
-		return fmt.Errorf("failed to insert accounts = %w", err)
+		if isUniqueViolation(err) {
+			return 0, ErrEmailTaken.WithCause(err)
+		}
+		return 0, fmt.Errorf("failed to insert accounts = %w", err)
 	}
 
 	// insert ke profiles
 	queryUser := `INSERT INTO profiles (id, fullname, phone, img) VALUES ($1, NULL, NULL, NULL);`
 	if _, err = tx.Exec(ctx, queryUser, userID); err != nil {
-		return fmt.Errorf("failed to insert profiles = %w", err)
+		return 0, fmt.Errorf("failed to insert profiles = %w", err)
 	}
 
 	// commit transaction
 	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *Auth) Login(ctx context.Context, email string) (*models.Account, error) {
+	query := `SELECT id, email, password, verified_at FROM accounts WHERE email = $1 AND purged_at IS NULL`
+	var account models.Account
+	err := r.db.QueryRow(ctx, query, email).Scan(&account.ID, &account.Email, &account.Password, &account.VerifiedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// Ambil akun berdasarkan id
+func (r *Auth) GetAccountByID(ctx context.Context, accountID int) (*models.Account, error) {
+	query := `SELECT id, email, password, verified_at FROM accounts WHERE id = $1 AND purged_at IS NULL`
+	var account models.Account
+	err := r.db.QueryRow(ctx, query, accountID).Scan(&account.ID, &account.Email, &account.Password, &account.VerifiedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// Cek apakah email sudah dipakai akun lain
+func (r *Auth) IsEmailTaken(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE email = $1)`, email).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Aktifkan kembali akun yang dinonaktifkan atau masih dalam masa tenggang penghapusan
+func (r *Auth) RestoreAccount(ctx context.Context, accountID int) error {
+	query := `
+		UPDATE accounts
+		SET delete_after = NULL, deactivated_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND (delete_after IS NOT NULL OR deactivated_at IS NOT NULL) AND purged_at IS NULL
+	`
+	if _, err := r.db.Exec(ctx, query, accountID); err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+	return nil
+}
+
+// Update hash password tanpa mencabut token (dipakai untuk rehash saat login)
+func (r *Auth) UpdatePassword(ctx context.Context, accountID int, password string) error {
+	if _, err := r.db.Exec(ctx, `UPDATE accounts SET password = $1, updated_at = NOW() WHERE id = $2`, password, accountID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// Ganti email, alamat baru otomatis terverifikasi karena token dikirim ke alamat tersebut
+func (r *Auth) UpdateEmail(ctx context.Context, accountID int, email string) error {
+	query := `UPDATE accounts SET email = $1, verified_at = NOW(), updated_at = NOW() WHERE id = $2`
+	if _, err := r.db.Exec(ctx, query, email, accountID); err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailTaken.WithCause(err)
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	return nil
+}
+
+// Simpan token sekali pakai (verifikasi email / reset password / ganti email)
+func (r *Auth) CreateAccountToken(ctx context.Context, accountID int, purpose, tokenHash string, payload *string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO account_tokens (account_id, purpose, token_hash, payload, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := r.db.Exec(ctx, query, accountID, purpose, tokenHash, payload, expiresAt); err != nil {
+		return fmt.Errorf("failed to insert account token: %w", err)
+	}
+	return nil
+}
+
+// Tandai token sudah dipakai, hanya berhasil sekali selama token belum expired
+func (r *Auth) ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error) {
+	query := `
+		UPDATE account_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING account_id, payload
+	`
+	var token models.AccountToken
+	if err := r.db.QueryRow(ctx, query, tokenHash, purpose).Scan(&token.AccountID, &token.Payload); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Verifikasi email akun
+func (r *Auth) VerifyEmail(ctx context.Context, accountID int) error {
+	query := `UPDATE accounts SET verified_at = NOW(), updated_at = NOW() WHERE id = $1 AND verified_at IS NULL`
+	if _, err := r.db.Exec(ctx, query, accountID); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// Ganti password dan cabut semua token sekali pakai yang masih aktif
+func (r *Auth) ResetPassword(ctx context.Context, accountID int, password string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE accounts SET password = $1, updated_at = NOW() WHERE id = $2`, password, accountID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE account_tokens SET used_at = NOW() WHERE account_id = $1 AND used_at IS NULL`, accountID); err != nil {
+		return fmt.Errorf("failed to revoke account tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Ganti password dari sesi yang login, hanya token reset password yang dicabut
+// supaya konfirmasi ganti email yang masih berjalan tetap berlaku
+func (r *Auth) ChangePassword(ctx context.Context, accountID int, password string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE accounts SET password = $1, updated_at = NOW() WHERE id = $2`, password, accountID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	query := `UPDATE account_tokens SET used_at = NOW() WHERE account_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := tx.Exec(ctx, query, accountID, models.TokenPurposeResetPassword); err != nil {
+		return fmt.Errorf("failed to revoke reset password tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	return nil
+}
+
+// Ambil data 2FA akun, nil jika belum pernah enroll
+func (r *Auth) GetMFA(ctx context.Context, accountID int) (*models.MFA, error) {
+	query := `SELECT account_id, secret, last_used_step, enabled_at FROM account_mfa WHERE account_id = $1`
+	var mfa models.MFA
+	err := r.db.QueryRow(ctx, query, accountID).Scan(&mfa.AccountID, &mfa.Secret, &mfa.LastUsedStep, &mfa.EnabledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// Simpan secret TOTP baru, hanya jika 2FA belum aktif
+func (r *Auth) SaveMFASecret(ctx context.Context, accountID int, secret string) error {
+	query := `
+		INSERT INTO account_mfa (account_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (account_id)
+		DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = NOW()
+		WHERE account_mfa.enabled_at IS NULL
+	`
+	if _, err := r.db.Exec(ctx, query, accountID, secret); err != nil {
+		return fmt.Errorf("failed to save mfa secret: %w", err)
+	}
+	return nil
+}
+
+// Aktifkan 2FA dan ganti semua recovery code
+func (r *Auth) EnableMFA(ctx context.Context, accountID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
+	query := `UPDATE account_mfa SET enabled_at = NOW(), last_used_step = $2, updated_at = NOW() WHERE account_id = $1`
+	if _, err := tx.Exec(ctx, query, accountID, step); err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO recovery_codes (account_id, code_hash) VALUES ($1, $2)`, accountID, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-func (r *Auth) Login(ctx context.Context, email string) (int, string, error) {
-	query := `SELECT id, password FROM accounts WHERE email = $1`
-	var id *int
-	var hashedPassword *string
-	err := r.db.QueryRow(ctx, query, email).Scan(&id, &hashedPassword)
+// Simpan step TOTP terakhir, false jika step sudah pernah dipakai
+func (r *Auth) UseMFAStep(ctx context.Context, accountID int, step int64) (bool, error) {
+	query := `UPDATE account_mfa SET last_used_step = $2, updated_at = NOW() WHERE account_id = $1 AND last_used_step < $2`
+	tag, err := r.db.Exec(ctx, query, accountID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update mfa step: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Pakai recovery code, masing-masing hanya bisa sekali
+func (r *Auth) UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error) {
+	query := `UPDATE recovery_codes SET used_at = NOW() WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.db.Exec(ctx, query, accountID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Matikan 2FA
+func (r *Auth) DisableMFA(ctx context.Context, accountID int) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM account_mfa WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to disable mfa: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Cari akun yang terhubung dengan identitas eksternal, 0 jika belum ada
+func (r *Auth) GetAccountByIdentity(ctx context.Context, provider, subject string) (int, error) {
+	query := `SELECT account_id FROM account_identities WHERE provider = $1 AND subject = $2`
+	var accountID int
+	if err := r.db.QueryRow(ctx, query, provider, subject).Scan(&accountID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, "", fmt.Errorf("user not found")
+			return 0, nil
 		}
-		return 0, "", err
+		return 0, err
+	}
+	return accountID, nil
+}
+
+// Hubungkan identitas eksternal ke akun yang sudah ada
+func (r *Auth) LinkIdentity(ctx context.Context, accountID int, provider, subject, email string) error {
+	query := `
+		INSERT INTO account_identities (provider, subject, account_id, email)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, subject) DO NOTHING
+	`
+	if _, err := r.db.Exec(ctx, query, provider, subject, accountID, email); err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// Buat akun baru dari identitas eksternal. Password dikosongkan sehingga
+// akun hanya bisa login lewat provider sampai user melakukan reset password.
+func (r *Auth) RegisterWithIdentity(ctx context.Context, email, provider, subject string) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int
+	queryAccount := `INSERT INTO accounts (email, password, verified_at) VALUES ($1, '', NOW()) RETURNING id`
+	if err := tx.QueryRow(ctx, queryAccount, email).Scan(&userID); err != nil {
+		return 0, fmt.Errorf("failed to insert accounts = %w", err)
+	}
+
+	queryUser := `INSERT INTO profiles (id, fullname, phone, img) VALUES ($1, NULL, NULL, NULL);`
+	if _, err := tx.Exec(ctx, queryUser, userID); err != nil {
+		return 0, fmt.Errorf("failed to insert profiles = %w", err)
+	}
+
+	queryIdentity := `INSERT INTO account_identities (provider, subject, account_id, email) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(ctx, queryIdentity, provider, subject, userID, email); err != nil {
+		return 0, fmt.Errorf("failed to insert identity = %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return *id, *hashedPassword, nil
+	return userID, nil
 }
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+)
+
+// Block user, relasi follow dua arah ikut dihapus
+func (r *UserRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH target AS (
+			SELECT id FROM active_accounts WHERE id = $2
+		), blocked AS (
+			INSERT INTO blocks (blocker_id, blocked_id)
+			SELECT $1, id FROM target
+			ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM target)
+	`
+	var found bool
+	if err := tx.QueryRow(ctx, query, blockerID, blockedID).Scan(&found); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+
+	query = `
+		UPDATE followers SET deleted_at = NOW()
+		WHERE ((account_id = $1 AND follower_id = $2) OR (account_id = $2 AND follower_id = $1))
+		  AND deleted_at IS NULL
+	`
+	if _, err := tx.Exec(ctx, query, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to remove follows: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Unblock user
+func (r *UserRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// Cek apakah salah satu akun memblokir akun lainnya
+func (r *UserRepository) IsBlocked(ctx context.Context, a, b int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+	var blocked bool
+	if err := r.db.QueryRow(ctx, query, a, b).Scan(&blocked); err != nil {
+		return false, err
+	}
+	return blocked, nil
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Error domain yang dikembalikan repository. Code adalah kontrak dengan client,
+// jangan diubah setelah dirilis.
+var (
+	ErrAccountNotFound = models.NewNotFoundError("account_not_found", "account not found")
+	ErrUserNotFound    = models.NewNotFoundError("user_not_found", "user not found")
+	ErrPostNotFound    = models.NewNotFoundError("post_not_found", "post not found")
+
+	ErrEmailTaken    = models.NewConflictError("email_taken", "email is already registered")
+	ErrUsernameTaken = models.NewConflictError("username_taken", "username already taken")
+
+	ErrUserBlocked             = models.NewForbiddenError("user_blocked", "you cannot interact with this user")
+	ErrFollowListFollowersOnly = models.NewForbiddenError("follow_list_followers_only", "only followers can see this list")
+	ErrFollowListPrivate       = models.NewForbiddenError("follow_list_private", "this list is private")
+
+	ErrTokenInvalid = models.NewValidationError("token_invalid", "token is invalid or expired")
+)
+
+// 23505 unique_violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
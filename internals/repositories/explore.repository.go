@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Ambil post explore berdasarkan id. Post milik sendiri, akun yang sudah
+// diikuti, akun yang saling blokir dan akun nonaktif dibuang.
+func (r *PostRepository) GetExplorePosts(ctx context.Context, viewerID int, ids []int) ([]models.PostFeed, error) {
+	query := `
+		SELECT 
+			p.id, 
+			p.account_id,
+			pr.fullname, 
+			p.caption, 
+			COALESCE(ARRAY_AGG(DISTINCT pi.img) FILTER (WHERE pi.img IS NOT NULL AND pi.deleted_at IS NULL), '{}') AS images, 
+			COUNT(DISTINCT lk.id) AS like_count, 
+			COUNT(DISTINCT cm.id) AS comment_count,
+			p.created_at
+		FROM posts p
+		LEFT JOIN post_imgs pi ON p.id = pi.post_id
+		INNER JOIN active_accounts ac ON p.account_id = ac.id
+		INNER JOIN profiles pr ON ac.id = pr.id
+		LEFT JOIN likes lk ON p.id = lk.post_id AND lk.deleted_at IS NULL
+			AND lk.account_id IN (SELECT id FROM active_accounts)
+		LEFT JOIN comments cm ON p.id = cm.post_id AND cm.deleted_at IS NULL
+			AND cm.account_id IN (SELECT id FROM active_accounts)
+		WHERE p.id = ANY($2) AND p.deleted_at IS NULL AND p.account_id <> $1
+			AND NOT EXISTS (SELECT 1 FROM followers fl
+			                WHERE fl.account_id = p.account_id AND fl.follower_id = $1 AND fl.deleted_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM blocks b
+			                WHERE (b.blocker_id = $1 AND b.blocked_id = p.account_id)
+			                   OR (b.blocker_id = p.account_id AND b.blocked_id = $1))
+		GROUP BY p.id, pr.fullname, p.caption
+	`
+
+	rows, err := r.db.Query(ctx, query, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.PostFeed
+	for rows.Next() {
+		var post models.PostFeed
+		err := rows.Scan(
+			&post.ID,
+			&post.AuthorID,
+			&post.Fullname,
+			&post.Caption,
+			&post.Images,
+			&post.LikeCount,
+			&post.CommentCount,
+			&post.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Hitung skor explore semua post sejak since, dengan bobot dan decay yang sama
+// seperti yang dipakai di Redis (weight * e^((t - epoch) / tau))
+func (r *PostRepository) GetExploreSeed(ctx context.Context, since, epoch time.Time, tau, likeWeight, commentWeight float64) ([]models.ExploreSeed, error) {
+	query := `
+		SELECT p.id, p.created_at,
+		       COALESCE(SUM(e.weight * EXP(EXTRACT(EPOCH FROM (e.created_at - $2::timestamp)) / $3::float8)), 0)::float8
+		FROM posts p
+		INNER JOIN active_accounts ac ON ac.id = p.account_id
+		LEFT JOIN (
+			SELECT post_id, $4::float8 AS weight, created_at FROM likes
+			WHERE deleted_at IS NULL AND account_id IN (SELECT id FROM active_accounts)
+			UNION ALL
+			SELECT post_id, $5::float8 AS weight, created_at FROM comments
+			WHERE deleted_at IS NULL AND account_id IN (SELECT id FROM active_accounts)
+		) e ON e.post_id = p.id
+		WHERE p.deleted_at IS NULL AND p.created_at >= $1
+		GROUP BY p.id, p.created_at
+	`
+	rows, err := r.db.Query(ctx, query, since.UTC(), epoch.UTC(), tau, likeWeight, commentWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get explore seed: %w", err)
+	}
+	defer rows.Close()
+
+	var seeds []models.ExploreSeed
+	for rows.Next() {
+		var s models.ExploreSeed
+		if err := rows.Scan(&s.PostID, &s.CreatedAt, &s.Score); err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, s)
+	}
+	return seeds, rows.Err()
+}
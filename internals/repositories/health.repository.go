@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HealthRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHealthRepository(db *pgxpool.Pool) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
+// MigrationVersion membaca versi schema dari tabel milik golang-migrate.
+// Database yang belum pernah di-migrate (tabel belum ada) dianggap versi 0.
+func (r *HealthRepository) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = r.db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	var pgErr *pgconn.PgError
+	if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == "42P01") {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
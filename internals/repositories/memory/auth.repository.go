@@ -0,0 +1,333 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+type Auth struct {
+	db *DB
+}
+
+func NewAuthRepo(db *DB) *Auth {
+	return &Auth{db: db}
+}
+
+// INSERT INTO accounts, email UNIQUE
+func (db *DB) insertAccount(email, password string, verifiedAt *time.Time) (*account, error) {
+	for _, a := range db.accounts {
+		if a.email == email {
+			return nil, uniqueViolation("accounts_email_key")
+		}
+	}
+	a := &account{id: db.nextID("accounts"), email: email, password: password, verifiedAt: verifiedAt, createdAt: db.now()}
+	db.accounts[a.id] = a
+	db.profiles[a.id] = &profile{id: a.id, birthdateVisibility: "private", followListVisibility: "public"}
+	return a, nil
+}
+
+func (r *Auth) Register(ctx context.Context, email, password string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	a, err := r.db.insertAccount(email, password, nil)
+	if err != nil {
+		return 0, repositories.ErrEmailTaken.WithCause(err)
+	}
+	return a.id, nil
+}
+
+func (r *Auth) Login(ctx context.Context, email string) (*models.Account, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, a := range r.db.accounts {
+		if a.email == email && a.purgedAt == nil {
+			return &models.Account{ID: a.id, Email: a.email, Password: a.password, VerifiedAt: a.verifiedAt}, nil
+		}
+	}
+	return nil, repositories.ErrAccountNotFound
+}
+
+func (r *Auth) GetAccountByID(ctx context.Context, accountID int) (*models.Account, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	a, ok := r.db.accounts[accountID]
+	if !ok || a.purgedAt != nil {
+		return nil, repositories.ErrAccountNotFound
+	}
+	return &models.Account{ID: a.id, Email: a.email, Password: a.password, VerifiedAt: a.verifiedAt}, nil
+}
+
+func (r *Auth) IsEmailTaken(ctx context.Context, email string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, a := range r.db.accounts {
+		if a.email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Auth) RestoreAccount(ctx context.Context, accountID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[accountID]; ok && a.purgedAt == nil {
+		a.deleteAfter, a.deactivatedAt = nil, nil
+	}
+	return nil
+}
+
+func (r *Auth) UpdatePassword(ctx context.Context, accountID int, password string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[accountID]; ok {
+		a.password = password
+	}
+	return nil
+}
+
+func (r *Auth) UpdateEmail(ctx context.Context, accountID int, email string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, a := range r.db.accounts {
+		if a.email == email && a.id != accountID {
+			return repositories.ErrEmailTaken.WithCause(uniqueViolation("accounts_email_key"))
+		}
+	}
+	if a, ok := r.db.accounts[accountID]; ok {
+		a.email = email
+		a.verifiedAt = timePtr(r.db.now())
+	}
+	return nil
+}
+
+func (r *Auth) CreateAccountToken(ctx context.Context, accountID int, purpose, tokenHash string, payload *string, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(accountID) {
+		return fmt.Errorf("failed to insert account token: %w", foreignKeyViolation("account_tokens", "account_tokens_account_id_fkey"))
+	}
+	for _, t := range r.db.accountTokens {
+		if t.tokenHash == tokenHash {
+			return fmt.Errorf("failed to insert account token: %w", uniqueViolation("account_tokens_token_hash_key"))
+		}
+	}
+	r.db.accountTokens = append(r.db.accountTokens, &accountToken{
+		accountID: accountID, purpose: purpose, tokenHash: tokenHash, payload: payload, expiresAt: expiresAt,
+	})
+	return nil
+}
+
+func (r *Auth) ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := r.db.now()
+	for _, t := range r.db.accountTokens {
+		if t.tokenHash == tokenHash && t.purpose == purpose && t.usedAt == nil && t.expiresAt.After(now) {
+			t.usedAt = timePtr(now)
+			return &models.AccountToken{AccountID: t.accountID, Payload: t.payload}, nil
+		}
+	}
+	return nil, repositories.ErrTokenInvalid
+}
+
+func (r *Auth) VerifyEmail(ctx context.Context, accountID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[accountID]; ok && a.verifiedAt == nil {
+		a.verifiedAt = timePtr(r.db.now())
+	}
+	return nil
+}
+
+func (r *Auth) ResetPassword(ctx context.Context, accountID int, password string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[accountID]; ok {
+		a.password = password
+	}
+	now := r.db.now()
+	for _, t := range r.db.accountTokens {
+		if t.accountID == accountID && t.usedAt == nil {
+			t.usedAt = timePtr(now)
+		}
+	}
+	return nil
+}
+
+func (r *Auth) ChangePassword(ctx context.Context, accountID int, password string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[accountID]; ok {
+		a.password = password
+	}
+	now := r.db.now()
+	for _, t := range r.db.accountTokens {
+		if t.accountID == accountID && t.purpose == models.TokenPurposeResetPassword && t.usedAt == nil {
+			t.usedAt = timePtr(now)
+		}
+	}
+	return nil
+}
+
+func (r *Auth) GetMFA(ctx context.Context, accountID int) (*models.MFA, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	m, ok := r.db.mfa[accountID]
+	if !ok {
+		return nil, nil
+	}
+	return &models.MFA{AccountID: accountID, Secret: m.secret, LastUsedStep: m.lastUsedStep, EnabledAt: m.enabledAt}, nil
+}
+
+func (r *Auth) SaveMFASecret(ctx context.Context, accountID int, secret string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(accountID) {
+		return fmt.Errorf("failed to save mfa secret: %w", foreignKeyViolation("account_mfa", "account_mfa_account_id_fkey"))
+	}
+	// ON CONFLICT DO UPDATE ... WHERE enabled_at IS NULL
+	m, ok := r.db.mfa[accountID]
+	if !ok {
+		r.db.mfa[accountID] = &mfaRow{secret: secret}
+		return nil
+	}
+	if m.enabledAt == nil {
+		m.secret, m.lastUsedStep = secret, 0
+	}
+	return nil
+}
+
+func (r *Auth) EnableMFA(ctx context.Context, accountID int, step int64, codeHashes []string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// cek constraint dulu supaya tidak ada perubahan setengah jalan (rollback)
+	seen := make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		for _, c := range r.db.recoveryCodes {
+			if c.codeHash == hash && c.accountID != accountID {
+				seen[hash] = true
+			}
+		}
+		if seen[hash] {
+			return fmt.Errorf("failed to insert recovery code: %w", uniqueViolation("recovery_codes_code_hash_key"))
+		}
+		seen[hash] = true
+	}
+
+	if m, ok := r.db.mfa[accountID]; ok {
+		m.enabledAt = timePtr(r.db.now())
+		m.lastUsedStep = step
+	}
+
+	codes := r.db.recoveryCodes[:0]
+	for _, c := range r.db.recoveryCodes {
+		if c.accountID != accountID {
+			codes = append(codes, c)
+		}
+	}
+	r.db.recoveryCodes = codes
+	for _, hash := range codeHashes {
+		r.db.recoveryCodes = append(r.db.recoveryCodes, &recoveryCode{accountID: accountID, codeHash: hash})
+	}
+	return nil
+}
+
+func (r *Auth) UseMFAStep(ctx context.Context, accountID int, step int64) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	m, ok := r.db.mfa[accountID]
+	if !ok || m.lastUsedStep >= step {
+		return false, nil
+	}
+	m.lastUsedStep = step
+	return true, nil
+}
+
+func (r *Auth) UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, c := range r.db.recoveryCodes {
+		if c.accountID == accountID && c.codeHash == codeHash && c.usedAt == nil {
+			c.usedAt = timePtr(r.db.now())
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *Auth) DisableMFA(ctx context.Context, accountID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	codes := r.db.recoveryCodes[:0]
+	for _, c := range r.db.recoveryCodes {
+		if c.accountID != accountID {
+			codes = append(codes, c)
+		}
+	}
+	r.db.recoveryCodes = codes
+	delete(r.db.mfa, accountID)
+	return nil
+}
+
+func (r *Auth) GetAccountByIdentity(ctx context.Context, provider, subject string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if id, ok := r.db.identities[[2]string{provider, subject}]; ok {
+		return id.accountID, nil
+	}
+	return 0, nil
+}
+
+func (r *Auth) LinkIdentity(ctx context.Context, accountID int, provider, subject, email string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(accountID) {
+		return fmt.Errorf("failed to link identity: %w", foreignKeyViolation("account_identities", "account_identities_account_id_fkey"))
+	}
+	key := [2]string{provider, subject}
+	if _, ok := r.db.identities[key]; !ok {
+		r.db.identities[key] = &identity{accountID: accountID, email: email}
+	}
+	return nil
+}
+
+func (r *Auth) RegisterWithIdentity(ctx context.Context, email, provider, subject string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := [2]string{provider, subject}
+	if _, ok := r.db.identities[key]; ok {
+		return 0, fmt.Errorf("failed to insert identity = %w", uniqueViolation("account_identities_pk"))
+	}
+	a, err := r.db.insertAccount(email, "", timePtr(r.db.now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert accounts = %w", err)
+	}
+	r.db.identities[key] = &identity{accountID: a.id, email: email}
+	return a.id, nil
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+func (r *UserRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(blockerID) {
+		return fmt.Errorf("failed to block user: %w", foreignKeyViolation("blocks", "blocks_blocker_id_fkey"))
+	}
+	if !r.db.isActive(blockedID) {
+		return repositories.ErrUserNotFound
+	}
+	if _, ok := r.db.blocks[pair{blockerID, blockedID}]; !ok {
+		r.db.blocks[pair{blockerID, blockedID}] = r.db.now()
+	}
+
+	// relasi follow dua arah ikut dihapus
+	now := r.db.now()
+	for _, key := range []pair{{blockerID, blockedID}, {blockedID, blockerID}} {
+		if f, ok := r.db.followers[key]; ok && f.deletedAt == nil {
+			f.deletedAt = timePtr(now)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.blocks, pair{blockerID, blockedID})
+	return nil
+}
+
+func (r *UserRepository) IsBlocked(ctx context.Context, a, b int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.isBlocked(a, b), nil
+}
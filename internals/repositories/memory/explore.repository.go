@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+func (r *PostRepository) GetExplorePosts(ctx context.Context, viewerID int, ids []int) ([]models.PostFeed, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	var posts []models.PostFeed
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		p := db.visiblePost(id)
+		if seen[id] || p == nil || p.accountID == viewerID ||
+			db.isFollowing(p.accountID, viewerID) || db.isBlocked(viewerID, p.accountID) {
+			continue
+		}
+		seen[id] = true
+		posts = append(posts, db.feedItem(p))
+	}
+	return posts, nil
+}
+
+func (r *PostRepository) GetExploreSeed(ctx context.Context, since, epoch time.Time, tau, likeWeight, commentWeight float64) ([]models.ExploreSeed, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	decay := func(weight float64, at time.Time) float64 {
+		return weight * math.Exp(at.Sub(epoch).Seconds()/tau)
+	}
+
+	var seeds []models.ExploreSeed
+	for _, id := range sortedKeys(db.posts) {
+		p := db.visiblePost(id)
+		if p == nil || p.createdAt.Before(since) {
+			continue
+		}
+		seed := models.ExploreSeed{PostID: p.id, CreatedAt: p.createdAt}
+		for _, l := range db.likes {
+			if l.postID == id && l.deletedAt == nil && db.isActive(l.accountID) {
+				seed.Score += decay(likeWeight, l.createdAt)
+			}
+		}
+		for _, c := range db.comments {
+			if c.postID == id && c.deletedAt == nil && db.isActive(c.accountID) {
+				seed.Score += decay(commentWeight, c.createdAt)
+			}
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
@@ -0,0 +1,26 @@
+package memory
+
+import "context"
+
+type HealthRepository struct {
+	db *DB
+}
+
+func NewHealthRepository(db *DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.PingErr
+}
+
+func (r *HealthRepository) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	if r.db.PingErr != nil {
+		return 0, false, r.db.PingErr
+	}
+	return r.db.MigrationVersion, r.db.MigrationDirty, nil
+}
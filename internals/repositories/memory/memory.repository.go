@@ -0,0 +1,312 @@
+// Package memory berisi implementasi in-memory dari interface di package
+// repositories. Semantik soft delete, ON CONFLICT, constraint unique/foreign key
+// dan filter akun nonaktif mengikuti query Postgres, sehingga handler bisa
+// dites dengan httptest tanpa database.
+package memory
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	chatdb "github.com/ntisrangga142/chat/db"
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+type account struct {
+	id            int
+	email         string
+	password      string
+	verifiedAt    *time.Time
+	createdAt     time.Time
+	deleteAfter   *time.Time
+	deactivatedAt *time.Time
+	purgedAt      *time.Time
+}
+
+type profile struct {
+	id                   int
+	username             *string
+	usernameChangedAt    *time.Time
+	fullname             *string
+	phone                *string
+	img                  *string
+	coverImg             *string
+	bio                  *string
+	website              *string
+	location             *string
+	pronouns             *string
+	birthdate            *string
+	birthdateVisibility  string
+	followListVisibility string
+}
+
+type follow struct {
+	accountID  int
+	followerID int
+	read       bool
+	createdAt  time.Time
+	deletedAt  *time.Time
+}
+
+type post struct {
+	id        int
+	accountID int
+	caption   string
+	createdAt time.Time
+	deletedAt *time.Time
+}
+
+type postImg struct {
+	id        int
+	postID    int
+	img       string
+	deletedAt *time.Time
+}
+
+type like struct {
+	id        int
+	accountID int
+	postID    int
+	read      bool
+	createdAt time.Time
+	deletedAt *time.Time
+}
+
+type comment struct {
+	id        int
+	accountID int
+	postID    int
+	comment   string
+	read      bool
+	createdAt time.Time
+	deletedAt *time.Time
+}
+
+type mention struct {
+	id        int
+	accountID int
+	fromID    int
+	postID    int
+	commentID *int
+	read      bool
+	createdAt time.Time
+}
+
+type accountToken struct {
+	accountID int
+	purpose   string
+	tokenHash string
+	payload   *string
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+type recoveryCode struct {
+	accountID int
+	codeHash  string
+	usedAt    *time.Time
+}
+
+type identity struct {
+	accountID int
+	email     string
+}
+
+type usernameHistory struct {
+	accountID int
+	changedAt time.Time
+}
+
+// pasangan primary key dua kolom
+type pair [2]int
+
+// DB menyimpan semua tabel. Satu DB bisa dipakai bersama oleh beberapa
+// repository seperti satu pgxpool.Pool.
+type DB struct {
+	mu  sync.Mutex
+	seq map[string]int
+
+	// Now dipakai sebagai NOW(), bisa diganti untuk test dengan jam palsu
+	Now func() time.Time
+
+	// isi tabel schema_migrations dan hasil ping, untuk test readiness
+	MigrationVersion int64
+	MigrationDirty   bool
+	PingErr          error
+
+	accounts        map[int]*account
+	profiles        map[int]*profile
+	followers       map[pair]*follow // (account_id, follower_id)
+	blocks          map[pair]time.Time
+	dismissals      map[pair]time.Time
+	posts           map[int]*post
+	postImgs        []*postImg
+	likes           map[pair]*like // (account_id, post_id)
+	comments        []*comment
+	mentions        []*mention
+	saves           map[pair]time.Time // (account_id, post_id)
+	accountTokens   []*accountToken
+	mfa             map[int]*mfaRow
+	recoveryCodes   []*recoveryCode
+	identities      map[[2]string]*identity
+	usernameHistory map[string]*usernameHistory
+}
+
+type mfaRow struct {
+	secret       string
+	lastUsedStep int64
+	enabledAt    *time.Time
+}
+
+func NewDB() *DB {
+	return &DB{
+		seq:              make(map[string]int),
+		Now:              time.Now,
+		MigrationVersion: chatdb.LatestMigrationVersion(),
+		accounts:         make(map[int]*account),
+		profiles:         make(map[int]*profile),
+		followers:        make(map[pair]*follow),
+		blocks:           make(map[pair]time.Time),
+		dismissals:       make(map[pair]time.Time),
+		posts:            make(map[int]*post),
+		likes:            make(map[pair]*like),
+		saves:            make(map[pair]time.Time),
+		mfa:              make(map[int]*mfaRow),
+		identities:       make(map[[2]string]*identity),
+		usernameHistory:  make(map[string]*usernameHistory),
+	}
+}
+
+var (
+	_ repositories.AuthStore         = (*Auth)(nil)
+	_ repositories.UserStore         = (*UserRepository)(nil)
+	_ repositories.PostStore         = (*PostRepository)(nil)
+	_ repositories.NotificationStore = (*NotificationRepository)(nil)
+	_ repositories.HealthStore       = (*HealthRepository)(nil)
+)
+
+// NewStores memasang semua repository in-memory di atas satu DB
+func NewStores(db *DB) repositories.Stores {
+	return repositories.Stores{
+		Auth:         NewAuthRepo(db),
+		User:         NewUserRepository(db),
+		Post:         NewPostRepository(db),
+		Notification: NewNotificationRepository(db),
+		Health:       NewHealthRepository(db),
+	}
+}
+
+// GENERATED ALWAYS AS IDENTITY
+func (db *DB) nextID(table string) int {
+	db.seq[table]++
+	return db.seq[table]
+}
+
+// kolom TIMESTAMP Postgres menyimpan presisi mikrodetik
+func (db *DB) now() time.Time {
+	return db.Now().UTC().Truncate(time.Microsecond)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// view active_accounts
+func (db *DB) isActive(id int) bool {
+	a, ok := db.accounts[id]
+	return ok && a.deactivatedAt == nil && a.deleteAfter == nil && a.purgedAt == nil
+}
+
+func (db *DB) accountExists(id int) bool {
+	_, ok := db.accounts[id]
+	return ok
+}
+
+func (db *DB) isFollowing(accountID, followerID int) bool {
+	f, ok := db.followers[pair{accountID, followerID}]
+	return ok && f.deletedAt == nil
+}
+
+func (db *DB) isBlocked(a, b int) bool {
+	_, ab := db.blocks[pair{a, b}]
+	_, ba := db.blocks[pair{b, a}]
+	return ab || ba
+}
+
+func (db *DB) findProfileByUsername(username string) *profile {
+	for _, p := range db.profiles {
+		if p.username != nil && strings.EqualFold(*p.username, username) {
+			return p
+		}
+	}
+	return nil
+}
+
+// error yang sama dengan yang dikembalikan Postgres
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+func foreignKeyViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// ILIKE dengan escape backslash seperti utils.SearchPattern
+func ilike(s, pattern string) bool {
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String()).MatchString(s)
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
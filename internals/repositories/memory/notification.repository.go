@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+type NotificationRepository struct {
+	db *DB
+}
+
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Sama dengan UNION query Postgres: follow, like, comment dan mention yang
+// belum dibaca. Seperti versi Postgres, follow/like/comment tidak difilter
+// deleted_at.
+func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, userID int, cursorTime *time.Time, cursorID, limit int) (models.NotificationList, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	var notifications models.NotificationList
+	add := func(kind string, fromID int, postID *int, message string, n models.Notification) {
+		if cursorTime != nil && !(n.CreatedAt.Before(*cursorTime) || (n.CreatedAt.Equal(*cursorTime) && n.CursorID < cursorID)) {
+			return
+		}
+		name := deref(db.profiles[fromID].fullname)
+		n.Type, n.FromID, n.FromName = kind, fromID, name
+		if postID != nil {
+			id := *postID
+			n.PostID = &id
+		}
+		n.Message = name + message
+		notifications = append(notifications, n)
+	}
+
+	for _, f := range db.followers {
+		if f.accountID == userID && !f.read && db.isActive(f.followerID) {
+			add("follow", f.followerID, nil, " followed you", models.Notification{CreatedAt: f.createdAt, CursorID: f.followerID * 4})
+		}
+	}
+	for _, l := range db.likes {
+		if p, ok := db.posts[l.postID]; ok && p.accountID == userID && !l.read && db.isActive(l.accountID) {
+			add("like", l.accountID, &l.postID, " liked your post", models.Notification{CreatedAt: l.createdAt, CursorID: l.id*4 + 1})
+		}
+	}
+	for _, c := range db.comments {
+		if p, ok := db.posts[c.postID]; ok && p.accountID == userID && !c.read && db.isActive(c.accountID) {
+			add("comment", c.accountID, &c.postID, " commented: "+c.comment, models.Notification{CreatedAt: c.createdAt, CursorID: c.id*4 + 2})
+		}
+	}
+	for _, m := range db.mentions {
+		p, ok := db.posts[m.postID]
+		if !ok || p.deletedAt != nil || m.accountID != userID || m.read || !db.isActive(m.fromID) {
+			continue
+		}
+		message := " mentioned you in a post"
+		if m.commentID != nil {
+			message = " mentioned you in a comment"
+		}
+		add("mention", m.fromID, &m.postID, message, models.Notification{CreatedAt: m.createdAt, CursorID: m.id*4 + 3})
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		if !notifications[i].CreatedAt.Equal(notifications[j].CreatedAt) {
+			return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+		}
+		return notifications[i].CursorID > notifications[j].CursorID
+	})
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
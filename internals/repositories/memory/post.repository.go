@@ -0,0 +1,293 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/internals/utils"
+)
+
+type PostRepository struct {
+	db *DB
+}
+
+func NewPostRepository(db *DB) *PostRepository {
+	return &PostRepository{db: db}
+}
+
+// ARRAY_AGG(DISTINCT img) dari gambar yang belum dihapus
+func (db *DB) distinctImages(postID int) []string {
+	images := []string{}
+	for _, pi := range db.postImgs {
+		if pi.postID == postID && pi.deletedAt == nil && !slices.Contains(images, pi.img) {
+			images = append(images, pi.img)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// ARRAY_AGG(img) dari gambar yang belum dihapus, urut insert
+func (db *DB) activeImages(postID int) []string {
+	images := []string{}
+	for _, pi := range db.postImgs {
+		if pi.postID == postID && pi.deletedAt == nil {
+			images = append(images, pi.img)
+		}
+	}
+	return images
+}
+
+// jumlah like & komentar aktif dari akun aktif
+func (db *DB) engagementCounts(postID int) (likes, comments int) {
+	for _, l := range db.likes {
+		if l.postID == postID && l.deletedAt == nil && db.isActive(l.accountID) {
+			likes++
+		}
+	}
+	for _, c := range db.comments {
+		if c.postID == postID && c.deletedAt == nil && db.isActive(c.accountID) {
+			comments++
+		}
+	}
+	return likes, comments
+}
+
+func (db *DB) feedItem(p *post) models.PostFeed {
+	item := models.PostFeed{
+		ID:        p.id,
+		AuthorID:  p.accountID,
+		Fullname:  deref(db.profiles[p.accountID].fullname),
+		Caption:   p.caption,
+		Images:    db.distinctImages(p.id),
+		CreatedAt: p.createdAt,
+	}
+	item.LikeCount, item.CommentCount = db.engagementCounts(p.id)
+	return item
+}
+
+// post yang belum dihapus dan penulisnya aktif
+func (db *DB) visiblePost(postID int) *post {
+	p, ok := db.posts[postID]
+	if !ok || p.deletedAt != nil || !db.isActive(p.accountID) {
+		return nil
+	}
+	return p
+}
+
+// sama dengan ORDER BY p.created_at DESC, p.id DESC
+func sortFeedNewest(posts []models.PostFeed) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+		return posts[i].ID > posts[j].ID
+	})
+}
+
+func (r *PostRepository) GetFollowingPosts(ctx context.Context, followerID int, cursorTime *time.Time, cursorID, limit int) ([]models.PostFeed, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var posts []models.PostFeed
+	for _, id := range sortedKeys(r.db.posts) {
+		p := r.db.visiblePost(id)
+		if p == nil || !r.db.isFollowing(p.accountID, followerID) {
+			continue
+		}
+		if cursorTime != nil && !(p.createdAt.Before(*cursorTime) || (p.createdAt.Equal(*cursorTime) && p.id < cursorID)) {
+			continue
+		}
+		posts = append(posts, r.db.feedItem(p))
+	}
+	sortFeedNewest(posts)
+	if limit > 0 && len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
+
+func (r *PostRepository) GetPostDetail(ctx context.Context, postID int) (*models.PostDetail, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	p := db.visiblePost(postID)
+	if p == nil {
+		return nil, repositories.ErrPostNotFound
+	}
+	author := db.profiles[p.accountID]
+	post := models.PostDetail{
+		ID:        p.id,
+		Caption:   p.caption,
+		CreatedAt: p.createdAt,
+		Author:    models.AuthorProfile{ID: author.id, Fullname: deref(author.fullname), Img: deref(author.img)},
+		Images:    db.distinctImages(p.id),
+	}
+	post.Likes, _ = db.engagementCounts(p.id)
+
+	var comments []*comment
+	for _, c := range db.comments {
+		if c.postID == postID && c.deletedAt == nil && db.isActive(c.accountID) {
+			comments = append(comments, c)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].createdAt.After(comments[j].createdAt) })
+	for _, c := range comments[:min(len(comments), 5)] {
+		post.Comments = append(post.Comments, models.CommentPreview{
+			ID:        c.id,
+			Fullname:  deref(db.profiles[c.accountID].fullname),
+			Comment:   c.comment,
+			CreatedAt: c.createdAt,
+		})
+	}
+	return &post, nil
+}
+
+func (r *PostRepository) CreatePost(ctx context.Context, req models.CreatePostRequest, accountID int) (*models.Post, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	if !db.accountExists(accountID) {
+		return nil, fmt.Errorf("failed to insert post: %w", foreignKeyViolation("posts", "posts_account_id_fkey"))
+	}
+
+	p := &post{id: db.nextID("posts"), accountID: accountID, caption: req.Caption, createdAt: db.now()}
+	db.posts[p.id] = p
+	for _, img := range req.Images {
+		db.postImgs = append(db.postImgs, &postImg{id: db.nextID("post_imgs"), postID: p.id, img: img})
+	}
+	db.insertMentions(accountID, p.id, nil, req.Caption)
+
+	return &models.Post{
+		ID:        p.id,
+		AccountID: accountID,
+		Caption:   req.Caption,
+		Images:    make([]models.PostImg, 0),
+		CreatedAt: p.createdAt,
+	}, nil
+}
+
+func (r *PostRepository) CreateLike(ctx context.Context, accountID int, postID int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	if !db.accountExists(accountID) {
+		return false, fmt.Errorf("failed to like post: %w", foreignKeyViolation("likes", "likes_account_id_fkey"))
+	}
+	if db.visiblePost(postID) == nil {
+		return false, repositories.ErrPostNotFound
+	}
+
+	// ON CONFLICT DO UPDATE ... WHERE likes.deleted_at IS NOT NULL
+	key := pair{accountID, postID}
+	if l, ok := db.likes[key]; ok {
+		if l.deletedAt == nil {
+			return false, nil
+		}
+		l.deletedAt, l.read, l.createdAt = nil, false, db.now()
+		return true, nil
+	}
+	db.likes[key] = &like{id: db.nextID("likes"), accountID: accountID, postID: postID, createdAt: db.now()}
+	return true, nil
+}
+
+func (r *PostRepository) DeleteLike(ctx context.Context, accountID, postID int) (*time.Time, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	l, ok := r.db.likes[pair{accountID, postID}]
+	if !ok || l.deletedAt != nil {
+		return nil, nil
+	}
+	l.deletedAt = timePtr(r.db.now())
+	likedAt := l.createdAt
+	return &likedAt, nil
+}
+
+func (r *PostRepository) CreateComment(ctx context.Context, accountID int, req models.CreateCommentRequest) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	if !db.accountExists(accountID) {
+		return fmt.Errorf("failed to insert comment: %w", foreignKeyViolation("comments", "comments_account_id_fkey"))
+	}
+	if db.visiblePost(req.PostID) == nil {
+		return repositories.ErrPostNotFound
+	}
+
+	c := &comment{id: db.nextID("comments"), accountID: accountID, postID: req.PostID, comment: req.Comment, createdAt: db.now()}
+	db.comments = append(db.comments, c)
+	db.insertMentions(accountID, req.PostID, &c.id, req.Comment)
+	return nil
+}
+
+// Simpan @mention yang cocok dengan handle akun aktif (selain penulis)
+func (db *DB) insertMentions(fromID, postID int, commentID *int, text string) {
+	for _, handle := range utils.ExtractMentions(text) {
+		p := db.findProfileByUsername(handle)
+		if p == nil || p.id == fromID || !db.isActive(p.id) {
+			continue
+		}
+		db.mentions = append(db.mentions, &mention{
+			id: db.nextID("mentions"), accountID: p.id, fromID: fromID, postID: postID, commentID: commentID, createdAt: db.now(),
+		})
+	}
+}
+
+func (r *PostRepository) GetAllCommentsByPost(ctx context.Context, postID int) ([]models.Comment, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	if db.visiblePost(postID) == nil {
+		return nil, repositories.ErrPostNotFound
+	}
+
+	var list []*comment
+	for _, c := range db.comments {
+		if c.postID == postID && c.deletedAt == nil && db.isActive(c.accountID) {
+			list = append(list, c)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].createdAt.Before(list[j].createdAt) })
+
+	var comments []models.Comment
+	for _, c := range list {
+		comments = append(comments, models.Comment{ID: c.id, AccountID: c.accountID, PostID: c.postID, Comment: c.comment})
+	}
+	return comments, nil
+}
+
+func (r *PostRepository) GetAuthorAffinity(ctx context.Context, viewerID int) (map[int]int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	since := db.now().Add(-90 * 24 * time.Hour)
+	affinity := make(map[int]int)
+	count := func(postID int, createdAt time.Time) {
+		if p, ok := db.posts[postID]; ok && p.accountID != viewerID && createdAt.After(since) {
+			affinity[p.accountID]++
+		}
+	}
+	for _, l := range db.likes {
+		if l.accountID == viewerID && l.deletedAt == nil {
+			count(l.postID, l.createdAt)
+		}
+	}
+	for _, c := range db.comments {
+		if c.accountID == viewerID && c.deletedAt == nil {
+			count(c.postID, c.createdAt)
+		}
+	}
+	return affinity, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+func (r *PostRepository) SavePost(ctx context.Context, accountID, postID int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.db.visiblePost(postID) == nil {
+		return false, nil
+	}
+	if !r.db.accountExists(accountID) {
+		return false, fmt.Errorf("failed to save post: %w", foreignKeyViolation("saves", "saves_account_id_fkey"))
+	}
+	// ON CONFLICT DO NOTHING
+	if _, ok := r.db.saves[pair{accountID, postID}]; !ok {
+		r.db.saves[pair{accountID, postID}] = r.db.now()
+	}
+	return true, nil
+}
+
+func (r *PostRepository) UnsavePost(ctx context.Context, accountID, postID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.saves, pair{accountID, postID})
+	return nil
+}
+
+func (r *PostRepository) GetSavedPosts(ctx context.Context, accountID int, cursorTime *time.Time, cursorID, limit int) ([]models.SavedPost, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	posts := []models.SavedPost{}
+	for key, savedAt := range db.saves {
+		if key[0] != accountID {
+			continue
+		}
+		p := db.visiblePost(key[1])
+		if p == nil || db.isBlocked(accountID, p.accountID) {
+			continue
+		}
+		if cursorTime != nil && !(savedAt.Before(*cursorTime) || (savedAt.Equal(*cursorTime) && p.id < cursorID)) {
+			continue
+		}
+		posts = append(posts, models.SavedPost{PostFeed: db.feedItem(p), SavedAt: savedAt})
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].SavedAt.Equal(posts[j].SavedAt) {
+			return posts[i].SavedAt.After(posts[j].SavedAt)
+		}
+		return posts[i].ID > posts[j].ID
+	})
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return posts, nil
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+// sama dengan regexp_matches(caption, '#([[:alnum:]_]+)', 'g')
+var hashtagPattern = regexp.MustCompile(`#([[:alnum:]_]+)`)
+
+func hashtags(caption string) []string {
+	var tags []string
+	for _, m := range hashtagPattern.FindAllStringSubmatch(caption, -1) {
+		tags = append(tags, strings.ToLower(m[1]))
+	}
+	return tags
+}
+
+// padanan suggestionExclusions
+func (db *DB) suggestable(uid, candidate int) bool {
+	if candidate == uid || db.isFollowing(candidate, uid) || db.isBlocked(uid, candidate) {
+		return false
+	}
+	_, dismissed := db.dismissals[pair{uid, candidate}]
+	return !dismissed
+}
+
+func (r *UserRepository) ComputeSuggestions(ctx context.Context, uid, limit int) ([]models.SuggestionScore, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	now := db.now()
+
+	// friends-of-friends
+	fof := make(map[int]int)
+	for _, f := range db.followers {
+		if f.deletedAt == nil && db.isFollowing(f.followerID, uid) {
+			fof[f.accountID]++
+		}
+	}
+
+	// hashtag dari post & like viewer
+	myTags := make(map[string]bool)
+	for _, p := range db.posts {
+		if p.accountID == uid && p.deletedAt == nil {
+			for _, t := range hashtags(p.caption) {
+				myTags[t] = true
+			}
+		}
+	}
+	for _, l := range db.likes {
+		if p := db.posts[l.postID]; l.accountID == uid && l.deletedAt == nil && p != nil && p.deletedAt == nil {
+			for _, t := range hashtags(p.caption) {
+				myTags[t] = true
+			}
+		}
+	}
+	shared := make(map[int]map[string]bool)
+	for _, p := range db.posts {
+		if p.deletedAt != nil || !p.createdAt.After(now.Add(-90*24*time.Hour)) {
+			continue
+		}
+		for _, t := range hashtags(p.caption) {
+			if myTags[t] {
+				if shared[p.accountID] == nil {
+					shared[p.accountID] = make(map[string]bool)
+				}
+				shared[p.accountID][t] = true
+			}
+		}
+	}
+
+	// like & komentar 30 hari terakhir dari akun aktif
+	engagement := make(map[int]int)
+	since := now.Add(-30 * 24 * time.Hour)
+	count := func(postID, accountID int, createdAt time.Time) {
+		if p := db.posts[postID]; p != nil && p.deletedAt == nil && createdAt.After(since) && db.isActive(accountID) {
+			engagement[p.accountID]++
+		}
+	}
+	for _, l := range db.likes {
+		if l.deletedAt == nil {
+			count(l.postID, l.accountID, l.createdAt)
+		}
+	}
+	for _, c := range db.comments {
+		if c.deletedAt == nil {
+			count(c.postID, c.accountID, c.createdAt)
+		}
+	}
+
+	scores := []models.SuggestionScore{}
+	for _, id := range sortedKeys(db.accounts) {
+		if !db.isActive(id) || !db.suggestable(uid, id) {
+			continue
+		}
+		mutuals, hasFof := fof[id]
+		tags, hasTags := shared[id]
+		interactions, hasEngagement := engagement[id]
+		if !hasFof && !hasTags && !hasEngagement {
+			continue
+		}
+		scores = append(scores, models.SuggestionScore{
+			ID: id,
+			Score: float64(mutuals)*repositories.SuggestionMutualWeight +
+				float64(len(tags))*repositories.SuggestionHashtagWeight +
+				math.Log(1+float64(interactions))*repositories.SuggestionEngagementWeight,
+		})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores, nil
+}
+
+func (r *UserRepository) GetSuggestedProfiles(ctx context.Context, uid int, ids []int) ([]models.Suggestion, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	suggestions := []models.Suggestion{}
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		p := db.profiles[id]
+		if seen[id] || p == nil || !db.isActive(id) || !db.suggestable(uid, id) {
+			continue
+		}
+		seen[id] = true
+
+		mutual := 0
+		for _, f := range db.followers {
+			if f.accountID == id && f.deletedAt == nil && db.isFollowing(f.followerID, uid) {
+				mutual++
+			}
+		}
+		suggestions = append(suggestions, models.Suggestion{
+			ID:          id,
+			Username:    deref(p.username),
+			Fullname:    deref(p.fullname),
+			Img:         deref(p.img),
+			MutualCount: mutual,
+			FollowsYou:  db.isFollowing(uid, id),
+		})
+	}
+	return suggestions, nil
+}
+
+func (r *UserRepository) DismissSuggestion(ctx context.Context, uid, dismissedID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(uid) {
+		return fmt.Errorf("failed to dismiss suggestion: %w", foreignKeyViolation("suggestion_dismissals", "suggestion_dismissals_account_id_fkey"))
+	}
+	if !r.db.isActive(dismissedID) {
+		return repositories.ErrUserNotFound
+	}
+	if _, ok := r.db.dismissals[pair{uid, dismissedID}]; !ok {
+		r.db.dismissals[pair{uid, dismissedID}] = r.db.now()
+	}
+	return nil
+}
+
+func (r *UserRepository) GetActiveAccountIDs(ctx context.Context, afterID, limit int) ([]int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var ids []int
+	for _, id := range sortedKeys(r.db.accounts) {
+		if len(ids) == limit {
+			break
+		}
+		if id > afterID && r.db.isActive(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,510 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+type UserRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// jumlah follower & following dari akun aktif
+func (db *DB) followCounts(uid int) (followers, following int) {
+	for _, f := range db.followers {
+		if f.deletedAt != nil {
+			continue
+		}
+		if f.accountID == uid && db.isActive(f.followerID) {
+			followers++
+		}
+		if f.followerID == uid && db.isActive(f.accountID) {
+			following++
+		}
+	}
+	return followers, following
+}
+
+func (db *DB) getProfile(uid int) (*models.Profile, error) {
+	p, ok := db.profiles[uid]
+	if !ok {
+		return nil, repositories.ErrUserNotFound
+	}
+	profile := models.Profile{
+		Username:             p.username,
+		FullName:             p.fullname,
+		PhoneNumber:          p.phone,
+		Img:                  p.img,
+		CoverImg:             p.coverImg,
+		Bio:                  p.bio,
+		Website:              p.website,
+		Location:             p.location,
+		Pronouns:             p.pronouns,
+		Birthdate:            p.birthdate,
+		BirthdateVisibility:  p.birthdateVisibility,
+		FollowListVisibility: p.followListVisibility,
+	}
+	profile.FollowerCount, profile.FollowingCount = db.followCounts(uid)
+	return &profile, nil
+}
+
+func (ur *UserRepository) GetProfile(ctx context.Context, uid int) (*models.Profile, error) {
+	ur.db.mu.Lock()
+	defer ur.db.mu.Unlock()
+
+	return ur.db.getProfile(uid)
+}
+
+func (ur *UserRepository) UpdateProfile(ctx context.Context, uid int, updates map[string]any) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	ur.db.mu.Lock()
+	defer ur.db.mu.Unlock()
+
+	p, ok := ur.db.profiles[uid]
+	if !ok {
+		return nil
+	}
+
+	// validasi semua kolom dulu, UPDATE gagal seluruhnya jika ada kolom yang salah
+	fields := map[string]**string{
+		"fullname":  &p.fullname,
+		"phone":     &p.phone,
+		"img":       &p.img,
+		"cover_img": &p.coverImg,
+		"bio":       &p.bio,
+		"website":   &p.website,
+		"location":  &p.location,
+		"pronouns":  &p.pronouns,
+		"birthdate": &p.birthdate,
+	}
+	enums := map[string]*string{
+		"birthdate_visibility":   &p.birthdateVisibility,
+		"follow_list_visibility": &p.followListVisibility,
+	}
+	for col, val := range updates {
+		_, isField := fields[col]
+		_, isEnum := enums[col]
+		if !isField && !isEnum {
+			return &pgconn.PgError{Severity: "ERROR", Code: "42703", Message: fmt.Sprintf("column %q of relation \"profiles\" does not exist", col)}
+		}
+		if _, isString := val.(string); val != nil && !isString {
+			return fmt.Errorf("unsupported value for column %s: %T", col, val)
+		}
+		if isEnum {
+			s, _ := val.(string)
+			if s != "public" && s != "followers" && s != "private" {
+				return &pgconn.PgError{Severity: "ERROR", Code: "23514", Message: fmt.Sprintf("new row for relation \"profiles\" violates check constraint on %s", col)}
+			}
+		}
+	}
+
+	for col, val := range updates {
+		s, _ := val.(string)
+		if enum, ok := enums[col]; ok {
+			*enum = s
+			continue
+		}
+		if val == nil {
+			*fields[col] = nil
+		} else {
+			*fields[col] = strPtr(s)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) Follow(ctx context.Context, accountID, followerID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !r.db.accountExists(followerID) {
+		return fmt.Errorf("failed to follow user: %w", foreignKeyViolation("followers", "followers_follower_id_fkey"))
+	}
+	if !r.db.isActive(accountID) {
+		return repositories.ErrUserNotFound
+	}
+	// ON CONFLICT (account_id, follower_id) DO UPDATE SET deleted_at = NULL
+	key := pair{accountID, followerID}
+	if f, ok := r.db.followers[key]; ok {
+		f.deletedAt = nil
+		return nil
+	}
+	r.db.followers[key] = &follow{accountID: accountID, followerID: followerID, createdAt: r.db.now()}
+	return nil
+}
+
+func (r *UserRepository) Unfollow(ctx context.Context, accountID, followerID int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if f, ok := r.db.followers[pair{accountID, followerID}]; ok {
+		f.deletedAt = timePtr(r.db.now())
+	}
+	return nil
+}
+
+// jenis list untuk listFollows
+const (
+	listFollowers = iota
+	listFollowing
+	listMutual
+)
+
+// padanan listFollows Postgres: filter akun aktif, pencarian ILIKE,
+// keyset (followed_at, id) menurun
+func (db *DB) listFollows(kind, ownerID, viewerID int, q models.FollowQuery) []models.Follow {
+	items := []models.Follow{}
+	for _, f := range db.followers {
+		if f.deletedAt != nil {
+			continue
+		}
+		var other int
+		switch kind {
+		case listFollowers, listMutual:
+			if f.accountID != ownerID {
+				continue
+			}
+			other = f.followerID
+		case listFollowing:
+			if f.followerID != ownerID {
+				continue
+			}
+			other = f.accountID
+		}
+		if kind == listMutual && !db.isFollowing(other, viewerID) {
+			continue
+		}
+		if !db.isActive(other) {
+			continue
+		}
+		p := db.profiles[other]
+		if p == nil {
+			continue
+		}
+		if q.Search != "" &&
+			!(p.fullname != nil && ilike(*p.fullname, q.Search)) &&
+			!(p.username != nil && ilike(*p.username, q.Search)) {
+			continue
+		}
+		if q.CursorTime != nil &&
+			!(f.createdAt.Before(*q.CursorTime) || (f.createdAt.Equal(*q.CursorTime) && other < q.CursorID)) {
+			continue
+		}
+		items = append(items, models.Follow{
+			ID:          other,
+			Username:    deref(p.username),
+			Fullname:    deref(p.fullname),
+			Img:         deref(p.img),
+			FollowedAt:  f.createdAt,
+			IsFollowing: db.isFollowing(other, viewerID),
+			FollowsYou:  db.isFollowing(viewerID, other),
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].FollowedAt.Equal(items[j].FollowedAt) {
+			return items[i].FollowedAt.After(items[j].FollowedAt)
+		}
+		return items[i].ID > items[j].ID
+	})
+	if q.Limit >= 0 && len(items) > q.Limit {
+		items = items[:q.Limit]
+	}
+	return items
+}
+
+func (r *UserRepository) GetFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.listFollows(listFollowers, accountID, viewerID, q), nil
+}
+
+func (r *UserRepository) GetFollowing(ctx context.Context, followerID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.listFollows(listFollowing, followerID, viewerID, q), nil
+}
+
+func (r *UserRepository) GetMutualFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.listFollows(listMutual, accountID, viewerID, q), nil
+}
+
+func (r *UserRepository) CountMutualFollowers(ctx context.Context, accountID, viewerID int) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return len(r.db.listFollows(listMutual, accountID, viewerID, models.FollowQuery{Limit: math.MaxInt32})), nil
+}
+
+func (r *UserRepository) GetFollowListAccess(ctx context.Context, accountID, viewerID int) (visibility string, viewerFollows bool, found bool, err error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p, ok := r.db.profiles[accountID]
+	if !ok || !r.db.isActive(accountID) {
+		return "", false, false, nil
+	}
+	return p.followListVisibility, r.db.isFollowing(accountID, viewerID), true, nil
+}
+
+func (r *UserRepository) GetAccountPassword(ctx context.Context, uid int) (string, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	a, ok := r.db.accounts[uid]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return a.password, nil
+}
+
+func (r *UserRepository) Deactivate(ctx context.Context, uid int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[uid]; ok && a.purgedAt == nil {
+		a.deactivatedAt = timePtr(r.db.now())
+	}
+	return nil
+}
+
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, uid int, deleteAfter time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if a, ok := r.db.accounts[uid]; ok && a.purgedAt == nil {
+		a.deleteAfter = timePtr(deleteAfter)
+	}
+	return nil
+}
+
+func (r *UserRepository) GetAccountsDueForPurge(ctx context.Context, limit int) ([]int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := r.db.now()
+	var due []*account
+	for _, a := range r.db.accounts {
+		if a.deleteAfter != nil && !a.deleteAfter.After(now) && a.purgedAt == nil {
+			due = append(due, a)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].deleteAfter.Before(*due[j].deleteAfter) })
+
+	var ids []int
+	for _, a := range due {
+		if len(ids) == limit {
+			break
+		}
+		ids = append(ids, a.id)
+	}
+	return ids, nil
+}
+
+func (r *UserRepository) PurgeAccount(ctx context.Context, uid int) (*models.PurgedAccount, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	a, ok := db.accounts[uid]
+	if !ok {
+		return nil, fmt.Errorf("failed to lock account: %w", pgx.ErrNoRows)
+	}
+	if a.purgedAt != nil {
+		return nil, nil
+	}
+
+	var purged models.PurgedAccount
+	ownPosts := make(map[int]bool)
+	for id, p := range db.posts {
+		if p.accountID == uid {
+			ownPosts[id] = true
+			purged.PostIDs = append(purged.PostIDs, id)
+		}
+	}
+
+	for _, pi := range db.postImgs {
+		if ownPosts[pi.postID] {
+			purged.PostImgs = append(purged.PostImgs, pi.img)
+		}
+	}
+	for key, f := range db.followers {
+		if key[0] == uid && f.deletedAt == nil {
+			purged.FollowerIDs = append(purged.FollowerIDs, key[1])
+		}
+	}
+	p := db.profiles[uid]
+	purged.ProfileImg, purged.CoverImg = p.img, p.coverImg
+
+	mentions := db.mentions[:0]
+	for _, m := range db.mentions {
+		if m.accountID != uid && m.fromID != uid && !ownPosts[m.postID] {
+			mentions = append(mentions, m)
+		}
+	}
+	db.mentions = mentions
+	for key := range db.saves {
+		if key[0] == uid || ownPosts[key[1]] {
+			delete(db.saves, key)
+		}
+	}
+	// like & komentar orang lain di post milik akun ikut terhapus bersama post
+	for key, l := range db.likes {
+		if l.accountID == uid || ownPosts[l.postID] {
+			delete(db.likes, key)
+		}
+	}
+	comments := db.comments[:0]
+	for _, c := range db.comments {
+		if !ownPosts[c.postID] {
+			comments = append(comments, c)
+		}
+	}
+	db.comments = comments
+	imgs := db.postImgs[:0]
+	for _, pi := range db.postImgs {
+		if !ownPosts[pi.postID] {
+			imgs = append(imgs, pi)
+		}
+	}
+	db.postImgs = imgs
+	for id := range ownPosts {
+		delete(db.posts, id)
+	}
+	// komentar di post orang lain dianonimkan
+	for _, c := range db.comments {
+		if c.accountID == uid {
+			c.comment = "[deleted]"
+		}
+	}
+	for key := range db.followers {
+		if key[0] == uid || key[1] == uid {
+			delete(db.followers, key)
+		}
+	}
+	for key := range db.blocks {
+		if key[0] == uid || key[1] == uid {
+			delete(db.blocks, key)
+		}
+	}
+	for key := range db.dismissals {
+		if key[0] == uid || key[1] == uid {
+			delete(db.dismissals, key)
+		}
+	}
+	tokens := db.accountTokens[:0]
+	for _, t := range db.accountTokens {
+		if t.accountID != uid {
+			tokens = append(tokens, t)
+		}
+	}
+	db.accountTokens = tokens
+	codes := db.recoveryCodes[:0]
+	for _, c := range db.recoveryCodes {
+		if c.accountID != uid {
+			codes = append(codes, c)
+		}
+	}
+	db.recoveryCodes = codes
+	delete(db.mfa, uid)
+	for key, id := range db.identities {
+		if id.accountID == uid {
+			delete(db.identities, key)
+		}
+	}
+	for name, h := range db.usernameHistory {
+		if h.accountID == uid {
+			delete(db.usernameHistory, name)
+		}
+	}
+
+	*p = profile{
+		id:                   uid,
+		fullname:             strPtr("Deleted User"),
+		usernameChangedAt:    p.usernameChangedAt,
+		birthdateVisibility:  p.birthdateVisibility,
+		followListVisibility: p.followListVisibility,
+	}
+	a.email = fmt.Sprintf("deleted-%d@deleted.invalid", uid)
+	a.password = ""
+	a.verifiedAt = nil
+	a.deleteAfter = nil
+	a.purgedAt = timePtr(db.now())
+
+	return &purged, nil
+}
+
+func (r *UserRepository) GetExportData(ctx context.Context, uid int) (*models.UserExport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	var data models.UserExport
+
+	a, ok := db.accounts[uid]
+	if !ok {
+		return nil, fmt.Errorf("failed get account: %w", pgx.ErrNoRows)
+	}
+	data.Account = models.ExportAccount{ID: a.id, Email: a.email, CreatedAt: a.createdAt}
+
+	profile, err := db.getProfile(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed get profile: %w", err)
+	}
+	data.Profile = *profile
+
+	for _, id := range sortedKeys(db.posts) {
+		p := db.posts[id]
+		if p.accountID != uid || p.deletedAt != nil {
+			continue
+		}
+		data.Posts = append(data.Posts, models.ExportPost{ID: p.id, Caption: p.caption, CreatedAt: p.createdAt, Images: db.activeImages(p.id)})
+	}
+	sort.SliceStable(data.Posts, func(i, j int) bool { return data.Posts[i].CreatedAt.Before(data.Posts[j].CreatedAt) })
+
+	for _, c := range db.comments {
+		if c.accountID == uid && c.deletedAt == nil {
+			data.Comments = append(data.Comments, models.ExportComment{ID: c.id, PostID: c.postID, Comment: c.comment, CreatedAt: c.createdAt})
+		}
+	}
+	sort.SliceStable(data.Comments, func(i, j int) bool { return data.Comments[i].CreatedAt.Before(data.Comments[j].CreatedAt) })
+
+	for _, l := range db.likes {
+		if l.accountID == uid && l.deletedAt == nil {
+			data.Likes = append(data.Likes, models.ExportLike{PostID: l.postID, CreatedAt: l.createdAt})
+		}
+	}
+	sort.Slice(data.Likes, func(i, j int) bool {
+		if !data.Likes[i].CreatedAt.Equal(data.Likes[j].CreatedAt) {
+			return data.Likes[i].CreatedAt.Before(data.Likes[j].CreatedAt)
+		}
+		return data.Likes[i].PostID < data.Likes[j].PostID
+	})
+
+	// export berisi seluruh list, tanpa pagination
+	all := models.FollowQuery{Limit: math.MaxInt32}
+	data.Followers = db.listFollows(listFollowers, uid, uid, all)
+	data.Following = db.listFollows(listFollowing, uid, uid, all)
+
+	return &data, nil
+}
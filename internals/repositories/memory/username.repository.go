@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/repositories"
+)
+
+func (r *UserRepository) GetUsername(ctx context.Context, uid int) (*string, *time.Time, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p, ok := r.db.profiles[uid]
+	if !ok {
+		return nil, nil, pgx.ErrNoRows
+	}
+	return p.username, p.usernameChangedAt, nil
+}
+
+func (r *UserRepository) IsUsernameTaken(ctx context.Context, uid int, username string, holdPeriod time.Duration) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if p := r.db.findProfileByUsername(username); p != nil && p.id != uid {
+		return true, nil
+	}
+	h, ok := r.db.usernameHistory[strings.ToLower(username)]
+	return ok && h.accountID != uid && h.changedAt.After(r.db.now().Add(-holdPeriod)), nil
+}
+
+func (r *UserRepository) ChangeUsername(ctx context.Context, uid int, username string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p, ok := r.db.profiles[uid]
+	if !ok {
+		return fmt.Errorf("failed to get username: %w", pgx.ErrNoRows)
+	}
+	// unique index LOWER(username)
+	if other := r.db.findProfileByUsername(username); other != nil && other.id != uid {
+		return repositories.ErrUsernameTaken
+	}
+
+	now := r.db.now()
+	if p.username != nil {
+		r.db.usernameHistory[strings.ToLower(*p.username)] = &usernameHistory{accountID: uid, changedAt: now}
+	}
+	// handle baru tidak lagi menjadi redirect ke akun manapun
+	delete(r.db.usernameHistory, strings.ToLower(username))
+
+	p.username = strPtr(username)
+	p.usernameChangedAt = timePtr(now)
+	return nil
+}
+
+func (r *UserRepository) GetProfileByUsername(ctx context.Context, viewerID int, username string) (*models.PublicProfile, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p := r.db.findProfileByUsername(username)
+	if p == nil || !r.db.isActive(p.id) {
+		return nil, nil
+	}
+
+	profile := models.PublicProfile{
+		ID:       p.id,
+		Username: *p.username,
+		FullName: p.fullname,
+		Img:      p.img,
+		CoverImg: p.coverImg,
+		Bio:      p.bio,
+		Website:  p.website,
+		Location: p.location,
+		Pronouns: p.pronouns,
+	}
+	if p.id == viewerID || p.birthdateVisibility == "public" ||
+		(p.birthdateVisibility == "followers" && r.db.isFollowing(p.id, viewerID)) {
+		profile.Birthdate = p.birthdate
+	}
+	profile.FollowerCount, profile.FollowingCount = r.db.followCounts(p.id)
+	return &profile, nil
+}
+
+func (r *UserRepository) GetUsernameRedirect(ctx context.Context, username string) (string, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	h, ok := r.db.usernameHistory[strings.ToLower(username)]
+	if !ok || !r.db.isActive(h.accountID) {
+		return "", nil
+	}
+	p := r.db.profiles[h.accountID]
+	if p == nil || p.username == nil {
+		return "", nil
+	}
+	return *p.username, nil
+}
+
+func (r *UserRepository) GetIDByUsername(ctx context.Context, username string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p := r.db.findProfileByUsername(username)
+	if p == nil || !r.db.isActive(p.id) {
+		return 0, nil
+	}
+	return p.id, nil
+}
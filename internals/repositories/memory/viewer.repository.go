@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// jumlah nama pada preview "disukai oleh", sama dengan versi Postgres
+const likedByPreviewSize = 2
+
+func (r *PostRepository) GetViewerStates(ctx context.Context, viewerID int, postIDs []int) (map[int]*models.ViewerState, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	db := r.db
+	states := make(map[int]*models.ViewerState, len(postIDs))
+	for _, id := range postIDs {
+		p, ok := db.posts[id]
+		if !ok {
+			continue
+		}
+
+		state := models.ViewerState{IsOwner: p.accountID == viewerID}
+		if l, ok := db.likes[pair{viewerID, id}]; ok && l.deletedAt == nil {
+			state.LikedByMe = true
+		}
+		_, state.SavedByMe = db.saves[pair{viewerID, id}]
+		for _, c := range db.comments {
+			if c.postID == id && c.accountID == viewerID && c.deletedAt == nil {
+				state.CommentedByMe = true
+				break
+			}
+		}
+
+		// like dari akun yang diikuti viewer, terbaru lebih dulu
+		var followed []*like
+		for _, l := range db.likes {
+			if l.postID == id && l.deletedAt == nil && db.isFollowing(l.accountID, viewerID) && db.isActive(l.accountID) {
+				followed = append(followed, l)
+			}
+		}
+		sort.Slice(followed, func(i, j int) bool { return followed[i].createdAt.After(followed[j].createdAt) })
+		if len(followed) > 0 {
+			names := []string{}
+			for _, l := range followed[:min(len(followed), likedByPreviewSize)] {
+				names = append(names, deref(db.profiles[l.accountID].fullname))
+			}
+			likeCount, _ := db.engagementCounts(id)
+			state.LikedBy = &models.LikedByPreview{Names: names, Others: max(likeCount-len(names), 0)}
+		}
+		states[id] = &state
+	}
+	return states, nil
+}
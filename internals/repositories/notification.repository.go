@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ntisrangga142/chat/internals/models"
@@ -15,17 +16,22 @@ func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
 	return &NotificationRepository{db: db}
 }
 
-// Ambil semua notifikasi unread untuk user (owner akun)
-func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, userID int) (models.NotificationList, error) {
+// Ambil notifikasi unread untuk user (owner akun), terbaru dulu mulai setelah
+// cursor (created_at, cursor_id)
+func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, userID int, cursorTime *time.Time, cursorID, limit int) (models.NotificationList, error) {
 	query := `
+		SELECT type, from_id, from_name, post_id, message, created_at, cursor_id
+		FROM (
 		-- FOLLOW notifications
 		SELECT 'follow' AS type,
 			   f.follower_id AS from_id,
 			   p.fullname AS from_name,
 			   NULL AS post_id,
 			   p.fullname || ' followed you' AS message,
-			   f.created_at
+			   f.created_at,
+			   f.follower_id::bigint * 4 AS cursor_id
 		FROM followers f
+		JOIN active_accounts ac ON ac.id = f.follower_id
 		JOIN profiles p ON p.id = f.follower_id
 		WHERE f.account_id = $1 AND (f.read = false OR f.read IS NULL)
 
@@ -37,9 +43,11 @@ func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, use
 			   p.fullname AS from_name,
 			   l.post_id AS post_id,
 			   p.fullname || ' liked your post' AS message,
-			   l.created_at
+			   l.created_at,
+			   l.id::bigint * 4 + 1
 		FROM likes l
 		JOIN posts ps ON ps.id = l.post_id
+		JOIN active_accounts ac ON ac.id = l.account_id
 		JOIN profiles p ON p.id = l.account_id
 		WHERE ps.account_id = $1 AND (l.read = false OR l.read IS NULL)
 
@@ -51,16 +59,36 @@ func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, use
 			   p.fullname AS from_name,
 			   c.post_id AS post_id,
 			   p.fullname || ' commented: ' || c.comment AS message,
-			   c.created_at
+			   c.created_at,
+			   c.id::bigint * 4 + 2
 		FROM comments c
 		JOIN posts ps ON ps.id = c.post_id
+		JOIN active_accounts ac ON ac.id = c.account_id
 		JOIN profiles p ON p.id = c.account_id
 		WHERE ps.account_id = $1 AND (c.read = false OR c.read IS NULL)
 
-		ORDER BY created_at DESC
+		UNION ALL
+
+		-- MENTION notifications
+		SELECT 'mention' AS type,
+			   m.from_id AS from_id,
+			   p.fullname AS from_name,
+			   m.post_id AS post_id,
+			   p.fullname || CASE WHEN m.comment_id IS NULL THEN ' mentioned you in a post' ELSE ' mentioned you in a comment' END AS message,
+			   m.created_at,
+			   m.id::bigint * 4 + 3
+		FROM mentions m
+		JOIN posts ps ON ps.id = m.post_id AND ps.deleted_at IS NULL
+		JOIN active_accounts ac ON ac.id = m.from_id
+		JOIN profiles p ON p.id = m.from_id
+		WHERE m.account_id = $1 AND (m.read = false OR m.read IS NULL)
+		) n
+		WHERE $2::timestamp IS NULL OR (n.created_at, n.cursor_id) < ($2::timestamp, $3::bigint)
+		ORDER BY n.created_at DESC, n.cursor_id DESC
+		LIMIT NULLIF($4, 0)
 	`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, userID, cursorTime, cursorID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +98,7 @@ func (r *NotificationRepository) GetUnreadNotifications(ctx context.Context, use
 	for rows.Next() {
 		var n models.Notification
 		var postID *int
-		if err := rows.Scan(&n.Type, &n.FromID, &n.FromName, &postID, &n.Message, &n.CreatedAt); err != nil {
+		if err := rows.Scan(&n.Type, &n.FromID, &n.FromName, &postID, &n.Message, &n.CreatedAt, &n.CursorID); err != nil {
 			return nil, err
 		}
 		if postID != nil {
@@ -2,10 +2,14 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/utils"
 )
 
 type PostRepository struct {
@@ -16,29 +20,35 @@ func NewPostRepository(db *pgxpool.Pool) *PostRepository {
 	return &PostRepository{db: db}
 }
 
-// Get Following Posts
-func (r *PostRepository) GetFollowingPosts(ctx context.Context, followerID int) ([]models.PostFeed, error) {
+// Get Following Posts, terbaru dulu mulai setelah cursor (created_at, id)
+func (r *PostRepository) GetFollowingPosts(ctx context.Context, followerID int, cursorTime *time.Time, cursorID, limit int) ([]models.PostFeed, error) {
 	query := `
 		SELECT 
 			p.id, 
+			p.account_id,
 			pr.fullname, 
 			p.caption, 
-			ARRAY_AGG(DISTINCT pi.img) AS images, 
+			COALESCE(ARRAY_AGG(DISTINCT pi.img) FILTER (WHERE pi.img IS NOT NULL AND pi.deleted_at IS NULL), '{}') AS images, 
 			COUNT(DISTINCT lk.id) AS like_count, 
-			COUNT(DISTINCT cm.id) AS comment_count
+			COUNT(DISTINCT cm.id) AS comment_count,
+			p.created_at
 		FROM posts p
 		LEFT JOIN post_imgs pi ON p.id = pi.post_id
-		INNER JOIN accounts ac ON p.account_id = ac.id
+		INNER JOIN active_accounts ac ON p.account_id = ac.id
 		INNER JOIN profiles pr ON ac.id = pr.id
 		INNER JOIN followers fl ON ac.id = fl.account_id
 		LEFT JOIN likes lk ON p.id = lk.post_id AND lk.deleted_at IS NULL
+			AND lk.account_id IN (SELECT id FROM active_accounts)
 		LEFT JOIN comments cm ON p.id = cm.post_id AND cm.deleted_at IS NULL
+			AND cm.account_id IN (SELECT id FROM active_accounts)
 		WHERE fl.follower_id = $1 AND fl.deleted_at IS NULL AND p.deleted_at IS NULL
+			AND ($2::timestamp IS NULL OR (p.created_at, p.id) < ($2::timestamp, $3::int))
 		GROUP BY p.id, pr.fullname, p.caption
-		ORDER BY p.created_at DESC
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT NULLIF($4, 0)
 	`
 
-	rows, err := r.db.Query(ctx, query, followerID)
+	rows, err := r.db.Query(ctx, query, followerID, cursorTime, cursorID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -49,11 +59,13 @@ func (r *PostRepository) GetFollowingPosts(ctx context.Context, followerID int)
 		var post models.PostFeed
 		err := rows.Scan(
 			&post.ID,
+			&post.AuthorID,
 			&post.Fullname,
 			&post.Caption,
 			&post.Images,
 			&post.LikeCount,
 			&post.CommentCount,
+			&post.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -68,13 +80,15 @@ func (r *PostRepository) GetFollowingPosts(ctx context.Context, followerID int)
 func (r *PostRepository) GetPostDetail(ctx context.Context, postID int) (*models.PostDetail, error) {
 	query := `
 		SELECT p.id, p.caption, p.created_at,
-		       pr.id, pr.fullname, pr.img,
+		       pr.id, COALESCE(pr.fullname, ''), COALESCE(pr.img, ''),
 		       COALESCE(ARRAY_AGG(DISTINCT pi.img) FILTER (WHERE pi.deleted_at IS NULL), '{}') AS images,
 		       COUNT(DISTINCT lk.id) FILTER (WHERE lk.deleted_at IS NULL) AS like_count
 		FROM posts p
+		INNER JOIN active_accounts ac ON ac.id = p.account_id
 		INNER JOIN profiles pr ON pr.id = p.account_id
 		LEFT JOIN post_imgs pi ON p.id = pi.post_id
 		LEFT JOIN likes lk ON p.id = lk.post_id
+			AND lk.account_id IN (SELECT id FROM active_accounts)
 		WHERE p.id = $1 AND p.deleted_at IS NULL
 		GROUP BY p.id, pr.id, pr.fullname, pr.img
 	`
@@ -91,12 +105,16 @@ func (r *PostRepository) GetPostDetail(ctx context.Context, postID int) (*models
 		&post.Likes,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
 		return nil, fmt.Errorf("failed get post detail: %w", err)
 	}
 
 	commentQuery := `
-		SELECT c.id, c.account_id, pr.fullname, pr.img, c.comment, c.created_at
+		SELECT c.id, COALESCE(pr.fullname, ''), c.comment, c.created_at
 		FROM comments c
+		INNER JOIN active_accounts ac ON ac.id = c.account_id
 		INNER JOIN profiles pr ON pr.id = c.account_id
 		WHERE c.post_id = $1 AND c.deleted_at IS NULL
 		ORDER BY c.created_at DESC
@@ -111,7 +129,7 @@ func (r *PostRepository) GetPostDetail(ctx context.Context, postID int) (*models
 	var comments []models.CommentPreview
 	for rows.Next() {
 		var cm models.CommentPreview
-		if err := rows.Scan(&cm.ID, &cm.ID, &cm.Fullname, &cm.Comment, &cm.CreatedAt); err != nil {
+		if err := rows.Scan(&cm.ID, &cm.Fullname, &cm.Comment, &cm.CreatedAt); err != nil {
 			return nil, err
 		}
 		comments = append(comments, cm)
@@ -130,8 +148,9 @@ func (r *PostRepository) CreatePost(ctx context.Context, req models.CreatePostRe
 	defer tx.Rollback(ctx)
 
 	var postID int
-	query := `INSERT INTO posts (account_id, caption) VALUES ($1, $2) RETURNING id`
-	if err := tx.QueryRow(ctx, query, accountID, req.Caption).Scan(&postID); err != nil {
+	var createdAt time.Time
+	query := `INSERT INTO posts (account_id, caption) VALUES ($1, $2) RETURNING id, created_at`
+	if err := tx.QueryRow(ctx, query, accountID, req.Caption).Scan(&postID, &createdAt); err != nil {
 		return nil, fmt.Errorf("failed to insert post: %w", err)
 	}
 
@@ -143,6 +162,10 @@ func (r *PostRepository) CreatePost(ctx context.Context, req models.CreatePostRe
 		}
 	}
 
+	if err := insertMentions(ctx, tx, accountID, postID, nil, req.Caption); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit tx: %w", err)
 	}
@@ -152,57 +175,129 @@ func (r *PostRepository) CreatePost(ctx context.Context, req models.CreatePostRe
 		AccountID: accountID,
 		Caption:   req.Caption,
 		Images:    make([]models.PostImg, 0), // bisa load lagi kalau perlu
+		CreatedAt: createdAt,
 	}, nil
 }
 
-// Like Post
-func (r *PostRepository) CreateLike(ctx context.Context, accountID int, postID int) error {
+// Like Post, mengembalikan false jika post sudah di-like sebelumnya
+func (r *PostRepository) CreateLike(ctx context.Context, accountID int, postID int) (bool, error) {
 	query := `
-		INSERT INTO likes (account_id, post_id, read, deleted_at)
-		VALUES ($1, $2, false, NULL)
-		ON CONFLICT (account_id, post_id)
-		DO UPDATE SET deleted_at = NULL, read = false
+		WITH target AS (
+			SELECT p.id FROM posts p
+			INNER JOIN active_accounts ac ON ac.id = p.account_id
+			WHERE p.id = $2 AND p.deleted_at IS NULL
+		), liked AS (
+			INSERT INTO likes (account_id, post_id, read, deleted_at)
+			SELECT $1, id, false, NULL FROM target
+			ON CONFLICT (account_id, post_id)
+			DO UPDATE SET deleted_at = NULL, read = false, created_at = NOW()
+			WHERE likes.deleted_at IS NOT NULL
+			RETURNING 1
+		)
+		SELECT EXISTS (SELECT 1 FROM target), EXISTS (SELECT 1 FROM liked)
 	`
-	_, err := r.db.Exec(ctx, query, accountID, postID)
-	if err != nil {
-		return fmt.Errorf("failed to like post: %w", err)
+	var found, liked bool
+	if err := r.db.QueryRow(ctx, query, accountID, postID).Scan(&found, &liked); err != nil {
+		return false, fmt.Errorf("failed to like post: %w", err)
 	}
-	return nil
+	if !found {
+		return false, ErrPostNotFound
+	}
+	return liked, nil
 }
 
-// Unlike Post
-func (r *PostRepository) DeleteLike(ctx context.Context, accountID, postID int) error {
+// Unlike Post, mengembalikan waktu like yang dibatalkan (nil jika belum di-like)
+func (r *PostRepository) DeleteLike(ctx context.Context, accountID, postID int) (*time.Time, error) {
 	query := `
 		UPDATE likes SET deleted_at = NOW()
 		WHERE account_id=$1 AND post_id=$2 AND deleted_at IS NULL
+		RETURNING created_at
 	`
-	_, err := r.db.Exec(ctx, query, accountID, postID)
-	if err != nil {
-		return fmt.Errorf("failed to unlike post: %w", err)
+	var likedAt time.Time
+	if err := r.db.QueryRow(ctx, query, accountID, postID).Scan(&likedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to unlike post: %w", err)
 	}
-	return nil
+	return &likedAt, nil
 }
 
 // Create Comment Post
 func (r *PostRepository) CreateComment(ctx context.Context, accountID int, req models.CreateCommentRequest) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// post dihapus atau pemiliknya nonaktif dianggap tidak ada
 	query := `
 		INSERT INTO comments (account_id, post_id, comment, read)
-		VALUES ($1, $2, $3, false)
+		SELECT $1, p.id, $3, false FROM posts p
+		INNER JOIN active_accounts ac ON ac.id = p.account_id
+		WHERE p.id = $2 AND p.deleted_at IS NULL
+		RETURNING id
 	`
-	_, err := r.db.Exec(ctx, query, accountID, req.PostID, req.Comment)
-	if err != nil {
+	var commentID int
+	if err := tx.QueryRow(ctx, query, accountID, req.PostID, req.Comment).Scan(&commentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPostNotFound
+		}
 		return fmt.Errorf("failed to insert comment: %w", err)
 	}
+
+	if err := insertMentions(ctx, tx, accountID, req.PostID, &commentID, req.Comment); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Simpan @mention yang cocok dengan handle akun aktif (selain penulis)
+func insertMentions(ctx context.Context, tx pgx.Tx, fromID, postID int, commentID *int, text string) error {
+	handles := utils.ExtractMentions(text)
+	if len(handles) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO mentions (account_id, from_id, post_id, comment_id, read)
+		SELECT p.id, $1, $2, $3, false
+		FROM profiles p
+		INNER JOIN active_accounts ac ON ac.id = p.id
+		WHERE LOWER(p.username) = ANY($4) AND p.id <> $1
+	`
+	if _, err := tx.Exec(ctx, query, fromID, postID, commentID, handles); err != nil {
+		return fmt.Errorf("failed to insert mentions: %w", err)
+	}
 	return nil
 }
 
 // Get Comment Post
 func (r *PostRepository) GetAllCommentsByPost(ctx context.Context, postID int) ([]models.Comment, error) {
+	var found bool
+	if err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM posts p
+			INNER JOIN active_accounts ac ON ac.id = p.account_id
+			WHERE p.id = $1 AND p.deleted_at IS NULL
+		)
+	`, postID).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to check post: %w", err)
+	}
+	if !found {
+		return nil, ErrPostNotFound
+	}
+
 	query := `
-		SELECT id, account_id, post_id, comment
-		FROM comments
-		WHERE post_id=$1 AND deleted_at IS NULL
-		ORDER BY created_at ASC
+		SELECT c.id, c.account_id, c.post_id, c.comment
+		FROM comments c
+		INNER JOIN active_accounts ac ON ac.id = c.account_id
+		INNER JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+		INNER JOIN active_accounts pa ON pa.id = p.account_id
+		WHERE c.post_id=$1 AND c.deleted_at IS NULL
+		ORDER BY c.created_at ASC
 	`
 	rows, err := r.db.Query(ctx, query, postID)
 	if err != nil {
@@ -220,3 +315,35 @@ func (r *PostRepository) GetAllCommentsByPost(ctx context.Context, postID int) (
 
 	return comments, nil
 }
+
+// Jumlah like & komentar viewer ke post tiap author dalam 90 hari terakhir
+func (r *PostRepository) GetAuthorAffinity(ctx context.Context, viewerID int) (map[int]int, error) {
+	query := `
+		SELECT p.account_id, COUNT(*)
+		FROM posts p
+		JOIN (
+			SELECT post_id FROM likes
+			WHERE account_id = $1 AND deleted_at IS NULL AND created_at > NOW() - INTERVAL '90 days'
+			UNION ALL
+			SELECT post_id FROM comments
+			WHERE account_id = $1 AND deleted_at IS NULL AND created_at > NOW() - INTERVAL '90 days'
+		) i ON i.post_id = p.id
+		WHERE p.account_id <> $1
+		GROUP BY p.account_id
+	`
+	rows, err := r.db.Query(ctx, query, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get author affinity: %w", err)
+	}
+	defer rows.Close()
+
+	affinity := make(map[int]int)
+	for rows.Next() {
+		var authorID, count int
+		if err := rows.Scan(&authorID, &count); err != nil {
+			return nil, err
+		}
+		affinity[authorID] = count
+	}
+	return affinity, rows.Err()
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Save Post, mengembalikan false jika post tidak ditemukan
+func (r *PostRepository) SavePost(ctx context.Context, accountID, postID int) (bool, error) {
+	query := `
+		WITH target AS (
+			SELECT p.id FROM posts p
+			INNER JOIN active_accounts ac ON ac.id = p.account_id
+			WHERE p.id = $2 AND p.deleted_at IS NULL
+		), saved AS (
+			INSERT INTO saves (account_id, post_id)
+			SELECT $1, id FROM target
+			ON CONFLICT (account_id, post_id) DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM target)
+	`
+	var found bool
+	if err := r.db.QueryRow(ctx, query, accountID, postID).Scan(&found); err != nil {
+		return false, fmt.Errorf("failed to save post: %w", err)
+	}
+	return found, nil
+}
+
+// Unsave Post
+func (r *PostRepository) UnsavePost(ctx context.Context, accountID, postID int) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM saves WHERE account_id = $1 AND post_id = $2`, accountID, postID); err != nil {
+		return fmt.Errorf("failed to unsave post: %w", err)
+	}
+	return nil
+}
+
+// Get Saved Posts, terbaru disimpan lebih dulu dengan keyset pagination (saved_at, id)
+func (r *PostRepository) GetSavedPosts(ctx context.Context, accountID int, cursorTime *time.Time, cursorID, limit int) ([]models.SavedPost, error) {
+	query := `
+		SELECT 
+			p.id, 
+			p.account_id,
+			pr.fullname, 
+			p.caption, 
+			COALESCE(ARRAY_AGG(DISTINCT pi.img) FILTER (WHERE pi.img IS NOT NULL AND pi.deleted_at IS NULL), '{}') AS images, 
+			COUNT(DISTINCT lk.id) AS like_count, 
+			COUNT(DISTINCT cm.id) AS comment_count,
+			p.created_at,
+			s.created_at AS saved_at
+		FROM saves s
+		INNER JOIN posts p ON p.id = s.post_id AND p.deleted_at IS NULL
+		INNER JOIN active_accounts ac ON p.account_id = ac.id
+		INNER JOIN profiles pr ON ac.id = pr.id
+		LEFT JOIN post_imgs pi ON p.id = pi.post_id
+		LEFT JOIN likes lk ON p.id = lk.post_id AND lk.deleted_at IS NULL
+			AND lk.account_id IN (SELECT id FROM active_accounts)
+		LEFT JOIN comments cm ON p.id = cm.post_id AND cm.deleted_at IS NULL
+			AND cm.account_id IN (SELECT id FROM active_accounts)
+		WHERE s.account_id = $1
+			AND NOT EXISTS (SELECT 1 FROM blocks b
+			                WHERE (b.blocker_id = $1 AND b.blocked_id = p.account_id)
+			                   OR (b.blocker_id = p.account_id AND b.blocked_id = $1))
+			AND ($2::timestamp IS NULL OR (s.created_at, p.id) < ($2::timestamp, $3::int))
+		GROUP BY p.id, pr.fullname, p.caption, s.created_at
+		ORDER BY s.created_at DESC, p.id DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, accountID, cursorTime, cursorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []models.SavedPost{}
+	for rows.Next() {
+		var post models.SavedPost
+		err := rows.Scan(
+			&post.ID,
+			&post.AuthorID,
+			&post.Fullname,
+			&post.Caption,
+			&post.Images,
+			&post.LikeCount,
+			&post.CommentCount,
+			&post.CreatedAt,
+			&post.SavedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Kontrak repository yang dipakai handler dan job. Implementasi Postgres ada
+// di package ini, implementasi in-memory untuk test ada di repositories/memory.
+
+type AuthStore interface {
+	Register(ctx context.Context, email, password string) (int, error)
+	Login(ctx context.Context, email string) (*models.Account, error)
+	GetAccountByID(ctx context.Context, accountID int) (*models.Account, error)
+	IsEmailTaken(ctx context.Context, email string) (bool, error)
+	RestoreAccount(ctx context.Context, accountID int) error
+	UpdatePassword(ctx context.Context, accountID int, password string) error
+	UpdateEmail(ctx context.Context, accountID int, email string) error
+	CreateAccountToken(ctx context.Context, accountID int, purpose, tokenHash string, payload *string, expiresAt time.Time) error
+	ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error)
+	VerifyEmail(ctx context.Context, accountID int) error
+	ResetPassword(ctx context.Context, accountID int, password string) error
+	ChangePassword(ctx context.Context, accountID int, password string) error
+	GetMFA(ctx context.Context, accountID int) (*models.MFA, error)
+	SaveMFASecret(ctx context.Context, accountID int, secret string) error
+	EnableMFA(ctx context.Context, accountID int, step int64, codeHashes []string) error
+	UseMFAStep(ctx context.Context, accountID int, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, accountID int, codeHash string) (bool, error)
+	DisableMFA(ctx context.Context, accountID int) error
+	GetAccountByIdentity(ctx context.Context, provider, subject string) (int, error)
+	LinkIdentity(ctx context.Context, accountID int, provider, subject, email string) error
+	RegisterWithIdentity(ctx context.Context, email, provider, subject string) (int, error)
+}
+
+type UserStore interface {
+	GetProfile(ctx context.Context, uid int) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, uid int, updates map[string]any) error
+	GetAccountPassword(ctx context.Context, uid int) (string, error)
+
+	// username
+	GetUsername(ctx context.Context, uid int) (*string, *time.Time, error)
+	IsUsernameTaken(ctx context.Context, uid int, username string, holdPeriod time.Duration) (bool, error)
+	ChangeUsername(ctx context.Context, uid int, username string) error
+	GetProfileByUsername(ctx context.Context, viewerID int, username string) (*models.PublicProfile, error)
+	GetUsernameRedirect(ctx context.Context, username string) (string, error)
+	GetIDByUsername(ctx context.Context, username string) (int, error)
+
+	// follow & block
+	Follow(ctx context.Context, accountID, followerID int) error
+	Unfollow(ctx context.Context, accountID, followerID int) error
+	GetFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error)
+	GetFollowing(ctx context.Context, followerID, viewerID int, q models.FollowQuery) ([]models.Follow, error)
+	GetMutualFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error)
+	CountMutualFollowers(ctx context.Context, accountID, viewerID int) (int, error)
+	GetFollowListAccess(ctx context.Context, accountID, viewerID int) (visibility string, viewerFollows bool, found bool, err error)
+	Block(ctx context.Context, blockerID, blockedID int) error
+	Unblock(ctx context.Context, blockerID, blockedID int) error
+	IsBlocked(ctx context.Context, a, b int) (bool, error)
+
+	// who-to-follow
+	ComputeSuggestions(ctx context.Context, uid, limit int) ([]models.SuggestionScore, error)
+	GetSuggestedProfiles(ctx context.Context, uid int, ids []int) ([]models.Suggestion, error)
+	DismissSuggestion(ctx context.Context, uid, dismissedID int) error
+	GetActiveAccountIDs(ctx context.Context, afterID, limit int) ([]int, error)
+
+	// deaktivasi, penghapusan & export
+	Deactivate(ctx context.Context, uid int) error
+	ScheduleDeletion(ctx context.Context, uid int, deleteAfter time.Time) error
+	GetAccountsDueForPurge(ctx context.Context, limit int) ([]int, error)
+	PurgeAccount(ctx context.Context, uid int) (*models.PurgedAccount, error)
+	GetExportData(ctx context.Context, uid int) (*models.UserExport, error)
+}
+
+type PostStore interface {
+	// limit 0 mengembalikan semua post (v1 dan mode ranked)
+	GetFollowingPosts(ctx context.Context, followerID int, cursorTime *time.Time, cursorID, limit int) ([]models.PostFeed, error)
+	GetPostDetail(ctx context.Context, postID int) (*models.PostDetail, error)
+	CreatePost(ctx context.Context, req models.CreatePostRequest, accountID int) (*models.Post, error)
+	CreateLike(ctx context.Context, accountID int, postID int) (bool, error)
+	DeleteLike(ctx context.Context, accountID, postID int) (*time.Time, error)
+	CreateComment(ctx context.Context, accountID int, req models.CreateCommentRequest) error
+	GetAllCommentsByPost(ctx context.Context, postID int) ([]models.Comment, error)
+	GetAuthorAffinity(ctx context.Context, viewerID int) (map[int]int, error)
+
+	// save & status viewer
+	SavePost(ctx context.Context, accountID, postID int) (bool, error)
+	UnsavePost(ctx context.Context, accountID, postID int) error
+	GetSavedPosts(ctx context.Context, accountID int, cursorTime *time.Time, cursorID, limit int) ([]models.SavedPost, error)
+	GetViewerStates(ctx context.Context, viewerID int, postIDs []int) (map[int]*models.ViewerState, error)
+
+	// explore
+	GetExplorePosts(ctx context.Context, viewerID int, ids []int) ([]models.PostFeed, error)
+	GetExploreSeed(ctx context.Context, since, epoch time.Time, tau, likeWeight, commentWeight float64) ([]models.ExploreSeed, error)
+}
+
+type NotificationStore interface {
+	// limit 0 mengembalikan semua notifikasi (v1)
+	GetUnreadNotifications(ctx context.Context, userID int, cursorTime *time.Time, cursorID, limit int) (models.NotificationList, error)
+}
+
+type HealthStore interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+var (
+	_ AuthStore         = (*Auth)(nil)
+	_ UserStore         = (*UserRepository)(nil)
+	_ PostStore         = (*PostRepository)(nil)
+	_ NotificationStore = (*NotificationRepository)(nil)
+	_ HealthStore       = (*HealthRepository)(nil)
+)
+
+// Kumpulan repository yang dipasang ke router
+type Stores struct {
+	Auth         AuthStore
+	User         UserStore
+	Post         PostStore
+	Notification NotificationStore
+	Health       HealthStore
+}
+
+// Stores dengan implementasi Postgres
+func NewStores(db *pgxpool.Pool) Stores {
+	return Stores{
+		Auth:         NewAuthRepo(db),
+		User:         NewUserRepository(db),
+		Post:         NewPostRepository(db),
+		Notification: NewNotificationRepository(db),
+		Health:       NewHealthRepository(db),
+	}
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// bobot skor who-to-follow
+const (
+	SuggestionMutualWeight     = 3.0 // per akun yang diikuti viewer yang mengikuti kandidat
+	SuggestionHashtagWeight    = 2.0 // per hashtag yang sama dengan post/like viewer
+	SuggestionEngagementWeight = 1.0 // dikali ln(1 + like & komentar 30 hari terakhir)
+)
+
+// filter kandidat yang tidak boleh disarankan ke viewer ($1)
+const suggestionExclusions = `
+	ac.id <> $1
+	AND NOT EXISTS (SELECT 1 FROM followers x WHERE x.account_id = ac.id AND x.follower_id = $1 AND x.deleted_at IS NULL)
+	AND NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = $1 AND b.blocked_id = ac.id) OR (b.blocker_id = ac.id AND b.blocked_id = $1))
+	AND NOT EXISTS (SELECT 1 FROM suggestion_dismissals d WHERE d.account_id = $1 AND d.dismissed_id = ac.id)
+`
+
+// Hitung kandidat who-to-follow untuk satu akun berdasarkan friends-of-friends,
+// hashtag yang sama dan engagement terbaru
+func (r *UserRepository) ComputeSuggestions(ctx context.Context, uid, limit int) ([]models.SuggestionScore, error) {
+	query := `
+		WITH following AS (
+			SELECT account_id FROM followers WHERE follower_id = $1 AND deleted_at IS NULL
+		),
+		fof AS (
+			SELECT f.account_id AS id, COUNT(*) AS mutuals
+			FROM followers f
+			JOIN following fl ON fl.account_id = f.follower_id
+			WHERE f.deleted_at IS NULL
+			GROUP BY f.account_id
+		),
+		my_tags AS (
+			SELECT LOWER(m[1]) AS tag
+			FROM posts p, regexp_matches(p.caption, '#([[:alnum:]_]+)', 'g') m
+			WHERE p.account_id = $1 AND p.deleted_at IS NULL
+			UNION
+			SELECT LOWER(m[1])
+			FROM likes l
+			JOIN posts p ON p.id = l.post_id AND p.deleted_at IS NULL, regexp_matches(p.caption, '#([[:alnum:]_]+)', 'g') m
+			WHERE l.account_id = $1 AND l.deleted_at IS NULL
+		),
+		tags AS (
+			SELECT p.account_id AS id, COUNT(DISTINCT LOWER(m[1])) AS shared
+			FROM posts p, regexp_matches(p.caption, '#([[:alnum:]_]+)', 'g') m
+			WHERE p.deleted_at IS NULL
+			  AND p.created_at > NOW() - INTERVAL '90 days'
+			  AND LOWER(m[1]) IN (SELECT tag FROM my_tags)
+			GROUP BY p.account_id
+		),
+		engagement AS (
+			SELECT p.account_id AS id, COUNT(*) AS interactions
+			FROM posts p
+			JOIN (
+				SELECT post_id, account_id FROM likes
+				WHERE deleted_at IS NULL AND created_at > NOW() - INTERVAL '30 days'
+				UNION ALL
+				SELECT post_id, account_id FROM comments
+				WHERE deleted_at IS NULL AND created_at > NOW() - INTERVAL '30 days'
+			) e ON e.post_id = p.id
+			WHERE p.deleted_at IS NULL
+			  AND e.account_id IN (SELECT id FROM active_accounts)
+			GROUP BY p.account_id
+		)
+		SELECT ac.id,
+		       COALESCE(fof.mutuals, 0) * $3::float8
+		       + COALESCE(tags.shared, 0) * $4::float8
+		       + LN(1 + COALESCE(engagement.interactions, 0)) * $5::float8 AS score
+		FROM active_accounts ac
+		LEFT JOIN fof ON fof.id = ac.id
+		LEFT JOIN tags ON tags.id = ac.id
+		LEFT JOIN engagement ON engagement.id = ac.id
+		WHERE ` + suggestionExclusions + `
+		  AND (fof.id IS NOT NULL OR tags.id IS NOT NULL OR engagement.id IS NOT NULL)
+		ORDER BY score DESC, ac.id
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, uid, limit, SuggestionMutualWeight, SuggestionHashtagWeight, SuggestionEngagementWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	scores := []models.SuggestionScore{}
+	for rows.Next() {
+		var s models.SuggestionScore
+		if err := rows.Scan(&s.ID, &s.Score); err != nil {
+			return nil, err
+		}
+		scores = append(scores, s)
+	}
+	return scores, rows.Err()
+}
+
+// Ambil profile kandidat yang masih layak disarankan. Hasil precompute bisa
+// basi, jadi follow, block, dismiss dan status akun dicek ulang di sini.
+func (r *UserRepository) GetSuggestedProfiles(ctx context.Context, uid int, ids []int) ([]models.Suggestion, error) {
+	query := `
+		SELECT p.id, COALESCE(p.username, ''), COALESCE(p.fullname, ''), COALESCE(p.img, ''),
+		       (SELECT COUNT(*) FROM followers f
+		        JOIN followers v ON v.account_id = f.follower_id AND v.follower_id = $1 AND v.deleted_at IS NULL
+		        WHERE f.account_id = p.id AND f.deleted_at IS NULL) AS mutual_count,
+		       EXISTS (SELECT 1 FROM followers y
+		               WHERE y.account_id = $1 AND y.follower_id = p.id AND y.deleted_at IS NULL) AS follows_you
+		FROM active_accounts ac
+		JOIN profiles p ON p.id = ac.id
+		WHERE ac.id = ANY($2) AND ` + suggestionExclusions
+	rows, err := r.db.Query(ctx, query, uid, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var s models.Suggestion
+		if err := rows.Scan(&s.ID, &s.Username, &s.Fullname, &s.Img, &s.MutualCount, &s.FollowsYou); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+// Simpan saran yang ditutup user
+func (r *UserRepository) DismissSuggestion(ctx context.Context, uid, dismissedID int) error {
+	query := `
+		WITH target AS (
+			SELECT id FROM active_accounts WHERE id = $2
+		), dismissed AS (
+			INSERT INTO suggestion_dismissals (account_id, dismissed_id)
+			SELECT $1, id FROM target
+			ON CONFLICT (account_id, dismissed_id) DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM target)
+	`
+	var found bool
+	if err := r.db.QueryRow(ctx, query, uid, dismissedID).Scan(&found); err != nil {
+		return fmt.Errorf("failed to dismiss suggestion: %w", err)
+	}
+	if !found {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Ambil id akun aktif secara bertahap (keyset), dipakai job precompute
+func (r *UserRepository) GetActiveAccountIDs(ctx context.Context, afterID, limit int) ([]int, error) {
+	rows, err := r.db.Query(ctx, `SELECT id FROM active_accounts WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -2,9 +2,13 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ntisrangga142/chat/internals/models"
 )
@@ -20,7 +24,15 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 // Get Profile
 func (ur *UserRepository) GetProfile(ctx context.Context, uid int) (*models.Profile, error) {
 	sql := `
-		SELECT p.fullname, p.phone, p.img
+		SELECT p.username, p.fullname, p.phone, p.img, p.cover_img,
+		       p.bio, p.website, p.location, p.pronouns,
+		       TO_CHAR(p.birthdate, 'YYYY-MM-DD'), p.birthdate_visibility, p.follow_list_visibility,
+		       (SELECT COUNT(*) FROM followers f
+		        INNER JOIN active_accounts ac ON ac.id = f.follower_id
+		        WHERE f.account_id = p.id AND f.deleted_at IS NULL) AS follower_count,
+		       (SELECT COUNT(*) FROM followers f
+		        INNER JOIN active_accounts ac ON ac.id = f.account_id
+		        WHERE f.follower_id = p.id AND f.deleted_at IS NULL) AS following_count
 		FROM profiles p
 		WHERE p.id = $1
 	`
@@ -29,18 +41,32 @@ func (ur *UserRepository) GetProfile(ctx context.Context, uid int) (*models.Prof
 
 	var profile models.Profile
 	err := row.Scan(
+		&profile.Username,
 		&profile.FullName,
 		&profile.PhoneNumber,
 		&profile.Img,
+		&profile.CoverImg,
+		&profile.Bio,
+		&profile.Website,
+		&profile.Location,
+		&profile.Pronouns,
+		&profile.Birthdate,
+		&profile.BirthdateVisibility,
+		&profile.FollowListVisibility,
+		&profile.FollowerCount,
+		&profile.FollowingCount,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 
 	return &profile, nil
 }
 
-// Update Profile
+// Update Profile, kolom pada updates sudah divalidasi utils.ValidateProfilePatch
 func (ur *UserRepository) UpdateProfile(ctx context.Context, uid int, updates map[string]any) error {
 	if len(updates) == 0 {
 		return nil
@@ -73,16 +99,20 @@ func (ur *UserRepository) UpdateProfile(ctx context.Context, uid int, updates ma
 
 // Follow user
 func (r *UserRepository) Follow(ctx context.Context, accountID, followerID int) error {
+	// akun yang diikuti harus aktif, tidak ada baris berarti user tidak ditemukan
 	query := `
 		INSERT INTO followers (account_id, follower_id, read)
-		VALUES ($1, $2, false)
+		SELECT id, $2, false FROM active_accounts WHERE id = $1
 		ON CONFLICT (account_id, follower_id) 
 		DO UPDATE SET deleted_at = NULL
 	`
-	_, err := r.db.Exec(ctx, query, accountID, followerID)
+	tag, err := r.db.Exec(ctx, query, accountID, followerID)
 	if err != nil {
 		return fmt.Errorf("failed to follow user: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
 	return nil
 }
 
@@ -100,52 +130,318 @@ func (r *UserRepository) Unfollow(ctx context.Context, accountID, followerID int
 	return nil
 }
 
-// Get Followers
-func (r *UserRepository) GetFollowers(ctx context.Context, accountID int) ([]models.Follow, error) {
-	query := `
-		SELECT p.id, p.fullname, p.img
+// Get Followers, diurutkan dari follow terbaru
+func (r *UserRepository) GetFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
+		FROM followers f
+		JOIN active_accounts ac ON ac.id = f.follower_id
+		JOIN profiles p ON p.id = f.follower_id
+		WHERE f.account_id = $1 AND f.deleted_at IS NULL`, accountID, viewerID, q)
+}
+
+// Get Following, diurutkan dari follow terbaru
+func (r *UserRepository) GetFollowing(ctx context.Context, followerID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
+		FROM followers f
+		JOIN active_accounts ac ON ac.id = f.account_id
+		JOIN profiles p ON p.id = f.account_id
+		WHERE f.follower_id = $1 AND f.deleted_at IS NULL`, followerID, viewerID, q)
+}
+
+// Get Mutual Followers: akun yang diikuti viewer dan juga mengikuti accountID
+func (r *UserRepository) GetMutualFollowers(ctx context.Context, accountID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
 		FROM followers f
+		JOIN followers v ON v.account_id = f.follower_id AND v.follower_id = $2 AND v.deleted_at IS NULL
+		JOIN active_accounts ac ON ac.id = f.follower_id
 		JOIN profiles p ON p.id = f.follower_id
+		WHERE f.account_id = $1 AND f.deleted_at IS NULL`, accountID, viewerID, q)
+}
+
+// Jumlah mutual followers, untuk teks "diikuti oleh A, B dan n lainnya"
+func (r *UserRepository) CountMutualFollowers(ctx context.Context, accountID, viewerID int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM followers f
+		JOIN followers v ON v.account_id = f.follower_id AND v.follower_id = $2 AND v.deleted_at IS NULL
+		JOIN active_accounts ac ON ac.id = f.follower_id
 		WHERE f.account_id = $1 AND f.deleted_at IS NULL
 	`
-	rows, err := r.db.Query(ctx, query, accountID)
+	var total int
+	if err := r.db.QueryRow(ctx, query, accountID, viewerID).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// listFollows menjalankan list follow dengan pencarian nama, flag relasi terhadap
+// viewer dan keyset pagination (followed_at, id). from adalah klausa FROM/WHERE tetap
+// yang memakai $1 (pemilik list) dan $2 (viewer).
+func (r *UserRepository) listFollows(ctx context.Context, from string, ownerID, viewerID int, q models.FollowQuery) ([]models.Follow, error) {
+	query := `
+		SELECT p.id, COALESCE(p.username, ''), COALESCE(p.fullname, ''), COALESCE(p.img, ''), f.created_at,
+		       EXISTS (SELECT 1 FROM followers x
+		               WHERE x.account_id = p.id AND x.follower_id = $2 AND x.deleted_at IS NULL) AS is_following,
+		       EXISTS (SELECT 1 FROM followers x
+		               WHERE x.account_id = $2 AND x.follower_id = p.id AND x.deleted_at IS NULL) AS follows_you
+	` + from + `
+		  AND ($3::text = '' OR p.fullname ILIKE $3 OR p.username ILIKE $3)
+		  AND ($4::timestamp IS NULL OR (f.created_at, p.id) < ($4::timestamp, $5::int))
+		ORDER BY f.created_at DESC, p.id DESC
+		LIMIT $6
+	`
+	rows, err := r.db.Query(ctx, query, ownerID, viewerID, q.Search, q.CursorTime, q.CursorID, q.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var profiles []models.Follow
+	profiles := []models.Follow{}
 	for rows.Next() {
 		var p models.Follow
-		if err := rows.Scan(&p.ID, &p.Fullname, &p.Img); err == nil {
-			profiles = append(profiles, p)
+		if err := rows.Scan(&p.ID, &p.Username, &p.Fullname, &p.Img, &p.FollowedAt, &p.IsFollowing, &p.FollowsYou); err != nil {
+			return nil, err
 		}
+		profiles = append(profiles, p)
 	}
 
-	return profiles, nil
+	return profiles, rows.Err()
 }
 
-// Get Following
-func (r *UserRepository) GetFollowing(ctx context.Context, followerID int) ([]models.Follow, error) {
+// Ambil pengaturan privasi list follow milik akun aktif dan apakah viewer mengikutinya.
+// found false jika akun tidak ada atau tidak aktif.
+func (r *UserRepository) GetFollowListAccess(ctx context.Context, accountID, viewerID int) (visibility string, viewerFollows bool, found bool, err error) {
 	query := `
-		SELECT p.id, p.fullname, p.img
-		FROM followers f
-		JOIN profiles p ON p.id = f.account_id
-		WHERE f.follower_id = $1 AND f.deleted_at IS NULL
+		SELECT p.follow_list_visibility,
+		       EXISTS (SELECT 1 FROM followers f
+		               WHERE f.account_id = p.id AND f.follower_id = $2 AND f.deleted_at IS NULL)
+		FROM profiles p
+		INNER JOIN active_accounts ac ON ac.id = p.id
+		WHERE p.id = $1
+	`
+	if err := r.db.QueryRow(ctx, query, accountID, viewerID).Scan(&visibility, &viewerFollows); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, false, nil
+		}
+		return "", false, false, err
+	}
+	return visibility, viewerFollows, true, nil
+}
+
+// Ambil hash password akun, string kosong untuk akun social login
+func (r *UserRepository) GetAccountPassword(ctx context.Context, uid int) (string, error) {
+	var password string
+	if err := r.db.QueryRow(ctx, `SELECT password FROM accounts WHERE id = $1`, uid).Scan(&password); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// Nonaktifkan akun sementara, data tetap tersimpan
+func (r *UserRepository) Deactivate(ctx context.Context, uid int) error {
+	query := `UPDATE accounts SET deactivated_at = NOW(), updated_at = NOW() WHERE id = $1 AND purged_at IS NULL`
+	if _, err := r.db.Exec(ctx, query, uid); err != nil {
+		return fmt.Errorf("failed to deactivate account: %w", err)
+	}
+	return nil
+}
+
+// Jadwalkan penghapusan akun setelah masa tenggang
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, uid int, deleteAfter time.Time) error {
+	query := `UPDATE accounts SET delete_after = $2, updated_at = NOW() WHERE id = $1 AND purged_at IS NULL`
+	if _, err := r.db.Exec(ctx, query, uid, deleteAfter); err != nil {
+		return fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+	return nil
+}
+
+// Ambil akun yang masa tenggangnya sudah habis
+func (r *UserRepository) GetAccountsDueForPurge(ctx context.Context, limit int) ([]int, error) {
+	query := `
+		SELECT id FROM accounts
+		WHERE delete_after IS NOT NULL AND delete_after <= NOW() AND purged_at IS NULL
+		ORDER BY delete_after
+		LIMIT $1
 	`
-	rows, err := r.db.Query(ctx, query, followerID)
+	rows, err := r.db.Query(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var profiles []models.Follow
+	var ids []int
 	for rows.Next() {
-		var p models.Follow
-		if err := rows.Scan(&p.ID, &p.Fullname, &p.Img); err == nil {
-			profiles = append(profiles, p)
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Hapus permanen data akun. Baris accounts & profiles tetap ada sebagai
+// "Deleted User" supaya komentar di post orang lain tidak merusak thread.
+// Mengembalikan nil jika akun sudah pernah dipurge.
+func (r *UserRepository) PurgeAccount(ctx context.Context, uid int) (*models.PurgedAccount, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// kunci akun supaya tidak dipurge dua kali bersamaan
+	var purgedAt *time.Time
+	if err := tx.QueryRow(ctx, `SELECT purged_at FROM accounts WHERE id = $1 FOR UPDATE`, uid).Scan(&purgedAt); err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+	if purgedAt != nil {
+		return nil, nil
+	}
+
+	var purged models.PurgedAccount
+	if err := tx.QueryRow(ctx, `
+		SELECT
+			COALESCE((SELECT ARRAY_AGG(pi.img) FROM post_imgs pi INNER JOIN posts p ON p.id = pi.post_id
+			          WHERE p.account_id = $1 AND pi.img IS NOT NULL), '{}'),
+			COALESCE((SELECT ARRAY_AGG(id) FROM posts WHERE account_id = $1), '{}'),
+			COALESCE((SELECT ARRAY_AGG(follower_id) FROM followers WHERE account_id = $1 AND deleted_at IS NULL), '{}')
+	`, uid).Scan(&purged.PostImgs, &purged.PostIDs, &purged.FollowerIDs); err != nil {
+		return nil, fmt.Errorf("failed to get posts and followers: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, `SELECT img, cover_img FROM profiles WHERE id = $1`, uid).Scan(&purged.ProfileImg, &purged.CoverImg); err != nil {
+		return nil, fmt.Errorf("failed to get profile image: %w", err)
+	}
+
+	queries := []string{
+		`DELETE FROM mentions
+		 WHERE account_id = $1 OR from_id = $1 OR post_id IN (SELECT id FROM posts WHERE account_id = $1)`,
+		`DELETE FROM saves WHERE account_id = $1 OR post_id IN (SELECT id FROM posts WHERE account_id = $1)`,
+		// like & komentar orang lain di post milik akun ikut terhapus bersama post
+		`DELETE FROM likes WHERE account_id = $1 OR post_id IN (SELECT id FROM posts WHERE account_id = $1)`,
+		`DELETE FROM comments WHERE post_id IN (SELECT id FROM posts WHERE account_id = $1)`,
+		`DELETE FROM post_imgs WHERE post_id IN (SELECT id FROM posts WHERE account_id = $1)`,
+		`DELETE FROM posts WHERE account_id = $1`,
+		// komentar di post orang lain dianonimkan
+		`UPDATE comments SET comment = '[deleted]', updated_at = NOW() WHERE account_id = $1`,
+		`DELETE FROM followers WHERE account_id = $1 OR follower_id = $1`,
+		`DELETE FROM blocks WHERE blocker_id = $1 OR blocked_id = $1`,
+		`DELETE FROM suggestion_dismissals WHERE account_id = $1 OR dismissed_id = $1`,
+		`DELETE FROM account_tokens WHERE account_id = $1`,
+		`DELETE FROM recovery_codes WHERE account_id = $1`,
+		`DELETE FROM account_mfa WHERE account_id = $1`,
+		`DELETE FROM account_identities WHERE account_id = $1`,
+		`DELETE FROM username_history WHERE account_id = $1`,
+		`UPDATE profiles
+		 SET fullname = 'Deleted User', username = NULL, phone = NULL, img = NULL, cover_img = NULL,
+		     bio = NULL, website = NULL, location = NULL, pronouns = NULL, birthdate = NULL, updated_at = NOW()
+		 WHERE id = $1`,
+		`UPDATE accounts
+		 SET email = 'deleted-' || id || '@deleted.invalid', password = '', verified_at = NULL,
+		     delete_after = NULL, purged_at = NOW(), updated_at = NOW()
+		 WHERE id = $1`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, uid); err != nil {
+			return nil, fmt.Errorf("failed to purge account: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit tx: %w", err)
+	}
+	return &purged, nil
+}
+
+// Kumpulkan semua data akun untuk export
+func (r *UserRepository) GetExportData(ctx context.Context, uid int) (*models.UserExport, error) {
+	var data models.UserExport
+
+	if err := r.db.QueryRow(ctx, `SELECT id, email, created_at FROM accounts WHERE id = $1`, uid).
+		Scan(&data.Account.ID, &data.Account.Email, &data.Account.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed get account: %w", err)
+	}
+
+	profile, err := r.GetProfile(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed get profile: %w", err)
+	}
+	data.Profile = *profile
+
+	postRows, err := r.db.Query(ctx, `
+		SELECT p.id, COALESCE(p.caption, ''), p.created_at,
+		       COALESCE(ARRAY_AGG(pi.img) FILTER (WHERE pi.img IS NOT NULL AND pi.deleted_at IS NULL), '{}')
+		FROM posts p
+		LEFT JOIN post_imgs pi ON pi.post_id = p.id
+		WHERE p.account_id = $1 AND p.deleted_at IS NULL
+		GROUP BY p.id
+		ORDER BY p.created_at
+	`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed get posts: %w", err)
+	}
+	defer postRows.Close()
+	for postRows.Next() {
+		var p models.ExportPost
+		if err := postRows.Scan(&p.ID, &p.Caption, &p.CreatedAt, &p.Images); err != nil {
+			return nil, err
+		}
+		data.Posts = append(data.Posts, p)
+	}
+	if err := postRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed read posts: %w", err)
+	}
+
+	commentRows, err := r.db.Query(ctx, `
+		SELECT id, post_id, COALESCE(comment, ''), created_at
+		FROM comments
+		WHERE account_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed get comments: %w", err)
+	}
+	defer commentRows.Close()
+	for commentRows.Next() {
+		var c models.ExportComment
+		if err := commentRows.Scan(&c.ID, &c.PostID, &c.Comment, &c.CreatedAt); err != nil {
+			return nil, err
 		}
+		data.Comments = append(data.Comments, c)
+	}
+	if err := commentRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed read comments: %w", err)
+	}
+
+	likeRows, err := r.db.Query(ctx, `
+		SELECT post_id, created_at FROM likes
+		WHERE account_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed get likes: %w", err)
+	}
+	defer likeRows.Close()
+	for likeRows.Next() {
+		var l models.ExportLike
+		if err := likeRows.Scan(&l.PostID, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		data.Likes = append(data.Likes, l)
+	}
+	if err := likeRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed read likes: %w", err)
+	}
+
+	// export berisi seluruh list, tanpa pagination
+	all := models.FollowQuery{Limit: math.MaxInt32}
+	if data.Followers, err = r.GetFollowers(ctx, uid, uid, all); err != nil {
+		return nil, fmt.Errorf("failed get followers: %w", err)
+	}
+	if data.Following, err = r.GetFollowing(ctx, uid, uid, all); err != nil {
+		return nil, fmt.Errorf("failed get following: %w", err)
 	}
 
-	return profiles, nil
+	return &data, nil
 }
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// Ambil username aktif dan waktu terakhir diganti
+func (r *UserRepository) GetUsername(ctx context.Context, uid int) (*string, *time.Time, error) {
+	var username *string
+	var changedAt *time.Time
+	err := r.db.QueryRow(ctx, `SELECT username, username_changed_at FROM profiles WHERE id = $1`, uid).Scan(&username, &changedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return username, changedAt, nil
+}
+
+// Cek apakah username dipakai akun lain, termasuk handle lama akun lain
+// yang masih ditahan selama holdPeriod supaya redirect-nya tidak dibajak
+func (r *UserRepository) IsUsernameTaken(ctx context.Context, uid int, username string, holdPeriod time.Duration) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM profiles WHERE LOWER(username) = LOWER($1) AND id <> $2
+		) OR EXISTS (
+			SELECT 1 FROM username_history
+			WHERE username = LOWER($1) AND account_id <> $2 AND changed_at > $3
+		)
+	`
+	var taken bool
+	if err := r.db.QueryRow(ctx, query, username, uid, time.Now().Add(-holdPeriod)).Scan(&taken); err != nil {
+		return false, err
+	}
+	return taken, nil
+}
+
+// Ganti username, handle lama disimpan di history untuk redirect
+func (r *UserRepository) ChangeUsername(ctx context.Context, uid int, username string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var old *string
+	if err := tx.QueryRow(ctx, `SELECT username FROM profiles WHERE id = $1 FOR UPDATE`, uid).Scan(&old); err != nil {
+		return fmt.Errorf("failed to get username: %w", err)
+	}
+
+	if old != nil {
+		query := `
+			INSERT INTO username_history (username, account_id)
+			VALUES (LOWER($1), $2)
+			ON CONFLICT (username) DO UPDATE SET account_id = EXCLUDED.account_id, changed_at = NOW()
+		`
+		if _, err := tx.Exec(ctx, query, *old, uid); err != nil {
+			return fmt.Errorf("failed to save username history: %w", err)
+		}
+	}
+
+	// handle baru tidak lagi menjadi redirect ke akun manapun
+	if _, err := tx.Exec(ctx, `DELETE FROM username_history WHERE username = LOWER($1)`, username); err != nil {
+		return fmt.Errorf("failed to release username: %w", err)
+	}
+
+	query := `UPDATE profiles SET username = $2, username_changed_at = NOW(), updated_at = NOW() WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, uid, username); err != nil {
+		if isUniqueViolation(err) {
+			return ErrUsernameTaken.WithCause(err)
+		}
+		return fmt.Errorf("failed to update username: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Ambil profile publik berdasarkan handle, nil jika tidak ada.
+// Tanggal lahir hanya ditampilkan sesuai birthdate_visibility terhadap viewer.
+func (r *UserRepository) GetProfileByUsername(ctx context.Context, viewerID int, username string) (*models.PublicProfile, error) {
+	query := `
+		SELECT p.id, p.username, p.fullname, p.img, p.cover_img,
+		       p.bio, p.website, p.location, p.pronouns,
+		       CASE
+		           WHEN p.id = $2 OR p.birthdate_visibility = 'public'
+		                OR (p.birthdate_visibility = 'followers' AND EXISTS (
+		                    SELECT 1 FROM followers vf
+		                    WHERE vf.account_id = p.id AND vf.follower_id = $2 AND vf.deleted_at IS NULL))
+		           THEN TO_CHAR(p.birthdate, 'YYYY-MM-DD')
+		       END AS birthdate,
+		       (SELECT COUNT(*) FROM followers f
+		        INNER JOIN active_accounts fa ON fa.id = f.follower_id
+		        WHERE f.account_id = p.id AND f.deleted_at IS NULL) AS follower_count,
+		       (SELECT COUNT(*) FROM followers f
+		        INNER JOIN active_accounts fa ON fa.id = f.account_id
+		        WHERE f.follower_id = p.id AND f.deleted_at IS NULL) AS following_count
+		FROM profiles p
+		INNER JOIN active_accounts ac ON ac.id = p.id
+		WHERE LOWER(p.username) = LOWER($1)
+	`
+	var profile models.PublicProfile
+	err := r.db.QueryRow(ctx, query, username, viewerID).Scan(
+		&profile.ID,
+		&profile.Username,
+		&profile.FullName,
+		&profile.Img,
+		&profile.CoverImg,
+		&profile.Bio,
+		&profile.Website,
+		&profile.Location,
+		&profile.Pronouns,
+		&profile.Birthdate,
+		&profile.FollowerCount,
+		&profile.FollowingCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Cari handle baru dari handle lama, string kosong jika tidak ada
+func (r *UserRepository) GetUsernameRedirect(ctx context.Context, username string) (string, error) {
+	query := `
+		SELECT p.username
+		FROM username_history h
+		INNER JOIN active_accounts ac ON ac.id = h.account_id
+		INNER JOIN profiles p ON p.id = h.account_id
+		WHERE h.username = LOWER($1) AND p.username IS NOT NULL
+	`
+	var current string
+	if err := r.db.QueryRow(ctx, query, username).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return current, nil
+}
+
+// Ambil id akun aktif berdasarkan handle, 0 jika tidak ada
+func (r *UserRepository) GetIDByUsername(ctx context.Context, username string) (int, error) {
+	query := `
+		SELECT p.id FROM profiles p
+		INNER JOIN active_accounts ac ON ac.id = p.id
+		WHERE LOWER(p.username) = LOWER($1)
+	`
+	var id int
+	if err := r.db.QueryRow(ctx, query, username).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// jumlah nama pada preview "disukai oleh"
+const likedByPreviewSize = 2
+
+// Ambil status like/save/komentar/kepemilikan viewer untuk beberapa post
+// sekaligus, termasuk preview like dari akun yang diikuti viewer
+func (r *PostRepository) GetViewerStates(ctx context.Context, viewerID int, postIDs []int) (map[int]*models.ViewerState, error) {
+	query := `
+		SELECT p.id,
+		       p.account_id = $1 AS is_owner,
+		       EXISTS (SELECT 1 FROM likes l
+		               WHERE l.post_id = p.id AND l.account_id = $1 AND l.deleted_at IS NULL) AS liked_by_me,
+		       EXISTS (SELECT 1 FROM saves s
+		               WHERE s.post_id = p.id AND s.account_id = $1) AS saved_by_me,
+		       EXISTS (SELECT 1 FROM comments c
+		               WHERE c.post_id = p.id AND c.account_id = $1 AND c.deleted_at IS NULL) AS commented_by_me,
+		       COALESCE((
+		           SELECT ARRAY_AGG(x.fullname ORDER BY x.created_at DESC)
+		           FROM (
+		               SELECT COALESCE(pr.fullname, '') AS fullname, l.created_at
+		               FROM likes l
+		               JOIN followers f ON f.account_id = l.account_id AND f.follower_id = $1 AND f.deleted_at IS NULL
+		               JOIN active_accounts ac ON ac.id = l.account_id
+		               JOIN profiles pr ON pr.id = l.account_id
+		               WHERE l.post_id = p.id AND l.deleted_at IS NULL
+		               ORDER BY l.created_at DESC
+		               LIMIT $3
+		           ) x
+		       ), '{}') AS liked_by,
+		       (SELECT COUNT(*) FROM likes l
+		        WHERE l.post_id = p.id AND l.deleted_at IS NULL
+		          AND l.account_id IN (SELECT id FROM active_accounts)) AS like_count
+		FROM posts p
+		WHERE p.id = ANY($2)
+	`
+	rows, err := r.db.Query(ctx, query, viewerID, postIDs, likedByPreviewSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed get viewer state: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[int]*models.ViewerState, len(postIDs))
+	for rows.Next() {
+		var (
+			postID    int
+			state     models.ViewerState
+			likedBy   []string
+			likeCount int
+		)
+		if err := rows.Scan(&postID, &state.IsOwner, &state.LikedByMe, &state.SavedByMe, &state.CommentedByMe, &likedBy, &likeCount); err != nil {
+			return nil, err
+		}
+		if len(likedBy) > 0 {
+			state.LikedBy = &models.LikedByPreview{Names: likedBy, Others: max(likeCount-len(likedBy), 0)}
+		}
+		states[postID] = &state
+	}
+	return states, rows.Err()
+}
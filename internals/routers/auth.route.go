@@ -1,25 +1,51 @@
 package routers
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ntisrangga142/chat/internals/configs"
 	"github.com/ntisrangga142/chat/internals/handlers"
 	"github.com/ntisrangga142/chat/internals/middlewares"
 	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/pkg"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitAuth(ctx *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
-	repo := repositories.NewAuthRepo(db)
-	handler := handlers.NewAuthHandler(repo, rdb)
+func InitAuth(ctx gin.IRouter, repo repositories.AuthStore, rdb *redis.Client, mailer pkg.Mailer, providers map[string]*pkg.OIDCProvider, app configs.AppConfig) {
+	handler := handlers.NewAuthHandler(repo, rdb, mailer, providers, app)
 
 	auth := ctx.Group("/auth")
+	authLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("auth", 10, time.Minute, middlewares.KeyByIP))
 
 	// Login
-	auth.POST("", handler.Login)
+	auth.POST("", authLimit, handler.Login)
 
 	// Register
-	auth.POST("/register", handler.Register)
+	auth.POST("/register", authLimit, handler.Register)
+
+	// Email verification
+	auth.POST("/verify/request", authLimit, handler.RequestVerification)
+	auth.POST("/verify", authLimit, handler.VerifyEmail)
+
+	// Password reset
+	auth.POST("/password/forgot", authLimit, handler.ForgotPassword)
+	auth.POST("/password/reset", authLimit, handler.ResetPassword)
+
+	// Change password & email
+	auth.PATCH("/password", middlewares.Authentication, authLimit, handler.ChangePassword)
+	auth.PATCH("/email", middlewares.Authentication, authLimit, handler.ChangeEmail)
+	auth.POST("/email/confirm", authLimit, handler.ConfirmEmailChange)
+
+	// Two-factor authentication
+	auth.POST("/mfa/verify", authLimit, handler.VerifyMFA)
+	auth.POST("/mfa/enroll", middlewares.Authentication, authLimit, handler.EnrollMFA)
+	auth.POST("/mfa/enable", middlewares.Authentication, authLimit, handler.EnableMFA)
+	auth.DELETE("/mfa", middlewares.Authentication, authLimit, handler.DisableMFA)
+
+	// Social login (OIDC)
+	auth.GET("/oidc/:provider/login", authLimit, handler.OIDCLogin)
+	auth.GET("/oidc/:provider/callback", authLimit, handler.OIDCCallback)
 
 	// Logout
 	auth.DELETE("/", middlewares.Authentication, handler.Logout)
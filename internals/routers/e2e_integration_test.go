@@ -0,0 +1,152 @@
+//go:build integration
+
+package routers_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/ntisrangga142/chat/internals/testutils"
+)
+
+// daftar, verifikasi email, login, lalu isi fullname & username
+func (e *integrationEnv) signUp(t *testing.T, email, username, fullname string) string {
+	t.Helper()
+	creds := models.AuthRequest{Email: email, Password: "Password123!"}
+
+	testutils.ExpectStatus(t, e.DoJSON(http.MethodPost, "/auth/register", "", creds), http.StatusCreated)
+	testutils.ExpectStatus(t, e.DoJSON(http.MethodPost, "/auth/verify", "", models.TokenRequest{Token: e.mailer.NextToken(t)}), http.StatusOK)
+
+	rec := e.DoJSON(http.MethodPost, "/auth", "", creds)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	token := testutils.Decode[models.ResponseLogin](t, rec).Token
+	if token == "" {
+		t.Fatalf("login for %s returned no token", email)
+	}
+
+	testutils.ExpectStatus(t, e.DoJSON(http.MethodPatch, "/user", token, map[string]string{"fullname": fullname}), http.StatusOK)
+	testutils.ExpectStatus(t, e.DoJSON(http.MethodPatch, "/user/username", token, models.ChangeUsernameRequest{Username: username}), http.StatusOK)
+	return token
+}
+
+func TestEndToEnd(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	rina := env.signUp(t, "rina@example.com", "rina", "Rina Wulandari")
+	tono := env.signUp(t, "tono@example.com", "tono", "Tono Saputra")
+
+	// tono mengikuti rina lewat handle
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/user/rina", tono, nil), http.StatusCreated)
+
+	rec := env.DoForm(http.MethodPost, "/post", rina, map[string]string{"caption": "Senja di Jogja"})
+	testutils.ExpectStatus(t, rec, http.StatusCreated)
+	postID := testutils.Decode[models.Response[models.Post]](t, rec).Data.ID
+	postPath := "/post/" + strconv.Itoa(postID)
+
+	rec = env.DoJSON(http.MethodGet, "/post", tono, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	feed := testutils.Decode[models.Response[[]models.PostFeed]](t, rec).Data
+	if len(feed) != 1 || feed[0].ID != postID || feed[0].Fullname != "Rina Wulandari" {
+		t.Fatalf("tono's feed = %+v, want rina's post", feed)
+	}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, postPath+"/like", tono, nil), http.StatusNoContent)
+	comment := models.CreateCommentRequest{PostID: postID, Comment: "Cakep banget @rina"}
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/post/comment", tono, comment), http.StatusCreated)
+
+	rec = env.DoJSON(http.MethodGet, postPath, tono, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	detail := testutils.Decode[models.Response[models.PostDetail]](t, rec).Data
+	if detail.Likes != 1 || len(detail.Comments) != 1 || detail.Comments[0].Fullname != "Tono Saputra" {
+		t.Fatalf("post detail = %+v, want one like and tono's comment", detail)
+	}
+	if detail.ViewerState == nil || !detail.LikedByMe || !detail.CommentedByMe {
+		t.Fatalf("viewer state = %+v, want liked and commented by tono", detail.ViewerState)
+	}
+
+	rec = env.DoJSON(http.MethodGet, "/notif", rina, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	got := make(map[string]int)
+	for _, n := range testutils.Decode[models.Response[models.NotificationList]](t, rec).Data {
+		if n.FromName != "Tono Saputra" {
+			t.Fatalf("notification %+v not from tono", n)
+		}
+		got[n.Type]++
+	}
+	for _, typ := range []string{"follow", "like", "comment", "mention"} {
+		if got[typ] != 1 {
+			t.Fatalf("notification types = %v, want one %s", got, typ)
+		}
+	}
+
+	// logout memasukkan token ke blacklist, hanya token itu yang ditolak
+	logout := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "logout", method: http.MethodDelete, path: "/auth/", token: tono, want: http.StatusOK},
+		{name: "revoked token rejected", method: http.MethodGet, path: "/user", token: tono, want: http.StatusUnauthorized},
+		{name: "logout twice rejected", method: http.MethodDelete, path: "/auth/", token: tono, want: http.StatusUnauthorized},
+		{name: "other session unaffected", method: http.MethodGet, path: "/user", token: rina, want: http.StatusOK},
+	}
+	for _, tt := range logout {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(tt.method, tt.path, tt.token, nil), tt.want)
+		})
+	}
+
+	key := "Blacklist:" + tono
+	if !env.redis.Exists(key) {
+		t.Fatalf("%s not stored in redis", key)
+	}
+	if ttl := env.redis.TTL(key); ttl <= 0 {
+		t.Fatalf("blacklist ttl = %v, want the remaining token lifetime", ttl)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/healthz", "", nil), http.StatusOK)
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/readyz", "", nil), http.StatusOK)
+
+	if _, err := env.db.Exec(context.Background(), `UPDATE schema_migrations SET dirty = true`); err != nil {
+		t.Fatal(err)
+	}
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, "/readyz", "", nil), http.StatusServiceUnavailable)
+}
+
+func TestSeededAccountsAreReachable(t *testing.T) {
+	env := newIntegrationEnv(t)
+	tono := env.signUp(t, "tono@example.com", "tono", "Tono Saputra")
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "seeded handle", path: "/user/by-handle/alice", want: http.StatusOK},
+		{name: "seeded follower list", path: "/user/alice/followers", want: http.StatusOK},
+		{name: "unknown handle", path: "/user/by-handle/nobody", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutils.ExpectStatus(t, env.DoJSON(http.MethodGet, tt.path, tono, nil), tt.want)
+		})
+	}
+
+	testutils.ExpectStatus(t, env.DoJSON(http.MethodPost, "/user/alice", tono, nil), http.StatusCreated)
+	rec := env.DoJSON(http.MethodGet, "/post", tono, nil)
+	testutils.ExpectStatus(t, rec, http.StatusOK)
+	for _, p := range testutils.Decode[models.Response[[]models.PostFeed]](t, rec).Data {
+		if p.Fullname != "Alice Johnson" {
+			t.Fatalf("feed post %+v, want only alice's seeded posts", p)
+		}
+	}
+}
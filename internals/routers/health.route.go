@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	chatdb "github.com/ntisrangga142/chat/db"
+	"github.com/ntisrangga142/chat/internals/handlers"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+// InitHealth dipasang sebelum rate limiter supaya probe orchestrator tidak ikut dibatasi
+func InitHealth(ctx *gin.Engine, repo repositories.HealthStore, rdb *redis.Client) {
+	handler := handlers.NewHealthHandler(repo, rdb, chatdb.LatestMigrationVersion())
+
+	ctx.GET("/healthz", handler.Liveness)
+	ctx.GET("/readyz", handler.Readiness)
+}
@@ -0,0 +1,11 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// InitMetrics dipasang sebelum rate limiter supaya scrape Prometheus tidak ikut dibatasi
+func InitMetrics(ctx *gin.Engine) {
+	ctx.GET("/metrics", gin.WrapH(promhttp.Handler()))
+}
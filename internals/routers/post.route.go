@@ -1,28 +1,37 @@
 package routers
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ntisrangga142/chat/internals/handlers"
 	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/rankers"
 	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitPost(ctx *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
-	repo := repositories.NewPostRepository(db)
-	handler := handlers.NewPostHandler(repo, rdb)
+func InitPost(ctx gin.IRouter, repo repositories.PostStore, rdb *redis.Client) {
+	handler := handlers.NewPostHandler(repo, rdb, rankers.NewEngagementRanker())
 
 	post := ctx.Group("/post")
-	post.Use(middlewares.Authentication)
+	post.Use(middlewares.Authentication, middlewares.Idempotency)
+
+	postLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-create", 10, time.Minute, middlewares.KeyByUserOrIP))
+	likeLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-like", 60, time.Minute, middlewares.KeyByUserOrIP))
+	commentLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-comment", 20, time.Minute, middlewares.KeyByUserOrIP))
 
 	post.GET("", handler.GetFollowingPosts)
+	post.GET("/explore", handler.GetExplorePosts)
+	post.GET("/saved", handler.GetSavedPosts)
 	post.GET("/:id", handler.GetPostDetail)
-	post.POST("", handler.CreatePost)
+	post.POST("", postLimit, handler.CreatePost)
 
-	post.POST("/:id/like", handler.LikePost)
-	post.DELETE("/:id/like", handler.UnlikePost)
+	post.POST("/:id/like", likeLimit, handler.LikePost)
+	post.DELETE("/:id/like", likeLimit, handler.UnlikePost)
+	post.POST("/:id/save", handler.SavePost)
+	post.DELETE("/:id/save", handler.UnsavePost)
 
-	post.POST("/comment", handler.CreateComment)
+	post.POST("/comment", commentLimit, handler.CreateComment)
 	post.GET("/:id/comment", handler.GetAllCommentsByPost)
 }
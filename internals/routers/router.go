@@ -1,33 +1,92 @@
 package routers
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
-	docs "github.com/ntisrangga142/chat/docs"
+	docsv1 "github.com/ntisrangga142/chat/docs/v1"
+	docsv2 "github.com/ntisrangga142/chat/docs/v2"
+	"github.com/ntisrangga142/chat/internals/configs"
+	"github.com/ntisrangga142/chat/internals/handlers"
 	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/pkg"
 	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func InitRouter(db *pgxpool.Pool, rdb *redis.Client) *gin.Engine {
-	router := gin.Default()
+func InitRouter(cfg *configs.Config, db *pgxpool.Pool, rdb *redis.Client, mailer pkg.Mailer, providers map[string]*pkg.OIDCProvider) *gin.Engine {
+	return NewRouter(cfg, repositories.NewStores(db), rdb, mailer, providers)
+}
+
+// NewRouter memasang semua route dengan repository yang diberikan,
+// dipakai test untuk menjalankan router dengan repository in-memory
+func NewRouter(cfg *configs.Config, stores repositories.Stores, rdb *redis.Client, mailer pkg.Mailer, providers map[string]*pkg.OIDCProvider) *gin.Engine {
+	router := gin.New()
+	// tanpa ini gin mempercayai X-Forwarded-For dari semua client, sehingga
+	// rate limit per IP bisa diakali dengan mengganti header tersebut
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		// format sudah divalidasi configs, fallback ke alamat koneksi
+		slog.Error("Invalid trusted proxies, ignoring X-Forwarded-For", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+	// repository menerima *gin.Context, fallback membuat span request ikut terbawa ke query
+	router.ContextWithFallback = true
+	router.Use(
+		otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(traced)),
+		middlewares.RequestID,
+		middlewares.Metrics,
+		middlewares.RequestLogger,
+		gin.CustomRecovery(middlewares.Recovery),
+	)
+
+	// swagger per versi API, /chat/v1/index.html dan /chat/v2/index.html
+	docsv1.SwaggerInfov1.Host = cfg.App.SwaggerHost
+	docsv2.SwaggerInfov2.Host = cfg.App.SwaggerHost
+	router.GET("/chat/v1/*any", ginSwagger.WrapHandler(swaggerFiles.NewHandler(), ginSwagger.InstanceName(docsv1.SwaggerInfov1.InstanceName())))
+	router.GET("/chat/v2/*any", ginSwagger.WrapHandler(swaggerFiles.NewHandler(), ginSwagger.InstanceName(docsv2.SwaggerInfov2.InstanceName())))
 
-	docs.SwaggerInfo.Title = "Social Media API"
-	docs.SwaggerInfo.Description = "This is a sample API for social media"
-	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Host = "localhost:8080"
-	router.GET("/chat/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	InitHealth(router, stores.Health, rdb)
+	InitMetrics(router)
 
 	middlewares.InitRedis(rdb)
+	middlewares.InitRateLimits(cfg.RateLimits)
+	middlewares.InitIdempotency(cfg.App.IdempotencyTTL)
+	router.Use(middlewares.RateLimit(middlewares.NewRateLimitPolicy("global", 300, time.Minute, middlewares.KeyByIP)))
 
 	router.Static("/avatar", "./public/profile")
+	router.Static("/cover", "./public/cover")
 	router.Static("/img", "./public/post")
 
-	InitAuth(router, db, rdb)
-	InitUser(router, db, rdb)
-	InitPost(router, db, rdb)
-	InitNotif(router, db, rdb)
+	wellKnown := handlers.NewWellKnownHandler()
+	router.GET("/.well-known/jwks.json", wellKnown.JWKS)
+
+	// /v1 mempertahankan kontrak lama dan tetap dilayani tanpa prefix untuk
+	// client yang belum pindah, keduanya ditandai deprecated
+	deprecated := middlewares.Deprecation(v1DeprecatedAt, "/chat/v2/index.html")
+	for _, v1 := range []gin.IRouter{router.Group("", deprecated), router.Group("/v1", deprecated)} {
+		InitAuth(v1, stores.Auth, rdb, mailer, providers, cfg.App)
+		InitUser(v1, stores.User, rdb)
+		InitPost(v1, stores.Post, rdb)
+		InitNotif(v1, stores.Notification)
+	}
+	InitV2(router, stores, rdb, mailer, providers, cfg.App)
 
 	return router
 }
+
+// tanggal /v2 dirilis, dikirim di header Deprecation pada route /v1
+var v1DeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// probe dan scrape tidak perlu di-trace
+func traced(ctx *gin.Context) bool {
+	switch ctx.FullPath() {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
+}
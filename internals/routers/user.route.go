@@ -1,25 +1,39 @@
 package routers
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ntisrangga142/chat/internals/handlers"
 	"github.com/ntisrangga142/chat/internals/middlewares"
 	"github.com/ntisrangga142/chat/internals/repositories"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitUser(ctx *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
-	repo := repositories.NewUserRepository(db)
+func InitUser(ctx gin.IRouter, repo repositories.UserStore, rdb *redis.Client) {
 	handler := handlers.NewUserHandler(repo, rdb)
 
+	// Download export lewat signed link, tanpa login
+	ctx.GET("/user/export/download", handler.DownloadExport)
+
 	user := ctx.Group("/user")
-	user.Use(middlewares.Authentication)
+	user.Use(middlewares.Authentication, middlewares.Idempotency)
 
 	// Get Profile
 	user.GET("", handler.GetProfile)
 	// Update Profile
 	user.PATCH("", handler.UpdateProfile)
+	// Change Username
+	user.PATCH("/username", handler.ChangeUsername)
+	// Get Profile by Handle
+	user.GET("/by-handle/:handle", handler.GetProfileByHandle)
+
+	// Deactivate Account
+	user.POST("/deactivate", handler.Deactivate)
+	// Delete Account
+	user.DELETE("", handler.DeleteAccount)
+	// Export Data
+	user.POST("/export", middlewares.RateLimit(middlewares.NewRateLimitPolicy("user-export", 3, time.Hour, middlewares.KeyByUserOrIP)), handler.ExportData)
 
 	// Follow
 	user.POST(":id", handler.Follow)
@@ -29,4 +43,18 @@ func InitUser(ctx *gin.Engine, db *pgxpool.Pool, rdb *redis.Client) {
 	user.GET("/follower", handler.GetFollowers)
 	// Get Following
 	user.GET("/following", handler.GetFollowing)
+	// Who to Follow
+	user.GET("/suggestions", handler.GetSuggestions)
+	// Dismiss Suggestion
+	user.DELETE("/suggestions/:id", handler.DismissSuggestion)
+	// Block
+	user.POST("/:id/block", handler.Block)
+	// Unblock
+	user.DELETE("/:id/block", handler.Unblock)
+	// Get Followers of User
+	user.GET("/:id/followers", handler.GetUserFollowers)
+	// Get Following of User
+	user.GET("/:id/following", handler.GetUserFollowing)
+	// Get Mutual Followers
+	user.GET("/:id/mutual", handler.GetMutualFollowers)
 }
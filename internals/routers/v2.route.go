@@ -0,0 +1,108 @@
+package routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/configs"
+	"github.com/ntisrangga142/chat/internals/handlers"
+	"github.com/ntisrangga142/chat/internals/middlewares"
+	"github.com/ntisrangga142/chat/internals/rankers"
+	"github.com/ntisrangga142/chat/internals/repositories"
+	"github.com/ntisrangga142/chat/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// InitV2 memasang route /v2 dengan path resource jamak. Handler sama dengan
+// /v1 kecuali list yang di /v1 tidak berhalaman, bentuk response diubah oleh
+// middlewares.EnvelopeV2. Nama policy rate limit sama dengan /v1 supaya
+// kuotanya dihitung bersama.
+func InitV2(ctx gin.IRouter, stores repositories.Stores, rdb *redis.Client, mailer pkg.Mailer, providers map[string]*pkg.OIDCProvider, app configs.AppConfig) {
+	v2 := ctx.Group("/v2", middlewares.EnvelopeV2)
+
+	initAuthV2(v2, handlers.NewAuthHandler(stores.Auth, rdb, mailer, providers, app))
+	initUsersV2(v2, handlers.NewUserHandler(stores.User, rdb))
+	initPostsV2(v2, handlers.NewPostHandler(stores.Post, rdb, rankers.NewEngagementRanker()))
+
+	notif := handlers.NewNotificationHandler(stores.Notification)
+	v2.GET("/notifications", middlewares.Authentication, notif.GetUnreadNotificationsPage)
+}
+
+func initAuthV2(v2 gin.IRouter, handler *handlers.AuthHandler) {
+	auth := v2.Group("/auth")
+	authLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("auth", 10, time.Minute, middlewares.KeyByIP))
+
+	auth.POST("/login", authLimit, handler.Login)
+	auth.POST("/logout", middlewares.Authentication, handler.Logout)
+	auth.POST("/register", authLimit, handler.Register)
+
+	auth.POST("/verify/request", authLimit, handler.RequestVerification)
+	auth.POST("/verify", authLimit, handler.VerifyEmail)
+
+	auth.POST("/password/forgot", authLimit, handler.ForgotPassword)
+	auth.POST("/password/reset", authLimit, handler.ResetPassword)
+	auth.PATCH("/password", middlewares.Authentication, authLimit, handler.ChangePassword)
+	auth.PATCH("/email", middlewares.Authentication, authLimit, handler.ChangeEmail)
+	auth.POST("/email/confirm", authLimit, handler.ConfirmEmailChange)
+
+	auth.POST("/mfa/verify", authLimit, handler.VerifyMFA)
+	auth.POST("/mfa/enroll", middlewares.Authentication, authLimit, handler.EnrollMFA)
+	auth.POST("/mfa/enable", middlewares.Authentication, authLimit, handler.EnableMFA)
+	auth.DELETE("/mfa", middlewares.Authentication, authLimit, handler.DisableMFA)
+
+	auth.GET("/oidc/:provider/login", authLimit, handler.OIDCLogin)
+	auth.GET("/oidc/:provider/callback", authLimit, handler.OIDCCallback)
+}
+
+func initUsersV2(v2 gin.IRouter, handler *handlers.UserHandler) {
+	// Download export lewat signed link, tanpa login
+	v2.GET("/users/me/export/download", handler.DownloadExport)
+
+	users := v2.Group("/users")
+	users.Use(middlewares.Authentication, middlewares.Idempotency)
+
+	// akun yang sedang login
+	users.GET("/me", handler.GetProfile)
+	users.PATCH("/me", handler.UpdateProfile)
+	users.DELETE("/me", handler.DeleteAccount)
+	users.PATCH("/me/username", handler.ChangeUsername)
+	users.POST("/me/deactivate", handler.Deactivate)
+	users.POST("/me/export", middlewares.RateLimit(middlewares.NewRateLimitPolicy("user-export", 3, time.Hour, middlewares.KeyByUserOrIP)), handler.ExportData)
+	users.GET("/me/followers", handler.GetFollowers)
+	users.GET("/me/following", handler.GetFollowing)
+	users.GET("/me/suggestions", handler.GetSuggestions)
+	users.DELETE("/me/suggestions/:id", handler.DismissSuggestion)
+
+	// user lain, :id boleh berupa id atau username
+	users.GET("/by-handle/:handle", handler.GetProfileByHandle)
+	users.POST("/:id/follow", handler.Follow)
+	users.DELETE("/:id/follow", handler.Unfollow)
+	users.POST("/:id/block", handler.Block)
+	users.DELETE("/:id/block", handler.Unblock)
+	users.GET("/:id/followers", handler.GetUserFollowers)
+	users.GET("/:id/following", handler.GetUserFollowing)
+	users.GET("/:id/mutual", handler.GetMutualFollowers)
+}
+
+func initPostsV2(v2 gin.IRouter, handler *handlers.PostHandler) {
+	posts := v2.Group("/posts")
+	posts.Use(middlewares.Authentication, middlewares.Idempotency)
+
+	postLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-create", 10, time.Minute, middlewares.KeyByUserOrIP))
+	likeLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-like", 60, time.Minute, middlewares.KeyByUserOrIP))
+	commentLimit := middlewares.RateLimit(middlewares.NewRateLimitPolicy("post-comment", 20, time.Minute, middlewares.KeyByUserOrIP))
+
+	posts.GET("", handler.GetFollowingPostsPage)
+	posts.POST("", postLimit, handler.CreatePost)
+	posts.GET("/explore", handler.GetExplorePosts)
+	posts.GET("/saved", handler.GetSavedPosts)
+	posts.GET("/:id", handler.GetPostDetail)
+
+	posts.POST("/:id/like", likeLimit, handler.LikePost)
+	posts.DELETE("/:id/like", likeLimit, handler.UnlikePost)
+	posts.POST("/:id/save", handler.SavePost)
+	posts.DELETE("/:id/save", handler.UnsavePost)
+
+	posts.GET("/:id/comments", handler.GetAllCommentsByPost)
+	posts.POST("/:id/comments", commentLimit, handler.CreateComment)
+}
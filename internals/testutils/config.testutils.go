@@ -0,0 +1,36 @@
+// Package testutils berisi helper yang dipakai bersama oleh handler test dan
+// integration test: config, bootstrap key set JWT, mailer penampung dan
+// request HTTP ke router.
+package testutils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ntisrangga142/chat/internals/configs"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// NewConfig membuat config minimal untuk router test, issuer membedakan
+// token dari suite yang berbeda
+func NewConfig(issuer string) *configs.Config {
+	return &configs.Config{
+		App: configs.AppConfig{Addr: ":8080", URL: "http://localhost:3000", SwaggerHost: "localhost:8080", MFAIssuer: "SocialMedia"},
+		JWT: pkg.KeyConfig{Secret: "test-secret", Issuer: issuer},
+	}
+}
+
+// Main dipanggil dari TestMain: gin mode test dan key set JWT dari cfg
+// dipasang sebelum test berjalan
+func Main(m *testing.M, cfg *configs.Config) {
+	gin.SetMode(gin.TestMode)
+
+	keySet, err := pkg.LoadKeySet(cfg.JWT)
+	if err != nil {
+		panic(err)
+	}
+	pkg.InitKeySet(keySet)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,63 @@
+package testutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Client mengirim request langsung ke router tanpa server HTTP
+type Client struct {
+	Router http.Handler
+}
+
+func (c *Client) Do(method, path, token string, body io.Reader, contentType string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (c *Client) DoJSON(method, path, token string, body any) *httptest.ResponseRecorder {
+	if body == nil {
+		return c.Do(method, path, token, nil, "")
+	}
+	raw, _ := json.Marshal(body)
+	return c.Do(method, path, token, bytes.NewReader(raw), "application/json")
+}
+
+func (c *Client) DoForm(method, path, token string, fields map[string]string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		w.WriteField(k, v)
+	}
+	w.Close()
+	return c.Do(method, path, token, &buf, w.FormDataContentType())
+}
+
+func Decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var out T
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid json %q: %v", rec.Body.String(), err)
+	}
+	return out
+}
+
+func ExpectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, want, rec.Body.String())
+	}
+}
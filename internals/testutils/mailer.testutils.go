@@ -0,0 +1,42 @@
+package testutils
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+var mailTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// CaptureMailer menampung email untuk dibaca test
+type CaptureMailer struct {
+	mails chan pkg.Mail
+}
+
+func NewCaptureMailer() *CaptureMailer {
+	return &CaptureMailer{mails: make(chan pkg.Mail, 16)}
+}
+
+func (m *CaptureMailer) Send(ctx context.Context, mail pkg.Mail) error {
+	m.mails <- mail
+	return nil
+}
+
+// NextToken menunggu email berikutnya dan mengambil token dari link di dalamnya
+func (m *CaptureMailer) NextToken(t *testing.T) string {
+	t.Helper()
+	select {
+	case mail := <-m.mails:
+		match := mailTokenPattern.FindStringSubmatch(mail.Body)
+		if match == nil {
+			t.Fatalf("mail %q has no token", mail.Subject)
+		}
+		return match[1]
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail sent")
+		return ""
+	}
+}
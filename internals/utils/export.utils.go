@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// folder penyimpanan file upload & export
+const (
+	PostImgDir    = "public/post"
+	ProfileImgDir = "public/profile"
+	CoverImgDir   = "public/cover"
+	ExportDir     = "private/exports"
+)
+
+// masa berlaku link download export
+const ExportLinkTTL = 24 * time.Hour
+
+// BuildExportZip menulis data akun sebagai JSON beserta media ke file ZIP
+func BuildExportZip(data *models.UserExport, destPath string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(destPath)
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+
+	files := map[string]any{
+		"account.json":   data.Account,
+		"profile.json":   data.Profile,
+		"posts.json":     data.Posts,
+		"comments.json":  data.Comments,
+		"likes.json":     data.Likes,
+		"followers.json": data.Followers,
+		"following.json": data.Following,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(content); err != nil {
+			return err
+		}
+	}
+
+	// media
+	if data.Profile.Img != nil {
+		if err := addFileToZip(zw, filepath.Join(ProfileImgDir, *data.Profile.Img), "media/profile/"); err != nil {
+			return err
+		}
+	}
+	if data.Profile.CoverImg != nil {
+		if err := addFileToZip(zw, filepath.Join(CoverImgDir, *data.Profile.CoverImg), "media/cover/"); err != nil {
+			return err
+		}
+	}
+	for _, post := range data.Posts {
+		for _, img := range post.Images {
+			if err := addFileToZip(zw, img, fmt.Sprintf("media/posts/%d/", post.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+func addFileToZip(zw *zip.Writer, path, prefix string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		// file yang hilang dari disk dilewati saja
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	w, err := zw.Create(prefix + filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+// RemoveUploadedFile menghapus file upload, hanya di dalam folder baseDir
+func RemoveUploadedFile(baseDir, path string) error {
+	clean := filepath.Clean(path)
+	base := filepath.Clean(baseDir)
+	if !strings.HasPrefix(clean, base+string(os.PathSeparator)) {
+		return fmt.Errorf("refusing to remove %s outside %s", path, baseDir)
+	}
+	if err := os.Remove(clean); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
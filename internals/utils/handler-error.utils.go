@@ -1,18 +1,66 @@
 package utils
 
 import (
-	"log"
+	"errors"
+	"log/slog"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ntisrangga142/chat/internals/models"
 )
 
-func HandleError(ctx *gin.Context, code int, status, err string, err_real error) {
-	log.Printf("%s ----- Cause: %s\n", status, err_real.Error())
+// HandleError mengirim pesan aman ke client dan mencatat penyebab aslinya di log.
+// Field status selalu http.StatusText(code).
+func HandleError(ctx *gin.Context, code int, err string, err_real error) {
+	status := http.StatusText(code)
+	attrs := []any{"status_code", code, "message", err}
+	if err_real != nil {
+		attrs = append(attrs, "error", err_real.Error())
+	}
+	Logger(ctx).Log(ctx.Request.Context(), errorLevel(code), status, attrs...)
 	ctx.JSON(code, models.NewErrorResponse(status, err, code))
 }
 
-func HandleMiddlewareError(ctx *gin.Context, code int, status, err string) {
-	log.Printf("%s ----- Cause: %s\n", status, err)
+func HandleMiddlewareError(ctx *gin.Context, code int, err string) {
+	status := http.StatusText(code)
+	Logger(ctx).Log(ctx.Request.Context(), errorLevel(code), status, "status_code", code, "message", err)
 	ctx.AbortWithStatusJSON(code, models.NewErrorResponse(status, err, code))
 }
+
+// status HTTP untuk setiap jenis error domain
+var kindStatus = map[models.ErrorKind]int{
+	models.KindNotFound:   http.StatusNotFound,
+	models.KindConflict:   http.StatusConflict,
+	models.KindForbidden:  http.StatusForbidden,
+	models.KindValidation: http.StatusBadRequest,
+}
+
+// HandleDomainError memetakan models.DomainError ke status HTTP dan error_code-nya.
+// Error lain dianggap kesalahan server dan dijawab 500 dengan pesan fallback.
+func HandleDomainError(ctx *gin.Context, err error, fallback string) {
+	var domainErr *models.DomainError
+	if !errors.As(err, &domainErr) {
+		HandleError(ctx, http.StatusInternalServerError, fallback, err)
+		return
+	}
+
+	code := kindStatus[domainErr.Kind]
+	Logger(ctx).Log(ctx.Request.Context(), errorLevel(code), http.StatusText(code),
+		"status_code", code, "error_code", domainErr.Code, "message", domainErr.Message, "error", err.Error())
+	ctx.JSON(code, models.ErrorResponse{
+		Success:   false,
+		Status:    http.StatusText(code),
+		Code:      code,
+		ErrorCode: domainErr.Code,
+		Error:     domainErr.Message,
+		Details:   domainErr.Fields,
+	})
+}
+
+// 5xx adalah kesalahan server, 4xx cukup sebagai peringatan
+func errorLevel(code int) slog.Level {
+	if code >= http.StatusInternalServerError {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
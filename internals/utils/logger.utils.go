@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+type loggerKey struct{}
+
+// WithLogger menyimpan logger per request (request_id, user_id, ...) di context
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger mengambil logger dari context, atau slog.Default jika tidak ada.
+// Bisa menerima *gin.Context maupun ctx.Request.Context().
+func Logger(ctx context.Context) *slog.Logger {
+	if gc, ok := ctx.(*gin.Context); ok && gc.Request != nil {
+		ctx = gc.Request.Context()
+	}
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// SetRequestLogger mengganti logger request yang sedang berjalan
+func SetRequestLogger(ctx *gin.Context, logger *slog.Logger) {
+	ctx.Request = ctx.Request.WithContext(WithLogger(ctx.Request.Context(), logger))
+}
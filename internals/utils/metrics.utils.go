@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "chat"
+
+// Counter bisnis, dinaikkan handler setelah perubahan tersimpan
+var (
+	SignupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "signups_total",
+		Help:      "Accounts created, by sign up method (password or the oidc provider name).",
+	}, []string{"method"})
+	PostsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "posts_created_total",
+		Help:      "Posts created.",
+	})
+	LikesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "likes_total",
+		Help:      "Posts liked. Repeated likes of the same post are not counted.",
+	})
+	CommentsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "comments_total",
+		Help:      "Comments created.",
+	})
+)
+
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Name:      "cache_requests_total",
+	Help:      "Redis cache lookups by CacheHit, by cache name and result (hit, miss or error).",
+}, []string{"cache", "result"})
+
+// "Chat-ListPosts-Ranked-12" -> "Chat-ListPosts-Ranked", supaya label tidak
+// berisi id user / post
+func cacheName(redisKey string) string {
+	return strings.TrimRight(redisKey, "-0123456789")
+}
+
+// PoolCollector mengekspor pgxpool.Stat setiap kali /metrics di-scrape
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	newConns         *prometheus.Desc
+	maxLifetimeClose *prometheus.Desc
+	maxIdleClose     *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db_pool", name), help, nil, nil)
+	}
+	return &PoolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_conns", "Connections currently in use."),
+		idleConns:        desc("idle_conns", "Idle connections in the pool."),
+		totalConns:       desc("total_conns", "Open connections, acquired, idle and constructing."),
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		acquireCount:     desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Total time spent waiting to acquire a connection."),
+		emptyAcquire:     desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+		canceledAcquire:  desc("canceled_acquires_total", "Acquires canceled by their context."),
+		newConns:         desc("new_conns_total", "Connections opened."),
+		maxLifetimeClose: desc("max_lifetime_destroys_total", "Connections closed because of MaxConnLifetime."),
+		maxIdleClose:     desc("max_idle_destroys_total", "Connections closed because of MaxConnIdleTime."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	gauge := func(desc *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v)
+	}
+	counter := func(desc *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, v)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	counter(c.acquireCount, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.maxLifetimeClose, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.maxIdleClose, float64(stat.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ParseLimit membaca query ?limit=, default DefaultPageLimit dan maksimal MaxPageLimit
+func ParseLimit(raw string) int {
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return DefaultPageLimit
+	}
+	return min(limit, MaxPageLimit)
+}
+
+// EncodeCursor membuat cursor opaque dari posisi (waktu, id) baris terakhir
+func EncodeCursor(at time.Time, id int) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", at.UnixMicro(), id))
+}
+
+// DecodeCursor kebalikan EncodeCursor, cursor kosong berarti halaman pertama (nil)
+func DecodeCursor(cursor string) (*time.Time, int, error) {
+	if cursor == "" {
+		return nil, 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, errors.New("invalid cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, 0, errors.New("invalid cursor")
+	}
+	micro, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, 0, errors.New("invalid cursor")
+	}
+	cursorID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, 0, errors.New("invalid cursor")
+	}
+	at := time.UnixMicro(micro).UTC()
+	return &at, cursorID, nil
+}
+
+// SearchPattern membuat pola ILIKE "%q%" dengan escape karakter wildcard
+func SearchPattern(q string) string {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return ""
+	}
+	q = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q)
+	return "%" + q + "%"
+}
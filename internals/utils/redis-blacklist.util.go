@@ -2,7 +2,10 @@ package utils
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/ntisrangga142/chat/pkg"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -13,3 +16,59 @@ func IsBlacklisted(ctx context.Context, rdb *redis.Client, token string) (bool,
 	}
 	return res == 1, nil
 }
+
+// ConsumeToken menandai token sebagai terpakai, false jika sudah pernah dipakai.
+// SETNX membuat dua request bersamaan tidak bisa sama-sama lolos.
+func ConsumeToken(ctx context.Context, rdb *redis.Client, token string, ttl time.Duration) (bool, error) {
+	return rdb.SetNX(ctx, "Blacklist:"+token, token, ttl).Result()
+}
+
+func sessionVersionKey(uid int) string {
+	return fmt.Sprintf("SessionVersion:%d", uid)
+}
+
+// RevokeSessions menaikkan versi sesi user sehingga semua access token yang
+// membawa versi sebelumnya ditolak, tanpa bergantung pada presisi iat.
+func RevokeSessions(ctx context.Context, rdb *redis.Client, uid int) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, sessionVersionKey(uid))
+		pipe.Expire(ctx, sessionVersionKey(uid), pkg.TokenTTL)
+		return nil
+	})
+	return err
+}
+
+// SessionVersion mengambil versi sesi untuk token baru. Masa simpan key
+// diperpanjang supaya key tidak hilang selama token dengan versi ini masih berlaku.
+func SessionVersion(ctx context.Context, rdb *redis.Client, uid int) (int64, error) {
+	var get *redis.StringCmd
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, sessionVersionKey(uid))
+		pipe.Expire(ctx, sessionVersionKey(uid), pkg.TokenTTL)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	return parseSessionVersion(get)
+}
+
+// IsSessionRevoked mengecek apakah versi sesi token sudah tidak berlaku
+func IsSessionRevoked(ctx context.Context, rdb *redis.Client, uid int, version int64) (bool, error) {
+	current, err := parseSessionVersion(rdb.Get(ctx, sessionVersionKey(uid)))
+	if err != nil {
+		return false, err
+	}
+	// versi lebih baru dari yang tersimpan berarti key hilang (misal Redis
+	// dikosongkan), ditolak juga supaya pencabutan tidak ikut hilang
+	return version != current, nil
+}
+
+// key yang belum ada berarti sesi belum pernah dicabut
+func parseSessionVersion(cmd *redis.StringCmd) (int64, error) {
+	version, err := cmd.Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, err
+}
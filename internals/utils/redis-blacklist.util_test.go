@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+func TestSessionVersionOutlivesIssuedTokens(t *testing.T) {
+	mr, rdb := newMiniredis(t)
+	ctx := context.Background()
+
+	if err := utils.RevokeSessions(ctx, rdb, 1); err != nil {
+		t.Fatal(err)
+	}
+	// login menjelang key habis, key harus bertahan selama token ini berlaku
+	mr.FastForward(pkg.TokenTTL - time.Minute)
+	version, err := utils.SessionVersion(ctx, rdb, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Fatalf("version = %d, want 1", version)
+	}
+
+	mr.FastForward(pkg.TokenTTL - time.Minute)
+	if revoked, err := utils.IsSessionRevoked(ctx, rdb, 1, version); err != nil || revoked {
+		t.Fatalf("token still within its TTL: revoked = %v, err = %v", revoked, err)
+	}
+
+	if err := utils.RevokeSessions(ctx, rdb, 1); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := utils.IsSessionRevoked(ctx, rdb, 1, version); err != nil || !revoked {
+		t.Fatalf("after revocation: revoked = %v, err = %v", revoked, err)
+	}
+}
@@ -3,56 +3,48 @@ package utils
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// CacheHit membaca cache ke data. Error apa pun dianggap cache miss oleh pemanggil,
+// jadi error redis dicatat di sini.
 func CacheHit(rctx context.Context, rdb *redis.Client, redisKey string, data any) error {
-	cmd := rdb.Get(rctx, redisKey)
-	if cmd.Err() != nil {
-		if cmd.Err() == redis.Nil {
-			log.Printf("Key %s does not exist.\n", redisKey)
-		} else {
-			log.Printf("Redis Error.\nCause: %s\n", cmd.Err())
-		}
-		return cmd.Err()
+	cache := cacheName(redisKey)
+	cmdByte, err := rdb.Get(rctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		cacheRequests.WithLabelValues(cache, "miss").Inc()
+		Logger(rctx).Debug("cache miss", "key", redisKey)
+		return err
 	}
-
-	cmdByte, err := cmd.Bytes()
 	if err != nil {
-		log.Println("Internal Server Error.\nCause:", err)
+		cacheRequests.WithLabelValues(cache, "error").Inc()
+		Logger(rctx).Warn("cache read failed", "key", redisKey, "error", err)
 		return err
 	}
 
 	if err := json.Unmarshal(cmdByte, data); err != nil {
-		log.Println("Internal Server Error.\nCause:", err)
+		cacheRequests.WithLabelValues(cache, "error").Inc()
+		Logger(rctx).Warn("cache decode failed", "key", redisKey, "error", err)
 		return err
 	}
 
+	cacheRequests.WithLabelValues(cache, "hit").Inc()
+	Logger(rctx).Debug("cache hit", "key", redisKey)
 	return nil
 }
 
+// RenewCache & InvalidateCache tidak mencatat error, pemanggil yang memutuskan
 func RenewCache(rctx context.Context, rdb *redis.Client, redisKey string, data any, waktu time.Duration) error {
 	bt, err := json.Marshal(data)
 	if err != nil {
-		log.Println("Internal Server Error.\nCause:", err)
-		return err
-	}
-
-	if err := rdb.Set(rctx, redisKey, bt, waktu*time.Minute).Err(); err != nil {
-		log.Printf("Redis Error.\nCause: %s\n", err)
 		return err
 	}
-
-	return nil
+	return rdb.Set(rctx, redisKey, bt, waktu*time.Minute).Err()
 }
 
 func InvalidateCache(rctx context.Context, rdb *redis.Client, redisKey string) error {
-	if err := rdb.Del(rctx, redisKey).Err(); err != nil {
-		log.Printf("Redis Error.\nCause: %s\n", err)
-		return err
-	}
-	return nil
+	return rdb.Del(rctx, redisKey).Err()
 }
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Skor explore memakai forward decay: setiap like/komentar menambah
+// weight * e^((t - epoch) / tau). Karena semua skor "meluruh" dengan faktor
+// yang sama, urutan ZSET setara dengan skor yang meluruh eksponensial terhadap
+// waktu, tanpa perlu menghitung ulang semua post. Epoch digeser berkala oleh
+// job supaya angka tidak overflow.
+const (
+	exploreScoreKey   = "Chat-Explore-Score"
+	exploreCreatedKey = "Chat-Explore-Created"
+	exploreEpochKey   = "Chat-Explore-Epoch"
+	exploreSessionKey = "Chat-Explore-Session:%s"
+
+	ExploreLikeWeight    = 1.0
+	ExploreCommentWeight = 2.0
+	// post lebih tua dari ini keluar dari explore
+	ExploreWindow = 7 * 24 * time.Hour
+	// setiap ExploreHalfLife nilai sebuah interaksi tinggal setengah
+	ExploreHalfLife = 12 * time.Hour
+	// epoch digeser setelah selisih ini (e^(2d/17.3h) masih jauh dari overflow)
+	exploreRebaseAfter = 48 * time.Hour
+	// masa berlaku snapshot ranking satu sesi explore
+	ExploreSessionTTL = 30 * time.Minute
+)
+
+// ExploreTau adalah konstanta decay (detik)
+var ExploreTau = ExploreHalfLife.Seconds() / math.Ln2
+
+var exploreIncrScript = redis.NewScript(`
+local created = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if not created or tonumber(created) < tonumber(ARGV[5]) then
+	return 0
+end
+-- epoch hanya dipasang ExploreJob saat seed, tanpa epoch skor belum ada
+-- dan interaksi ini akan ikut terhitung dari database saat seed
+local epoch = tonumber(redis.call('GET', KEYS[3]))
+if not epoch then
+	return 0
+end
+local inc = tonumber(ARGV[2]) * math.exp((tonumber(ARGV[3]) - epoch) / tonumber(ARGV[4]))
+local score = tonumber(redis.call('ZINCRBY', KEYS[1], inc, ARGV[1]))
+if score < 0 then
+	redis.call('ZADD', KEYS[1], 0, ARGV[1])
+end
+return 1
+`)
+
+var exploreRebaseScript = redis.NewScript(`
+local epoch = tonumber(redis.call('GET', KEYS[2]))
+if not epoch then
+	return 0
+end
+local now = tonumber(ARGV[1])
+if now - epoch < tonumber(ARGV[2]) then
+	return 0
+end
+local factor = math.exp((epoch - now) / tonumber(ARGV[3]))
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('ZUNIONSTORE', KEYS[1], 1, KEYS[1], 'WEIGHTS', factor)
+end
+redis.call('SET', KEYS[2], now)
+return 1
+`)
+
+// ExploreScoreAt menghitung kontribusi satu interaksi pada waktu at terhadap epoch
+func ExploreScoreAt(weight float64, at, epoch time.Time) float64 {
+	return weight * math.Exp(at.Sub(epoch).Seconds()/ExploreTau)
+}
+
+// TrackExplorePost mendaftarkan post baru sebagai kandidat explore
+func TrackExplorePost(ctx context.Context, rdb *redis.Client, postID int, createdAt time.Time) error {
+	member := strconv.Itoa(postID)
+	pipe := rdb.TxPipeline()
+	pipe.ZAdd(ctx, exploreCreatedKey, redis.Z{Score: float64(createdAt.Unix()), Member: member})
+	pipe.ZAddNX(ctx, exploreScoreKey, redis.Z{Score: 0, Member: member})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// UntrackExplorePosts menghapus post dari kandidat explore, misal setelah akun dipurge
+func UntrackExplorePosts(ctx context.Context, rdb *redis.Client, postIDs []int) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	members := make([]any, len(postIDs))
+	for i, id := range postIDs {
+		members[i] = strconv.Itoa(id)
+	}
+	pipe := rdb.TxPipeline()
+	pipe.ZRem(ctx, exploreScoreKey, members...)
+	pipe.ZRem(ctx, exploreCreatedKey, members...)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecordEngagement menambah (atau mengurangi, jika weight negatif) skor post
+// untuk interaksi pada waktu at. Post di luar ExploreWindow diabaikan, begitu
+// juga semua interaksi sebelum ExploreJob memasang epoch.
+func RecordEngagement(ctx context.Context, rdb *redis.Client, postID int, weight float64, at time.Time) error {
+	now := time.Now()
+	return exploreIncrScript.Run(ctx, rdb,
+		[]string{exploreScoreKey, exploreCreatedKey, exploreEpochKey},
+		strconv.Itoa(postID), weight, at.Unix(), ExploreTau, now.Add(-ExploreWindow).Unix(),
+	).Err()
+}
+
+// GetExploreIDs mengambil id post dengan skor tertinggi mulai dari offset
+func GetExploreIDs(ctx context.Context, rdb *redis.Client, offset, count int) ([]int, error) {
+	members, err := rdb.ZRevRange(ctx, exploreScoreKey, int64(offset), int64(offset+count-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.Atoi(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// SaveExploreSession menyimpan snapshot urutan post satu sesi explore. Skor
+// ZSET berubah di setiap like dan komentar, jadi halaman berikutnya dibaca
+// dari snapshot supaya tidak ada post yang terulang atau terlewat.
+func SaveExploreSession(ctx context.Context, rdb *redis.Client, session string, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	key := fmt.Sprintf(exploreSessionKey, session)
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	pipe := rdb.TxPipeline()
+	pipe.RPush(ctx, key, members...)
+	pipe.Expire(ctx, key, ExploreSessionTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetExploreSession mengambil count id dari snapshot mulai offset, ok false
+// jika sesi sudah kedaluwarsa
+func GetExploreSession(ctx context.Context, rdb *redis.Client, session string, offset, count int) ([]int, bool, error) {
+	key := fmt.Sprintf(exploreSessionKey, session)
+	pipe := rdb.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	members := pipe.LRange(ctx, key, int64(offset), int64(offset+count-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, false, err
+	}
+	if exists.Val() == 0 {
+		return nil, false, nil
+	}
+	ids := make([]int, 0, len(members.Val()))
+	for _, m := range members.Val() {
+		if id, err := strconv.Atoi(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true, nil
+}
+
+// EncodeExploreCursor membuat cursor opaque dari sesi explore dan posisi di snapshot
+func EncodeExploreCursor(session string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s:%d", session, offset))
+}
+
+// DecodeExploreCursor kebalikan EncodeExploreCursor, cursor kosong berarti sesi baru
+func DecodeExploreCursor(cursor string) (string, int, error) {
+	if cursor == "" {
+		return "", 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, errors.New("invalid cursor")
+	}
+	session, pos, ok := strings.Cut(string(raw), ":")
+	if !ok || session == "" {
+		return "", 0, errors.New("invalid cursor")
+	}
+	offset, err := strconv.Atoi(pos)
+	if err != nil || offset < 0 {
+		return "", 0, errors.New("invalid cursor")
+	}
+	return session, offset, nil
+}
+
+// ExploreEpoch mengembalikan epoch skor saat ini, ok false jika explore belum pernah diisi
+func ExploreEpoch(ctx context.Context, rdb *redis.Client) (time.Time, bool, error) {
+	res, err := rdb.Get(ctx, exploreEpochKey).Int64()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.Unix(res, 0), true, nil
+}
+
+// SeedExplore mengisi ulang explore dari database (cold start)
+func SeedExplore(ctx context.Context, rdb *redis.Client, epoch time.Time, created map[int]time.Time, scores map[int]float64) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, exploreScoreKey, exploreCreatedKey)
+	for id, at := range created {
+		member := strconv.Itoa(id)
+		pipe.ZAdd(ctx, exploreCreatedKey, redis.Z{Score: float64(at.Unix()), Member: member})
+		pipe.ZAdd(ctx, exploreScoreKey, redis.Z{Score: scores[id], Member: member})
+	}
+	pipe.Set(ctx, exploreEpochKey, epoch.Unix(), 0)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MaintainExplore menggeser epoch bila perlu dan membuang post yang sudah keluar window
+func MaintainExplore(ctx context.Context, rdb *redis.Client) error {
+	now := time.Now()
+	if err := exploreRebaseScript.Run(ctx, rdb, []string{exploreScoreKey, exploreEpochKey},
+		now.Unix(), exploreRebaseAfter.Seconds(), ExploreTau).Err(); err != nil {
+		return err
+	}
+
+	expired, err := rdb.ZRangeByScore(ctx, exploreCreatedKey, &redis.ZRangeBy{
+		Min: "-inf", Max: strconv.FormatInt(now.Add(-ExploreWindow).Unix(), 10),
+	}).Result()
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	members := make([]any, len(expired))
+	for i, m := range expired {
+		members[i] = m
+	}
+	pipe := rdb.TxPipeline()
+	pipe.ZRem(ctx, exploreScoreKey, members...)
+	pipe.ZRem(ctx, exploreCreatedKey, members...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
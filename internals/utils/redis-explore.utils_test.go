@@ -0,0 +1,269 @@
+package utils_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ntisrangga142/chat/internals/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const exploreScoreKey = "Chat-Explore-Score"
+
+func newMiniredis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func exploreScore(t *testing.T, rdb *redis.Client, postID string) float64 {
+	t.Helper()
+	score, err := rdb.ZScore(context.Background(), exploreScoreKey, postID).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return score
+}
+
+func TestExploreScoreAt(t *testing.T) {
+	epoch := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		weight float64
+		at     time.Time
+		want   float64
+	}{
+		{name: "at epoch", weight: utils.ExploreLikeWeight, at: epoch, want: 1},
+		{name: "comment at epoch", weight: utils.ExploreCommentWeight, at: epoch, want: 2},
+		// interaksi satu half-life lebih baru bernilai dua kali lipat
+		{name: "one half-life later", weight: 1, at: epoch.Add(utils.ExploreHalfLife), want: 2},
+		{name: "two half-lives later", weight: 1, at: epoch.Add(2 * utils.ExploreHalfLife), want: 4},
+		{name: "one half-life earlier", weight: 1, at: epoch.Add(-utils.ExploreHalfLife), want: 0.5},
+		{name: "unlike", weight: -utils.ExploreLikeWeight, at: epoch.Add(utils.ExploreHalfLife), want: -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utils.ExploreScoreAt(tt.weight, tt.at, epoch); !almostEqual(got, tt.want) {
+				t.Fatalf("ExploreScoreAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordEngagement(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newMiniredis(t)
+	now := time.Now().Truncate(time.Second)
+	epoch := now.Add(-24 * time.Hour)
+
+	if err := utils.SeedExplore(ctx, rdb, epoch, map[int]time.Time{
+		1: now.Add(-30 * time.Hour),
+		2: now.Add(-time.Hour),
+		3: now.Add(-8 * 24 * time.Hour),
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// post 1: dua like 24 jam lalu, post 2: satu like sekarang
+	record := func(postID int, weight float64, at time.Time) {
+		t.Helper()
+		if err := utils.RecordEngagement(ctx, rdb, postID, weight, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(1, utils.ExploreLikeWeight, epoch)
+	record(1, utils.ExploreLikeWeight, epoch)
+	record(2, utils.ExploreLikeWeight, now)
+	// post di luar ExploreWindow diabaikan
+	record(3, utils.ExploreCommentWeight, now)
+
+	tests := []struct {
+		post string
+		want float64
+	}{
+		{post: "1", want: 2 * utils.ExploreScoreAt(utils.ExploreLikeWeight, epoch, epoch)},
+		{post: "2", want: utils.ExploreScoreAt(utils.ExploreLikeWeight, now, epoch)},
+		{post: "3", want: 0},
+	}
+	for _, tt := range tests {
+		if got := exploreScore(t, rdb, tt.post); !almostEqual(got, tt.want) {
+			t.Fatalf("score post %s = %v, want %v", tt.post, got, tt.want)
+		}
+	}
+
+	// satu like baru (nilai 4) mengalahkan dua like sehari lalu (nilai 2)
+	ids, err := utils.GetExploreIDs(ctx, rdb, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 1, 3}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ranking = %v, want %v", ids, want)
+	}
+
+	// unlike tidak membuat skor negatif
+	record(2, -utils.ExploreLikeWeight, now)
+	record(2, -utils.ExploreLikeWeight, now)
+	if got := exploreScore(t, rdb, "2"); got != 0 {
+		t.Fatalf("score after unlikes = %v, want 0", got)
+	}
+}
+
+func TestRecordEngagementWaitsForEpoch(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newMiniredis(t)
+	now := time.Now()
+
+	if err := utils.TrackExplorePost(ctx, rdb, 1, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RecordEngagement(ctx, rdb, 1, utils.ExploreCommentWeight, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// epoch hanya dipasang ExploreJob, supaya job tetap melakukan seed
+	if epoch, ok, err := utils.ExploreEpoch(ctx, rdb); err != nil || ok {
+		t.Fatalf("ExploreEpoch = %v, %v, %v, want no epoch before the job seeds", epoch, ok, err)
+	}
+	if got := exploreScore(t, rdb, "1"); got != 0 {
+		t.Fatalf("score = %v, want 0 before the epoch is set", got)
+	}
+
+	epoch := now.Add(-time.Hour).Truncate(time.Second)
+	if err := utils.SeedExplore(ctx, rdb, epoch, map[int]time.Time{1: now}, map[int]float64{1: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.RecordEngagement(ctx, rdb, 1, utils.ExploreCommentWeight, now); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := exploreScore(t, rdb, "1"), utils.ExploreScoreAt(utils.ExploreCommentWeight, now.Truncate(time.Second), epoch); !almostEqual(got, want) {
+		t.Fatalf("score = %v, want %v", got, want)
+	}
+}
+
+func TestMaintainExploreRebase(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name       string
+		epochAge   time.Duration
+		wantRebase bool
+	}{
+		{name: "epoch still recent", epochAge: 47 * time.Hour},
+		{name: "epoch too old", epochAge: 49 * time.Hour, wantRebase: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, rdb := newMiniredis(t)
+			epoch := now.Add(-tt.epochAge)
+			scores := map[int]float64{1: 8, 2: 3, 3: 5}
+			if err := utils.SeedExplore(ctx, rdb, epoch, map[int]time.Time{
+				1: now.Add(-time.Hour),
+				2: now.Add(-2 * time.Hour),
+				3: now.Add(-8 * 24 * time.Hour),
+			}, scores); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := utils.MaintainExplore(ctx, rdb); err != nil {
+				t.Fatal(err)
+			}
+
+			newEpoch, _, err := utils.ExploreEpoch(ctx, rdb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			factor := 1.0
+			if tt.wantRebase {
+				if newEpoch.Before(now) || newEpoch.After(now.Add(5*time.Second)) {
+					t.Fatalf("epoch = %v, want moved to now %v", newEpoch, now)
+				}
+				// skor lama dikali e^((epoch - epoch baru) / tau), urutan tetap
+				factor = utils.ExploreScoreAt(1, epoch, newEpoch)
+			} else if !newEpoch.Equal(epoch) {
+				t.Fatalf("epoch = %v, want unchanged %v", newEpoch, epoch)
+			}
+			for _, id := range []int{1, 2} {
+				want := scores[id] * factor
+				if got := exploreScore(t, rdb, strconv.Itoa(id)); !almostEqual(got, want) {
+					t.Fatalf("score post %d = %v, want %v", id, got, want)
+				}
+			}
+
+			// post di luar window dibuang
+			ids, err := utils.GetExploreIDs(ctx, rdb, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []int{1, 2}; !reflect.DeepEqual(ids, want) {
+				t.Fatalf("ranking = %v, want %v", ids, want)
+			}
+		})
+	}
+}
+
+func TestExploreSession(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newMiniredis(t)
+
+	if err := utils.SaveExploreSession(ctx, rdb, "s1", []int{5, 3, 9, 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		session string
+		offset  int
+		count   int
+		want    []int
+		wantOK  bool
+	}{
+		{name: "first page", session: "s1", count: 2, want: []int{5, 3}, wantOK: true},
+		{name: "last page", session: "s1", offset: 2, count: 3, want: []int{9, 1}, wantOK: true},
+		{name: "past the end", session: "s1", offset: 4, count: 2, want: []int{}, wantOK: true},
+		{name: "unknown session", session: "s2", count: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, ok, err := utils.GetExploreSession(ctx, rdb, tt.session, tt.offset, tt.count)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || (ok && !reflect.DeepEqual(ids, tt.want)) {
+				t.Fatalf("GetExploreSession = %v, %v, want %v, %v", ids, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	mr.FastForward(utils.ExploreSessionTTL + time.Second)
+	if _, ok, err := utils.GetExploreSession(ctx, rdb, "s1", 0, 2); err != nil || ok {
+		t.Fatalf("expired session ok = %v, err = %v, want not ok", ok, err)
+	}
+}
+
+func TestExploreCursor(t *testing.T) {
+	session, offset, err := utils.DecodeExploreCursor(utils.EncodeExploreCursor("abc_-123", 40))
+	if err != nil || session != "abc_-123" || offset != 40 {
+		t.Fatalf("round trip = %q, %d, %v", session, offset, err)
+	}
+	if session, _, err := utils.DecodeExploreCursor(""); err != nil || session != "" {
+		t.Fatalf("empty cursor = %q, %v, want new session", session, err)
+	}
+	for _, cursor := range []string{"not base64!", "YWJj" /* "abc" */, "OjQw" /* ":40" */, "YWJjOi0x" /* "abc:-1" */} {
+		if _, _, err := utils.DecodeExploreCursor(cursor); err == nil {
+			t.Fatalf("cursor %q accepted, want error", cursor)
+		}
+	}
+}
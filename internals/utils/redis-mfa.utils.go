@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// jumlah kode 2FA salah berturut-turut sebelum akun dikunci
+const MaxMFAFailures = 5
+
+// lama akun dikunci, dihitung dari kegagalan terakhir
+const MFALockout = 15 * time.Minute
+
+func mfaFailureKey(uid int) string {
+	return fmt.Sprintf("MFAFail:%d", uid)
+}
+
+// IsMFALocked mengecek apakah verifikasi 2FA user sedang dikunci
+func IsMFALocked(ctx context.Context, rdb *redis.Client, uid int) (bool, error) {
+	res, err := rdb.Get(ctx, mfaFailureKey(uid)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	failures, err := strconv.Atoi(res)
+	if err != nil {
+		return false, err
+	}
+	return failures >= MaxMFAFailures, nil
+}
+
+// RecordMFAFailure menambah hitungan kode salah dan memperpanjang masa kunci
+func RecordMFAFailure(ctx context.Context, rdb *redis.Client, uid int) error {
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, mfaFailureKey(uid))
+		pipe.Expire(ctx, mfaFailureKey(uid), MFALockout)
+		return nil
+	})
+	return err
+}
+
+// ResetMFAFailures menghapus hitungan setelah verifikasi berhasil
+func ResetMFAFailures(ctx context.Context, rdb *redis.Client, uid int) error {
+	return rdb.Del(ctx, mfaFailureKey(uid)).Err()
+}
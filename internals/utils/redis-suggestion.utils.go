@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ntisrangga142/chat/internals/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// jumlah kandidat yang disimpan per user
+	SuggestionLimit = 50
+	// masa berlaku hasil precompute, lebih lama dari interval job
+	SuggestionTTL = 2 * time.Hour
+)
+
+func suggestionKey(uid int) string {
+	return fmt.Sprintf("Chat-Suggestions-%d", uid)
+}
+
+// SaveSuggestions mengganti ZSET saran milik user dengan hasil precompute terbaru.
+// List kosong tetap disimpan (placeholder) supaya tidak dihitung ulang setiap request.
+func SaveSuggestions(ctx context.Context, rdb *redis.Client, uid int, scores []models.SuggestionScore, ttl time.Duration) error {
+	key := suggestionKey(uid)
+	members := make([]redis.Z, 0, len(scores)+1)
+	members = append(members, redis.Z{Score: -1, Member: "0"})
+	for _, s := range scores {
+		members = append(members, redis.Z{Score: s.Score, Member: strconv.Itoa(s.ID)})
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSuggestionIDs mengambil id kandidat dengan skor tertinggi.
+// found false jika belum pernah dihitung atau sudah kedaluwarsa.
+func GetSuggestionIDs(ctx context.Context, rdb *redis.Client, uid, limit int) ([]int, bool, error) {
+	members, err := rdb.ZRevRangeByScore(ctx, suggestionKey(uid), &redis.ZRangeBy{
+		Min: "0", Max: "+inf", Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	exists, err := rdb.Exists(ctx, suggestionKey(uid)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.Atoi(m)
+		if err != nil || id == 0 {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, exists == 1, nil
+}
+
+// RemoveSuggestion membuang satu kandidat dari ZSET saran user
+func RemoveSuggestion(ctx context.Context, rdb *redis.Client, uid, targetID int) error {
+	return rdb.ZRem(ctx, suggestionKey(uid), strconv.Itoa(targetID)).Err()
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// SignDownload membuat query string file + expires + sig untuk link download
+func SignDownload(file string, expiresAt time.Time) (string, error) {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	sig, err := pkg.SignOneTimeToken(fmt.Sprintf("download:%s:%s", file, expires))
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("file", file)
+	q.Set("expires", expires)
+	q.Set("sig", sig)
+	return q.Encode(), nil
+}
+
+// VerifyDownload memastikan link download belum expired dan signature cocok
+func VerifyDownload(file, expires, sig string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expires: %w", err)
+	}
+	if time.Now().Unix() > exp {
+		return fmt.Errorf("download link expired")
+	}
+
+	expected, err := pkg.SignOneTimeToken(fmt.Sprintf("download:%s:%s", file, expires))
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
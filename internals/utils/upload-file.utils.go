@@ -10,19 +10,27 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SaveUploadedFile menyimpan file upload
-func SaveUploadedFile(ctx *gin.Context, file *multipart.FileHeader, destDir string, filename string) (string, error) {
-	// Validasi ekstensi
+// MaxImageSize batas ukuran satu file gambar
+const MaxImageSize = 1 << 20 // 1 MB
+
+// ValidateImageFile memeriksa ekstensi dan ukuran file gambar
+func ValidateImageFile(file *multipart.FileHeader) error {
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return "", fmt.Errorf("invalid file type: only jpg, jpeg, png allowed")
+		return fmt.Errorf("invalid file type: only jpg, jpeg, png allowed")
+	}
+	if file.Size > MaxImageSize {
+		return fmt.Errorf("file too large: maximum size is 1MB")
 	}
+	return nil
+}
 
-	// Validasi ukuran file (max 1 MB)
-	const maxSize = 1 << 20 // 1 MB
-	if file.Size > maxSize {
-		return "", fmt.Errorf("file too large: maximum size is 1MB")
+// SaveUploadedFile menyimpan file upload
+func SaveUploadedFile(ctx *gin.Context, file *multipart.FileHeader, destDir string, filename string) (string, error) {
+	if err := ValidateImageFile(file); err != nil {
+		return "", err
 	}
+	ext := strings.ToLower(filepath.Ext(file.Filename))
 
 	// Buat folder tujuan jika belum ada
 	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
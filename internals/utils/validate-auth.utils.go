@@ -7,6 +7,9 @@ func ValidateEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// PasswordRequirement dikirim ke client saat ValidatePassword gagal
+const PasswordRequirement = "password must be at least 8 characters with upper and lower case letters, a number and a symbol"
+
 func ValidatePassword(password string) bool {
 	var (
 		lowerRegex   = regexp.MustCompile(`[a-z]`)
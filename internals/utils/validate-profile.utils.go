@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+const (
+	// nilai birthdate_visibility dan follow_list_visibility
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+
+	// kode negara untuk nomor lokal yang diawali 0
+	DefaultPhoneCountryCode = "62"
+	// umur minimal pemilik akun
+	minimumAge = 13
+)
+
+var (
+	e164Regex      = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	phoneStripper  = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	maxFieldLength = map[string]int{
+		"fullname": 100,
+		"bio":      160,
+		"website":  255,
+		"location": 100,
+		"pronouns": 40,
+	}
+)
+
+// Field profile yang boleh diubah lewat PATCH /user
+var profileFields = map[string]func(string) (string, error){
+	"fullname":               validateText("fullname"),
+	"bio":                    validateText("bio"),
+	"location":               validateText("location"),
+	"pronouns":               validateText("pronouns"),
+	"website":                NormalizeWebsite,
+	"phone":                  NormalizePhone,
+	"birthdate":              validateBirthdate,
+	"birthdate_visibility":   validateVisibility,
+	"follow_list_visibility": validateVisibility,
+}
+
+// field yang tidak boleh dikosongkan lewat null
+var requiredProfileFields = map[string]bool{
+	"fullname":               true,
+	"birthdate_visibility":   true,
+	"follow_list_visibility": true,
+}
+
+// gambar hanya bisa diganti lewat upload multipart, tapi boleh dihapus dengan null
+var removableProfileFields = map[string]bool{
+	"img":       true,
+	"cover_img": true,
+}
+
+// DecodeMergePatch membaca body JSON merge-patch (RFC 7396) untuk profile.
+// Nilai null berarti kosongkan field, field yang tidak dikirim tidak diubah.
+func DecodeMergePatch(body io.Reader) (map[string]*string, error) {
+	var raw map[string]any
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	patch := make(map[string]*string, len(raw))
+	for field, val := range raw {
+		switch v := val.(type) {
+		case nil:
+			patch[field] = nil
+		case string:
+			patch[field] = &v
+		default:
+			return nil, fmt.Errorf("%s: must be a string or null", field)
+		}
+	}
+	return patch, nil
+}
+
+// ValidateProfilePatch memvalidasi dan menormalisasi patch menjadi kolom -> nilai
+// (nil untuk NULL). Semua pesan error dikumpulkan supaya client bisa memperbaiki sekaligus.
+func ValidateProfilePatch(patch map[string]*string) (map[string]any, error) {
+	updates := make(map[string]any, len(patch))
+	var fields []models.FieldError
+	invalid := func(field, code, message string) {
+		fields = append(fields, models.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	for field, val := range patch {
+		if removableProfileFields[field] {
+			if val != nil {
+				invalid(field, "multipart_required", "upload the image as multipart form-data")
+				continue
+			}
+			updates[field] = nil
+			continue
+		}
+
+		validate, ok := profileFields[field]
+		if !ok {
+			invalid(field, "unknown_field", "unknown field")
+			continue
+		}
+
+		if val == nil || strings.TrimSpace(*val) == "" {
+			if requiredProfileFields[field] {
+				invalid(field, "required", "cannot be empty")
+				continue
+			}
+			updates[field] = nil
+			continue
+		}
+
+		normalized, err := validate(strings.TrimSpace(*val))
+		if err != nil {
+			invalid(field, "invalid", err.Error())
+			continue
+		}
+		updates[field] = normalized
+	}
+
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		msgs := make([]string, len(fields))
+		for i, f := range fields {
+			msgs[i] = f.Field + ": " + f.Message
+		}
+		return nil, models.NewValidationError(ErrValidation, strings.Join(msgs, "; "), fields...)
+	}
+	return updates, nil
+}
+
+func validateText(field string) func(string) (string, error) {
+	return func(val string) (string, error) {
+		if limit := maxFieldLength[field]; utf8.RuneCountInString(val) > limit {
+			return "", fmt.Errorf("must be at most %d characters", limit)
+		}
+		return val, nil
+	}
+}
+
+// NormalizePhone mengubah nomor telepon ke format E.164, contoh 0812-3456-7890 -> +6281234567890.
+// Nomor tanpa +, 00 atau 0 ditolak karena tidak jelas apakah diawali kode negara
+// atau nomor lokal tanpa angka 0.
+func NormalizePhone(phone string) (string, error) {
+	p := phoneStripper.Replace(phone)
+	switch {
+	case strings.HasPrefix(p, "+"):
+	case strings.HasPrefix(p, "00"):
+		p = "+" + p[2:]
+	case strings.HasPrefix(p, "0"):
+		p = "+" + DefaultPhoneCountryCode + p[1:]
+	default:
+		return "", errors.New("must start with + and the country code, e.g. +6281234567890, or with 0 for a local number")
+	}
+	if !e164Regex.MatchString(p) {
+		return "", errors.New("must be a valid phone number in E.164 format, e.g. +6281234567890")
+	}
+	return p, nil
+}
+
+// NormalizeWebsite memastikan URL http/https yang valid, skema default https
+func NormalizeWebsite(website string) (string, error) {
+	if utf8.RuneCountInString(website) > maxFieldLength["website"] {
+		return "", fmt.Errorf("must be at most %d characters", maxFieldLength["website"])
+	}
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+
+	u, err := url.Parse(website)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errors.New("must be a valid http or https URL")
+	}
+	host := u.Hostname()
+	if host == "" || !strings.Contains(host, ".") || u.User != nil {
+		return "", errors.New("must be a valid http or https URL")
+	}
+	return u.String(), nil
+}
+
+func validateBirthdate(val string) (string, error) {
+	date, err := time.Parse(time.DateOnly, val)
+	if err != nil {
+		return "", errors.New("must be a date in YYYY-MM-DD format")
+	}
+	now := time.Now()
+	if date.Year() < 1900 || date.After(now) {
+		return "", errors.New("must be a date between 1900 and today")
+	}
+	if date.AddDate(minimumAge, 0, 0).After(now) {
+		return "", fmt.Errorf("you must be at least %d years old", minimumAge)
+	}
+	return date.Format(time.DateOnly), nil
+}
+
+func validateVisibility(val string) (string, error) {
+	switch strings.ToLower(val) {
+	case VisibilityPublic, VisibilityFollowers, VisibilityPrivate:
+		return strings.ToLower(val), nil
+	}
+	return "", fmt.Errorf("must be one of %s, %s or %s", VisibilityPublic, VisibilityFollowers, VisibilityPrivate)
+}
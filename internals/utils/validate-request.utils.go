@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/ntisrangga142/chat/internals/models"
+)
+
+// ErrValidation adalah error_code untuk input yang gagal validasi per field
+const ErrValidation = "validation_failed"
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// nama field di details mengikuti tag json, bukan nama field Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	// notblank: string tidak boleh hanya berisi spasi
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	// image: file upload jpg/png dengan ukuran maksimal MaxImageSize
+	v.RegisterValidation("image", func(fl validator.FieldLevel) bool {
+		file, ok := fl.Field().Interface().(multipart.FileHeader)
+		return ok && ValidateImageFile(&file) == nil
+	})
+}
+
+// ParseIDParam membaca path param berupa id positif, menulis 400 jika tidak valid
+func ParseIDParam(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil || id <= 0 {
+		HandleFieldError(ctx, name, "invalid_format", name+" must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// HandleBindError menjawab 400 untuk error ShouldBind*, dengan detail per field
+// jika error berasal dari tag binding
+func HandleBindError(ctx *gin.Context, err error) {
+	HandleDomainError(ctx, BindError(err), "failed binding data")
+}
+
+// HandleFieldError menjawab 400 untuk satu field yang gagal validasi manual
+func HandleFieldError(ctx *gin.Context, field, code, message string) {
+	HandleDomainError(ctx, models.NewValidationError(ErrValidation, message, models.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}), "")
+}
+
+// BindError mengubah error ShouldBind* menjadi error validasi
+func BindError(err error) *models.DomainError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, fieldError(fe))
+		}
+		return models.NewValidationError(ErrValidation, "request validation failed", fields...).WithCause(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return models.NewValidationError(ErrValidation, "request validation failed", models.FieldError{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type.Kind()),
+		}).WithCause(err)
+	}
+
+	return models.NewValidationError("invalid_body", "request body is not valid").WithCause(err)
+}
+
+func fieldError(fe validator.FieldError) models.FieldError {
+	field := fe.Field()
+	// string dihitung panjangnya, slice jumlah isinya, angka nilainya
+	unit, tooSmall, tooLarge := "", "too_small", "too_large"
+	switch fe.Kind() {
+	case reflect.String:
+		unit, tooSmall, tooLarge = " characters", "too_short", "too_long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit, tooSmall, tooLarge = " items", "too_few", "too_many"
+	}
+
+	switch fe.Tag() {
+	case "required", "notblank":
+		return models.FieldError{Field: field, Code: "required", Message: field + " is required"}
+	case "required_without":
+		return models.FieldError{Field: field, Code: "required", Message: fmt.Sprintf("%s is required when %s is empty", field, strings.ToLower(fe.Param()))}
+	case "image":
+		return models.FieldError{Field: field, Code: "invalid_image", Message: fmt.Sprintf("%s must be a jpg or png image of at most %dMB", field, MaxImageSize>>20)}
+	case "numeric":
+		return models.FieldError{Field: field, Code: "invalid_format", Message: field + " must contain only digits"}
+	case "email":
+		return models.FieldError{Field: field, Code: "invalid_format", Message: field + " must be a valid email address"}
+	case "min", "gte":
+		return models.FieldError{Field: field, Code: tooSmall, Message: fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit)}
+	case "max", "lte":
+		return models.FieldError{Field: field, Code: tooLarge, Message: fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit)}
+	case "gt":
+		return models.FieldError{Field: field, Code: tooSmall, Message: fmt.Sprintf("%s must be greater than %s", field, fe.Param())}
+	case "oneof":
+		return models.FieldError{Field: field, Code: "invalid_value", Message: fmt.Sprintf("%s must be one of: %s", field, fe.Param())}
+	}
+	return models.FieldError{Field: field, Code: "invalid", Message: field + " is invalid"}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.]{3,30}$`)
+	digitsRegex   = regexp.MustCompile(`^[0-9]+$`)
+	mentionRegex  = regexp.MustCompile(`(?:^|[^\w.@])@([a-zA-Z0-9_.]{3,30})`)
+)
+
+// Handle yang tidak boleh dipakai user karena bentrok dengan route atau menyesatkan
+var reservedUsernames = map[string]struct{}{
+	"admin": {}, "administrator": {}, "root": {}, "system": {}, "support": {},
+	"help": {}, "api": {}, "auth": {}, "login": {}, "logout": {}, "register": {},
+	"signup": {}, "user": {}, "users": {}, "me": {}, "post": {}, "posts": {},
+	"notif": {}, "notification": {}, "notifications": {}, "settings": {},
+	"explore": {}, "search": {}, "about": {}, "security": {}, "official": {},
+	"moderator": {}, "staff": {}, "null": {}, "undefined": {}, "deleted": {},
+	"everyone": {}, "here": {},
+	// segmen statis di bawah /user dan /users, gin mendahulukannya dari :id
+	"deactivate": {}, "export": {}, "download": {}, "username": {},
+	"follower": {}, "followers": {}, "following": {}, "suggestions": {},
+	"mutual": {}, "block": {}, "follow": {},
+}
+
+// NormalizeUsername mengubah handle ke bentuk pembanding (tanpa @, lowercase)
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
+
+// ValidateUsername: 3-30 karakter huruf, angka, underscore atau titik,
+// tidak diawali/diakhiri titik, tanpa titik berurutan, bukan angka semua
+// (supaya tidak tertukar dengan id) dan tidak termasuk kata yang dicadangkan
+func ValidateUsername(username string) error {
+	if !usernameRegex.MatchString(username) {
+		return errors.New("username must be 3-30 characters of letters, numbers, underscores or dots")
+	}
+	if strings.HasPrefix(username, ".") || strings.HasSuffix(username, ".") || strings.Contains(username, "..") {
+		return errors.New("username cannot start or end with a dot or contain consecutive dots")
+	}
+	if digitsRegex.MatchString(username) {
+		return errors.New("username cannot contain only numbers")
+	}
+	if _, ok := reservedUsernames[strings.ToLower(username)]; ok {
+		return errors.New("username is reserved")
+	}
+	return nil
+}
+
+// ExtractMentions mengambil handle unik (lowercase) dari teks, contoh "halo @alice"
+func ExtractMentions(text string) []string {
+	seen := make(map[string]struct{})
+	mentions := []string{}
+	for _, m := range mentionRegex.FindAllStringSubmatch(text, -1) {
+		handle := strings.ToLower(strings.TrimRight(m[1], "."))
+		if _, ok := seen[handle]; ok || ValidateUsername(handle) != nil {
+			continue
+		}
+		seen[handle] = struct{}{}
+		mentions = append(mentions, handle)
+	}
+	return mentions
+}
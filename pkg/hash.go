@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -30,6 +31,19 @@ func (h *HashConfig) UseRecommended() {
 	h.Thread = 1
 }
 
+// NeedsRehash bernilai true jika parameter hash (hasil decode dari
+// ComparePasswordAndHash) lebih lemah dari UseRecommended
+func (h *HashConfig) NeedsRehash() bool {
+	recommended := NewHashConfig()
+	recommended.UseRecommended()
+
+	return h.Memory < recommended.Memory ||
+		h.Time < recommended.Time ||
+		h.Thread < recommended.Thread ||
+		h.KeyLen < recommended.KeyLen ||
+		h.SaltLen < recommended.SaltLen
+}
+
 func (h *HashConfig) GenHash(password string) (string, error) {
 	salt, err := h.genSalt()
 	if err != nil {
@@ -83,10 +97,10 @@ func (h *HashConfig) ComparePasswordAndHash(password, encodedHash string) (bool,
 func (h *HashConfig) decodeHash(encodedHash string) (salt []byte, hash []byte, err error) {
 	vals := strings.Split(encodedHash, "$")
 	if len(vals) != 6 {
-		return nil, nil, err
+		return nil, nil, errors.New("invalid hash format")
 	}
 	if vals[1] != "argon2id" {
-		return nil, nil, err
+		return nil, nil, errors.New("unsupported hash algorithm")
 	}
 
 	var version int
@@ -94,7 +108,7 @@ func (h *HashConfig) decodeHash(encodedHash string) (salt []byte, hash []byte, e
 		return nil, nil, err
 	}
 	if version != argon2.Version {
-		return nil, nil, err
+		return nil, nil, errors.New("incompatible argon2 version")
 	}
 
 	if _, err := fmt.Sscanf(vals[3], "m=%d,t=%d,p=%d", &h.Memory, &h.Time, &h.Thread); err != nil {
@@ -0,0 +1,296 @@
+package pkg
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// kid untuk token HS256 lama yang tidak punya header kid
+const legacyKeyID = "legacy-hs256"
+
+type JWTKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	// private key untuk signing, nil untuk key yang hanya dipakai verifikasi
+	Private any
+	// public key (atau secret untuk HS256) untuk verifikasi
+	Public any
+}
+
+// KeySet berisi satu key aktif untuk signing dan beberapa key verifikasi,
+// sehingga key bisa dirotasi tanpa membuat semua user logout
+type KeySet struct {
+	Signing  *JWTKey
+	Verify   map[string]*JWTKey
+	Issuer   string
+	Audience string
+	// secret HMAC untuk token sekali pakai & signed url
+	Secret []byte
+}
+
+// KeyConfig berisi sumber key set, diisi dari env JWT_* oleh package configs
+type KeyConfig struct {
+	// secret HMAC token sekali pakai & signed url, juga key HS256 jika
+	// SigningKey kosong
+	Secret   string
+	Issuer   string
+	Audience string
+	// path PEM private key RSA atau Ed25519 (key aktif)
+	SigningKey string
+	// kid key aktif (opsional, default thumbprint)
+	SigningKID string
+	// key lama yang masih diterima, format path atau kid=path
+	VerifyKeys []string
+	// AcceptLegacy tetap menerima token HS256 tanpa kid walau sudah memakai
+	// JWT_SIGNING_KEY, hanya untuk masa migrasi
+	AcceptLegacy bool
+}
+
+var defaultKeySet atomic.Pointer[KeySet]
+
+// InitKeySet memasang key set yang dipakai GenToken dan VerifyToken
+func InitKeySet(ks *KeySet) {
+	defaultKeySet.Store(ks)
+}
+
+// errKeySetNotInitialized dikembalikan jika InitKeySet belum dipanggil, key
+// tidak pernah dibaca langsung dari env
+var errKeySetNotInitialized = errors.New("jwt key set is not initialized, call InitKeySet first")
+
+func getKeySet() (*KeySet, error) {
+	ks := defaultKeySet.Load()
+	if ks == nil {
+		return nil, errKeySetNotInitialized
+	}
+	return ks, nil
+}
+
+// LoadKeySet membaca file key dan menyusun key set
+func LoadKeySet(cfg KeyConfig) (*KeySet, error) {
+	ks := &KeySet{
+		Verify:   make(map[string]*JWTKey),
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+	}
+
+	if cfg.Secret != "" {
+		ks.Secret = []byte(cfg.Secret)
+	}
+	// Secret HS256 hanya menjadi key JWT jika belum ada key asimetris, atau
+	// selama masa migrasi dengan AcceptLegacy. Setelah itu token HS256 ditolak
+	// dan secret hanya dipakai untuk HMAC.
+	if cfg.Secret != "" && (cfg.SigningKey == "" || cfg.AcceptLegacy) {
+		legacy := &JWTKey{ID: legacyKeyID, Method: jwt.SigningMethodHS256, Private: []byte(cfg.Secret), Public: []byte(cfg.Secret)}
+		ks.Verify[legacy.ID] = legacy
+		ks.Signing = legacy
+	}
+
+	for _, entry := range cfg.VerifyKeys {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// "kid=path" mempertahankan kid dari JWT_SIGNING_KID sebelumnya,
+		// tanpa kid dipakai thumbprint
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			kid, path = "", entry
+		}
+		key, err := LoadJWTKey(path, kid)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = nil
+		ks.Verify[key.ID] = key
+	}
+
+	if cfg.SigningKey != "" {
+		key, err := LoadJWTKey(cfg.SigningKey, cfg.SigningKID)
+		if err != nil {
+			return nil, err
+		}
+		if key.Private == nil {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY %s is not a private key", cfg.SigningKey)
+		}
+		ks.Verify[key.ID] = key
+		ks.Signing = key
+	}
+
+	if ks.Signing == nil {
+		return nil, errors.New("no secret found")
+	}
+	return ks, nil
+}
+
+// LoadJWTKey membaca file PEM (private atau public key RSA/Ed25519)
+func LoadJWTKey(path, kid string) (*JWTKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode jwt key %s", path)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported jwt key type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt key %s: %w", path, err)
+	}
+
+	key := &JWTKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported jwt key algorithm in %s", path)
+	}
+
+	key.ID = kid
+	if key.ID == "" {
+		key.ID, err = keyThumbprint(key.Public)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func keyThumbprint(pub any) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+func (ks *KeySet) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyID
+	}
+	key, ok := ks.Verify[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+	return key.Public, nil
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKey mengubah JWK (RSA, EC atau Ed25519) menjadi public key untuk verifikasi
+func (k JWK) PublicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa modulus: %w", err)
+		}
+		e, err := decode(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curve, ok := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported ec curve %q", k.Crv)
+		}
+		x, errX := decode(k.X)
+		y, errY := decode(k.Y)
+		if errX != nil || errY != nil {
+			return nil, errors.New("invalid ec point")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		x, err := decode(k.X)
+		if err != nil || k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan semua public key verifikasi. Secret HS256 tidak pernah dipublikasikan.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.Verify {
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA", Use: "sig", Alg: key.Method.Alg(), Kid: key.ID,
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP", Use: "sig", Alg: key.Method.Alg(), Kid: key.ID,
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// GetJWKS mengembalikan JWKS dari key set default
+func GetJWKS() (JWKS, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return JWKS{}, err
+	}
+	return ks.JWKS(), nil
+}
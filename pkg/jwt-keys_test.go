@@ -0,0 +1,301 @@
+package pkg_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// tulis key ke file PEM di direktori sementara
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func genEd25519PEM(t *testing.T) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, "ed25519.pem", "PRIVATE KEY", der)
+}
+
+func genRSAPEM(t *testing.T) string {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv))
+}
+
+// pasang key set untuk satu test, dilepas lagi setelah selesai
+func useKeySet(t *testing.T, cfg pkg.KeyConfig) *pkg.KeySet {
+	t.Helper()
+	ks, err := pkg.LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.InitKeySet(ks)
+	t.Cleanup(func() { pkg.InitKeySet(nil) })
+	return ks
+}
+
+func genToken(t *testing.T, claims *pkg.Claims) string {
+	t.Helper()
+	token, err := claims.GenToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, newKey := genEd25519PEM(t), genRSAPEM(t)
+
+	// sebelum rotasi: key lama aktif dengan kid 2025-07
+	useKeySet(t, pkg.KeyConfig{Secret: "secret", Issuer: "chat", SigningKey: oldKey, SigningKID: "2025-07"})
+	oldToken := genToken(t, pkg.NewJWTClaims(1))
+	// sebelum key asimetris: HS256 dengan JWT_SECRET
+	legacy := pkg.KeyConfig{Secret: "secret", Issuer: "chat"}
+	useKeySet(t, legacy)
+	legacyToken := genToken(t, pkg.NewJWTClaims(1))
+
+	rotated := pkg.KeyConfig{Secret: "secret", Issuer: "chat", SigningKey: newKey, SigningKID: "2025-10", VerifyKeys: []string{"2025-07=" + oldKey}}
+	withoutOldKey := rotated
+	withoutOldKey.VerifyKeys = nil
+	oldKeyWithoutKid := rotated
+	oldKeyWithoutKid.VerifyKeys = []string{oldKey}
+	acceptLegacy := rotated
+	acceptLegacy.AcceptLegacy = true
+
+	useKeySet(t, rotated)
+	newToken := genToken(t, pkg.NewJWTClaims(1))
+
+	tests := []struct {
+		name    string
+		cfg     pkg.KeyConfig
+		token   string
+		wantErr bool
+	}{
+		{name: "new key", cfg: rotated, token: newToken},
+		{name: "old kid still verifies", cfg: rotated, token: oldToken},
+		{name: "old key removed", cfg: withoutOldKey, token: oldToken, wantErr: true},
+		{name: "old key listed under its thumbprint", cfg: oldKeyWithoutKid, token: oldToken, wantErr: true},
+		{name: "legacy HS256 rejected by default", cfg: rotated, token: legacyToken, wantErr: true},
+		{name: "legacy HS256 accepted with opt-in", cfg: acceptLegacy, token: legacyToken},
+		{name: "legacy HS256 without signing key", cfg: legacy, token: legacyToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useKeySet(t, tt.cfg)
+			var claims pkg.Claims
+			if err := claims.VerifyToken(tt.token); (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenClaims(t *testing.T) {
+	ks := useKeySet(t, pkg.KeyConfig{Secret: "secret", Issuer: "chat", Audience: "chat-api", SigningKey: genEd25519PEM(t), SigningKID: "k1"})
+	now := time.Now()
+
+	claimsWith := func(edit func(*pkg.Claims)) string {
+		claims := pkg.NewJWTClaims(1)
+		edit(claims)
+		return genToken(t, claims)
+	}
+
+	// HS256 dengan kid key Ed25519, ditandatangani secret
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, pkg.NewJWTClaims(1))
+	confused.Header["kid"] = ks.Signing.ID
+	confusedToken, err := confused.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodEdDSA, pkg.NewJWTClaims(1))
+	unknownKid.Header["kid"] = "k0"
+	unknownKidToken, err := unknownKid.SignedString(ks.Signing.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "valid", token: claimsWith(func(*pkg.Claims) {})},
+		{name: "alg does not match kid", token: confusedToken, wantErr: true},
+		{name: "unknown kid", token: unknownKidToken, wantErr: true},
+		{name: "other audience", token: claimsWith(func(c *pkg.Claims) { c.Audience = jwt.ClaimStrings{"other"} }), wantErr: true},
+		{name: "missing audience", token: claimsWith(func(c *pkg.Claims) { c.Audience = nil }), wantErr: true},
+		{name: "other issuer", token: claimsWith(func(c *pkg.Claims) { c.Issuer = "evil" }), wantErr: true},
+		{name: "not valid yet", token: claimsWith(func(c *pkg.Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) }), wantErr: true},
+		{name: "nbf within leeway", token: claimsWith(func(c *pkg.Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second)) })},
+		{name: "expired", token: claimsWith(func(c *pkg.Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims pkg.Claims
+			if err := claims.VerifyToken(tt.token); (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMFATokenIsSeparate(t *testing.T) {
+	for _, audience := range []string{"chat-api", ""} {
+		t.Run("audience "+audience, func(t *testing.T) {
+			ks := useKeySet(t, pkg.KeyConfig{Secret: "secret", Issuer: "chat", Audience: audience, SigningKey: genEd25519PEM(t), SigningKID: "k1"})
+			access := genToken(t, pkg.NewJWTClaims(1))
+			mfa := genToken(t, pkg.NewMFAClaims(1))
+
+			// claims challenge yang ditandatangani ulang tanpa header typ mfa
+			retyped := jwt.NewWithClaims(jwt.SigningMethodEdDSA, pkg.NewMFAClaims(1))
+			retyped.Header["kid"] = ks.Signing.ID
+			retypedToken, err := retyped.SignedString(ks.Signing.Private)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				name    string
+				verify  func(*pkg.Claims, string) error
+				token   string
+				wantErr bool
+			}{
+				{name: "access as access", verify: (*pkg.Claims).VerifyToken, token: access},
+				{name: "mfa as mfa", verify: (*pkg.Claims).VerifyMFAToken, token: mfa},
+				{name: "mfa as access", verify: (*pkg.Claims).VerifyToken, token: mfa, wantErr: true},
+				{name: "access as mfa", verify: (*pkg.Claims).VerifyMFAToken, token: access, wantErr: true},
+				{name: "mfa without typ", verify: (*pkg.Claims).VerifyMFAToken, token: retypedToken, wantErr: true},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var claims pkg.Claims
+					if err := tt.verify(&claims, tt.token); (err != nil) != tt.wantErr {
+						t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, edKey := genRSAPEM(t), genEd25519PEM(t)
+	ks := useKeySet(t, pkg.KeyConfig{Secret: "secret", SigningKey: rsaKey, SigningKID: "b-rsa", VerifyKeys: []string{edKey}, AcceptLegacy: true})
+	ed, err := pkg.LoadJWTKey(edKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := ks.JWKS()
+	// secret HS256 tidak pernah dipublikasikan
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("got %d keys, want RSA and Ed25519 only: %+v", len(jwks.Keys), jwks.Keys)
+	}
+	byKid := map[string]pkg.JWK{}
+	for i, key := range jwks.Keys {
+		if i > 0 && jwks.Keys[i-1].Kid > key.Kid {
+			t.Fatalf("keys not sorted by kid: %+v", jwks.Keys)
+		}
+		byKid[key.Kid] = key
+	}
+
+	rsaJWK := byKid["b-rsa"]
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" || rsaJWK.E != "AQAB" || rsaJWK.N == "" {
+		t.Fatalf("rsa jwk = %+v", rsaJWK)
+	}
+	edJWK := byKid[ed.ID]
+	if edJWK.Kty != "OKP" || edJWK.Alg != "EdDSA" || edJWK.Crv != "Ed25519" || edJWK.X == "" {
+		t.Fatalf("ed25519 jwk = %+v", edJWK)
+	}
+
+	// JWK yang dipublikasikan bisa dibaca kembali menjadi key yang sama
+	for _, key := range jwks.Keys {
+		pub, err := key.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: %v", key.Kid, err)
+		}
+		if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(ks.Verify[key.Kid].Public) {
+			t.Fatalf("%s: parsed key differs from the published one", key.Kid)
+		}
+	}
+}
+
+func TestJWKPublicKeyEC(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := priv.PublicKey.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// titik tanpa kompresi: 0x04 || x || y
+	x, y := raw[1:33], raw[33:]
+	enc := base64.RawURLEncoding.EncodeToString
+
+	tests := []struct {
+		name    string
+		jwk     pkg.JWK
+		wantErr bool
+	}{
+		{name: "P-256", jwk: pkg.JWK{Kty: "EC", Crv: "P-256", X: enc(x), Y: enc(y)}},
+		{name: "unknown curve", jwk: pkg.JWK{Kty: "EC", Crv: "secp256k1", X: enc(x), Y: enc(y)}, wantErr: true},
+		{name: "point not on curve", jwk: pkg.JWK{Kty: "EC", Crv: "P-256", X: enc(y), Y: enc(x)}, wantErr: true},
+		{name: "unknown key type", jwk: pkg.JWK{Kty: "oct"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, err := tt.jwk.PublicKey()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !priv.PublicKey.Equal(pub) {
+				t.Fatal("parsed key differs from the original")
+			}
+		})
+	}
+}
+
+func TestKeySetNotInitialized(t *testing.T) {
+	pkg.InitKeySet(nil)
+	// env tidak dipakai sebagai fallback
+	t.Setenv("JWT_SECRET", "secret")
+
+	if _, err := pkg.NewJWTClaims(1).GenToken(); err == nil {
+		t.Fatal("GenToken without InitKeySet succeeded, want error")
+	}
+	var claims pkg.Claims
+	if err := claims.VerifyToken("a.b.c"); err == nil {
+		t.Fatal("VerifyToken without InitKeySet succeeded, want error")
+	}
+}
@@ -1,55 +1,142 @@
 package pkg
 
 import (
+	"crypto/rand"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// masa berlaku access token
+const TokenTTL = time.Minute * 60
+
+// masa berlaku token challenge 2FA
+const MFATokenTTL = time.Minute * 5
+
+// Purpose kosong berarti access token biasa
+const PurposeMFA = "mfa"
+
+// header typ token challenge 2FA, access token memakai "JWT" bawaan
+const mfaTokenType = "mfa+jwt"
+
+var errTokenType = errors.New("token type is not accepted here")
+
+// toleransi selisih jam antar service
+const tokenLeeway = 30 * time.Second
+
 type Claims struct {
-	UserId int `json:"id"`
+	UserId  int    `json:"id"`
+	Purpose string `json:"purpose,omitempty"`
+	// versi sesi saat token terbit, lihat utils.RevokeSessions
+	SessionVersion int64 `json:"sv,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// audience token challenge 2FA, beda dengan access token supaya tidak bisa
+// dipakai di tempat lain walaupun key dan issuer sama
+func mfaAudience(audience string) string {
+	if audience == "" {
+		return PurposeMFA
+	}
+	return audience + "/" + PurposeMFA
+}
+
+func newRegisteredClaims(ttl time.Duration, purpose string) jwt.RegisteredClaims {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+	if ks, err := getKeySet(); err == nil {
+		claims.Issuer = ks.Issuer
+		if purpose == PurposeMFA {
+			claims.Audience = jwt.ClaimStrings{mfaAudience(ks.Audience)}
+		} else if ks.Audience != "" {
+			claims.Audience = jwt.ClaimStrings{ks.Audience}
+		}
+	}
+	return claims
+}
+
 func NewJWTClaims(userid int) *Claims {
 	return &Claims{
-		UserId: userid,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 60)),
-			Issuer:    os.Getenv("JWT_ISSUER"),
-		},
+		UserId:           userid,
+		RegisteredClaims: newRegisteredClaims(TokenTTL, ""),
+	}
+}
+
+// NewMFAClaims membuat token challenge 2FA yang hanya bisa ditukar di /auth/mfa/verify.
+// jti acak supaya dua login di waktu yang sama tidak menghasilkan token yang sama,
+// karena token ditandai terpakai per string token.
+func NewMFAClaims(userid int) *Claims {
+	claims := newRegisteredClaims(MFATokenTTL, PurposeMFA)
+	claims.ID = rand.Text()
+	return &Claims{
+		UserId:           userid,
+		Purpose:          PurposeMFA,
+		RegisteredClaims: claims,
 	}
 }
 
 func (c *Claims) GenToken() (string, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", errors.New("no secret found")
+	ks, err := getKeySet()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
-	return token.SignedString([]byte(jwtSecret))
+	token := jwt.NewWithClaims(ks.Signing.Method, c)
+	if ks.Signing.ID != legacyKeyID {
+		token.Header["kid"] = ks.Signing.ID
+	}
+	if c.Purpose == PurposeMFA {
+		token.Header["typ"] = mfaTokenType
+	}
+	return token.SignedString(ks.Signing.Private)
 }
 
+// VerifyToken memverifikasi access token, token challenge 2FA ditolak
 func (c *Claims) VerifyToken(token string) error {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return errors.New("no secret found")
-	}
-	parsedToken, err := jwt.ParseWithClaims(token, c, func(t *jwt.Token) (any, error) { return []byte(jwtSecret), nil })
+	return c.verify(token, "")
+}
+
+// VerifyMFAToken memverifikasi token challenge 2FA dari NewMFAClaims
+func (c *Claims) VerifyMFAToken(token string) error {
+	return c.verify(token, PurposeMFA)
+}
+
+func (c *Claims) verify(token, purpose string) error {
+	ks, err := getKeySet()
 	if err != nil {
 		return err
 	}
-	if !parsedToken.Valid {
-		return jwt.ErrTokenExpired
+
+	methods := make([]string, 0, len(ks.Verify))
+	for _, key := range ks.Verify {
+		methods = append(methods, key.Method.Alg())
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(ks.Issuer),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(tokenLeeway),
+	}
+	if purpose == PurposeMFA {
+		opts = append(opts, jwt.WithAudience(mfaAudience(ks.Audience)))
+	} else if ks.Audience != "" {
+		opts = append(opts, jwt.WithAudience(ks.Audience))
 	}
-	iss, err := parsedToken.Claims.GetIssuer()
+
+	parsedToken, err := jwt.ParseWithClaims(token, c, ks.keyFunc, opts...)
 	if err != nil {
 		return err
 	}
-	if iss != os.Getenv("JWT_ISSUER") {
-		return jwt.ErrTokenInvalidIssuer
+	if !parsedToken.Valid {
+		return jwt.ErrTokenExpired
+	}
+	if typ, _ := parsedToken.Header["typ"].(string); (typ == mfaTokenType) != (purpose == PurposeMFA) || c.Purpose != purpose {
+		return errTokenType
 	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{mail.To}, buildMessage(m.From, mail))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildMessage(from string, mail Mail) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + from + "\r\n")
+	sb.WriteString("To: " + mail.To + "\r\n")
+	sb.WriteString("Subject: " + mail.Subject + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(mail.Body)
+	return []byte(sb.String())
+}
+
+// LogMailer tidak mengirim email, hanya menulis ke log atau file.
+// Dipakai untuk development dan testing lokal.
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{Path: path}
+}
+
+func (m *LogMailer) Send(ctx context.Context, mail Mail) error {
+	// tanpa file, email ditulis ke logger aplikasi supaya format & level sama
+	if m.Path == "" {
+		slog.InfoContext(ctx, "Mail sent to log", "to", mail.To, "subject", mail.Subject, "body", mail.Body)
+		return nil
+	}
+
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n-----\n", time.Now().Format(time.RFC3339), mail.To, mail.Subject, mail.Body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+	return nil
+}
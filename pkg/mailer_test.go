@@ -0,0 +1,55 @@
+package pkg_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+var testMail = pkg.Mail{To: "alice@example.com", Subject: "Verify your email", Body: "https://example.com/verify?token=abc"}
+
+func TestLogMailerWritesToSlog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	if err := pkg.NewLogMailer("").Send(context.Background(), testMail); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log is not a single structured entry: %q", buf.String())
+	}
+	for key, want := range map[string]string{"to": testMail.To, "subject": testMail.Subject, "body": testMail.Body} {
+		if entry[key] != want {
+			t.Fatalf("%s = %v, want %q", key, entry[key], want)
+		}
+	}
+}
+
+func TestLogMailerWritesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer := pkg.NewLogMailer(path)
+	for range 2 {
+		if err := mailer.Send(context.Background(), testMail); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(raw), testMail.Body); got != 2 {
+		t.Fatalf("file has %d mails, want 2 appended: %s", got, raw)
+	}
+}
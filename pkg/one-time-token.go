@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// GenOneTimeToken membuat token acak untuk dikirim ke user beserta
+// signature-nya. Yang disimpan di database hanya signature.
+func GenOneTimeToken() (token string, signature string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	signature, err = SignOneTimeToken(token)
+	if err != nil {
+		return "", "", err
+	}
+	return token, signature, nil
+}
+
+// SignOneTimeToken menghitung HMAC-SHA256 token dengan secret dari key set
+func SignOneTimeToken(token string) (string, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return "", err
+	}
+	if len(ks.Secret) == 0 {
+		return "", errors.New("no secret found")
+	}
+	mac := hmac.New(sha256.New, ks.Secret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
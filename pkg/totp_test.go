@@ -0,0 +1,115 @@
+package pkg_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ntisrangga142/chat/pkg"
+)
+
+// secret ASCII "12345678901234567890" dari RFC 6238 Appendix B
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// fake clock, tidak bergantung jam sistem
+func totpAt(unix int64) *pkg.TOTP {
+	totp := pkg.NewTOTP()
+	totp.Now = func() time.Time { return time.Unix(unix, 0) }
+	return totp
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	totp := &pkg.TOTP{Digits: 8, Period: 30 * time.Second}
+	for _, tt := range tests {
+		got, err := totp.Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	// 1111111109 dan 1111111111 berada di step yang berurutan
+	const (
+		now          = 1111111111
+		currentStep  = now / 30
+		previousStep = currentStep - 1
+		currentCode  = "050471"
+		previousCode = "081804"
+	)
+	nextCode, _ := pkg.NewTOTP().Code(rfcSecret, time.Unix(now+30, 0))
+	farCode, _ := pkg.NewTOTP().Code(rfcSecret, time.Unix(now-60, 0))
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: currentCode, wantStep: currentStep, wantOK: true},
+		{name: "surrounding spaces", code: " " + currentCode + " ", wantStep: currentStep, wantOK: true},
+		{name: "previous step within skew", code: previousCode, wantStep: previousStep, wantOK: true},
+		{name: "next step within skew", code: nextCode, wantStep: currentStep + 1, wantOK: true},
+		{name: "two steps behind", code: farCode},
+		{name: "replay of the used step", code: currentCode, lastStep: currentStep},
+		{name: "older step after a newer one was used", code: previousCode, lastStep: currentStep},
+		{name: "current step after the previous was used", code: currentCode, lastStep: previousStep, wantStep: currentStep, wantOK: true},
+		{name: "wrong code", code: "000000"},
+		{name: "wrong length", code: "05047"},
+	}
+	totp := totpAt(now)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := totp.Validate(rfcSecret, tt.code, tt.lastStep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("Validate = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("invalid secret", func(t *testing.T) {
+		if _, _, err := totp.Validate("not base32!", currentCode, 0); err == nil {
+			t.Fatal("want error for invalid secret")
+		}
+	})
+}
+
+func TestGenRecoveryCodes(t *testing.T) {
+	codes, err := pkg.GenRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("got %d codes, want 10", len(codes))
+	}
+
+	format := regexp.MustCompile(`^[0-9a-f]{5}-[0-9a-f]{5}$`)
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Fatalf("code %q does not match xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}